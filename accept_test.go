@@ -0,0 +1,120 @@
+package requests
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestSetAccept(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+
+	var got string
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get(HeaderAccept)
+	})
+
+	_, err = NewRequest().SetURLByStr(mock.URL()).SetAccept("application/json", "application/xml").Get("")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got != "application/json, application/xml;q=0.9" {
+		t.Errorf("unexpected Accept header: %q", got)
+	}
+}
+
+func TestExpectContentType(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+
+	mock.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderContentType, "application/json")
+		w.Write([]byte(`{}`))
+	})
+	mock.HandleFunc("/bad", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderContentType, "text/html")
+		w.Write([]byte(`<html></html>`))
+	})
+
+	_, err = NewRequest().SetURLByStr(mock.URL()).ExpectContentType("application/*").Get("/ok")
+	if err != nil {
+		t.Errorf("unexpected error for matching content type: %v", err)
+	}
+
+	_, err = NewRequest().SetURLByStr(mock.URL()).ExpectContentType("application/*").Get("/bad")
+	if err == nil {
+		t.Error("expected ErrUnexpectedContentType for mismatched content type")
+	}
+	if _, ok := err.(*ErrUnexpectedContentType); !ok {
+		t.Errorf("expected *ErrUnexpectedContentType, got %T", err)
+	}
+}
+
+func TestAddAcceptAssemblesQValues(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	var got string
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get(HeaderAccept)
+	})
+
+	_, err = NewRequest().SetURLByStr(mock.URL()).
+		SetAcceptJSON().
+		AddAccept("application/xml", 0.8).
+		Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "application/json, application/xml;q=0.8"; got != want {
+		t.Errorf("Accept = %q, want %q", got, want)
+	}
+}
+
+func TestAddAcceptRejectsOutOfRangeQ(t *testing.T) {
+	req := NewRequest().SetQuiet().AddAccept("application/json", 1.5)
+	if _, ok := req.headerParam.Search(HeaderAccept); ok {
+		t.Error("AddAccept with q > 1 should leave the Accept header unset")
+	}
+}
+
+func TestSetAcceptReplacesPriorValue(t *testing.T) {
+	req := NewRequest().SetAccept("application/json").SetAccept("application/xml")
+	p, ok := req.headerParam.Search(HeaderAccept)
+	if !ok || p.Value != "application/xml" {
+		t.Errorf("Accept = %v, %v, want %q (second SetAccept should replace, not append)", p, ok, "application/xml")
+	}
+}
+
+func TestFormatQValueTrimsTrailingZeros(t *testing.T) {
+	cases := map[float64]string{
+		1:     "1",
+		0:     "0",
+		0.8:   "0.8",
+		0.123: "0.123",
+		0.5:   "0.5",
+	}
+	for q, want := range cases {
+		if got := formatQValue(q); got != want {
+			t.Errorf("formatQValue(%v) = %q, want %q", q, got, want)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package requests
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// AdaptiveTimeoutOptions configures Client.SetAdaptiveTimeout.
+type AdaptiveTimeoutOptions struct {
+	// Multiplier scales the tracked standard deviation when computing a
+	// bucket's timeout: mean + Multiplier*stddev.
+	Multiplier float64
+	// Min and Max clamp the computed timeout. Max is also used as the
+	// timeout for any host+path-template with no history yet.
+	Min time.Duration
+	Max time.Duration
+}
+
+// TimeoutEstimate is a snapshot of the adaptive timeout estimator's state
+// for one host+path-template bucket, as returned by Client.TimeoutEstimates.
+type TimeoutEstimate struct {
+	Key     string
+	Mean    time.Duration
+	StdDev  time.Duration
+	Timeout time.Duration
+	Samples int
+}
+
+// adaptiveTimeoutAlpha is the EWMA smoothing factor applied to both the
+// mean and variance updates: higher weights recent attempts more heavily
+// over older history.
+const adaptiveTimeoutAlpha = 0.2
+
+// adaptiveTimeoutStat tracks an exponentially-weighted moving mean and
+// variance of response times for one host+path-template bucket, using the
+// standard EWMA variance update (the streaming analogue of Welford's
+// algorithm) so it adapts to recent latency without keeping a sliding
+// window of samples.
+type adaptiveTimeoutStat struct {
+	mean     float64
+	variance float64
+	samples  int
+}
+
+func (s *adaptiveTimeoutStat) observe(d time.Duration) {
+	x := float64(d)
+	if s.samples == 0 {
+		s.mean = x
+		s.variance = 0
+	} else {
+		diff := x - s.mean
+		s.mean += adaptiveTimeoutAlpha * diff
+		s.variance = (1 - adaptiveTimeoutAlpha) * (s.variance + adaptiveTimeoutAlpha*diff*diff)
+	}
+	s.samples++
+}
+
+func (s *adaptiveTimeoutStat) timeout(opts AdaptiveTimeoutOptions) time.Duration {
+	if s.samples == 0 {
+		return opts.Max
+	}
+	d := time.Duration(s.mean + opts.Multiplier*math.Sqrt(s.variance))
+	if d < opts.Min {
+		d = opts.Min
+	}
+	if d > opts.Max {
+		d = opts.Max
+	}
+	return d
+}
+
+// adaptiveTimeoutEstimator is the concurrency-safe store behind
+// Client.SetAdaptiveTimeout: one adaptiveTimeoutStat per host+path-template
+// bucket, guarded by a single mutex since updates and reads are cheap and
+// infrequent relative to the request latency they describe. now is a seam
+// for tests to drive with a fake clock instead of time.Now.
+type adaptiveTimeoutEstimator struct {
+	opts AdaptiveTimeoutOptions
+	now  func() time.Time
+
+	mu    sync.Mutex
+	stats map[string]*adaptiveTimeoutStat
+}
+
+func newAdaptiveTimeoutEstimator(opts AdaptiveTimeoutOptions) *adaptiveTimeoutEstimator {
+	return &adaptiveTimeoutEstimator{
+		opts:  opts,
+		now:   time.Now,
+		stats: map[string]*adaptiveTimeoutStat{},
+	}
+}
+
+// deadline returns the absolute deadline for the next attempt against key,
+// computed from now() plus the bucket's current timeout estimate.
+func (e *adaptiveTimeoutEstimator) deadline(key string) time.Time {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	s := e.stats[key]
+	var timeout time.Duration
+	if s == nil {
+		timeout = e.opts.Max
+	} else {
+		timeout = s.timeout(e.opts)
+	}
+	return e.now().Add(timeout)
+}
+
+// observe records d as a completed attempt's response time for key.
+func (e *adaptiveTimeoutEstimator) observe(key string, d time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	s := e.stats[key]
+	if s == nil {
+		s = &adaptiveTimeoutStat{}
+		e.stats[key] = s
+	}
+	s.observe(d)
+}
+
+// snapshot returns the current estimate for every bucket with history,
+// sorted by key for stable output.
+func (e *adaptiveTimeoutEstimator) snapshot() []TimeoutEstimate {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	out := make([]TimeoutEstimate, 0, len(e.stats))
+	for key, s := range e.stats {
+		out = append(out, TimeoutEstimate{
+			Key:     key,
+			Mean:    time.Duration(s.mean),
+			StdDev:  time.Duration(math.Sqrt(s.variance)),
+			Timeout: s.timeout(e.opts),
+			Samples: s.samples,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
+
+// adaptiveTimeoutKey is the bucket key SetAdaptiveTimeout groups latency
+// samples by: a request's host plus its path as set before path
+// parameters are substituted in (see SetPath), so e.g. /users/{id} shares
+// one estimate across every id instead of bucketing per id. Requests built
+// without path parameters just bucket by their literal path.
+func adaptiveTimeoutKey(req *Request) string {
+	if req.baseURL == nil {
+		return ""
+	}
+	return req.baseURL.Host + req.baseURL.Path
+}
+
+// SetAdaptiveTimeout opts in to per-host+path-template timeout
+// estimation: instead of a single static Client.SetTimeout, each
+// request's attempt deadline is set from a tracked EWMA mean and standard
+// deviation of that bucket's past response times, clamped to
+// [opts.Min, opts.Max]. A bucket with no history yet uses opts.Max. It has
+// no effect on a request that already has an explicit deadline, from
+// Request.SetTimeout/SetDeadline or an inherited context. Pass the zero
+// AdaptiveTimeoutOptions to disable adaptive timeouts again.
+func (c *Client) SetAdaptiveTimeout(opts AdaptiveTimeoutOptions) *Client {
+	if opts == (AdaptiveTimeoutOptions{}) {
+		c.adaptiveTimeout = nil
+		return c
+	}
+	c.adaptiveTimeout = newAdaptiveTimeoutEstimator(opts)
+	return c
+}
+
+// TimeoutEstimates returns a snapshot of the current per-host+path-template
+// timeout estimates tracked by SetAdaptiveTimeout, sorted by key. It
+// returns nil if adaptive timeouts were never enabled.
+func (c *Client) TimeoutEstimates() []TimeoutEstimate {
+	if c.adaptiveTimeout == nil {
+		return nil
+	}
+	return c.adaptiveTimeout.snapshot()
+}
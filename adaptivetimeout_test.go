@@ -0,0 +1,154 @@
+package requests
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+// TestAdaptiveTimeoutStatEstimate feeds a scripted sequence of latencies
+// through the estimator directly (bypassing any real clock or network
+// I/O) and checks that the computed timeout tracks the EWMA mean/stddev
+// and is clamped to [Min, Max].
+func TestAdaptiveTimeoutStatEstimate(t *testing.T) {
+	opts := AdaptiveTimeoutOptions{
+		Multiplier: 3,
+		Min:        10 * time.Millisecond,
+		Max:        time.Second,
+	}
+	e := newAdaptiveTimeoutEstimator(opts)
+	fakeNow := time.Unix(0, 0)
+	e.now = func() time.Time { return fakeNow }
+
+	key := "example.com/users/{id}"
+
+	// No history yet: falls back to Max.
+	if got := e.deadline(key); !got.Equal(fakeNow.Add(opts.Max)) {
+		t.Errorf("deadline with no history = %v, want %v", got, fakeNow.Add(opts.Max))
+	}
+
+	for _, d := range []time.Duration{
+		50 * time.Millisecond,
+		55 * time.Millisecond,
+		45 * time.Millisecond,
+		60 * time.Millisecond,
+		50 * time.Millisecond,
+	} {
+		e.observe(key, d)
+	}
+
+	snap := e.snapshot()
+	if len(snap) != 1 {
+		t.Fatalf("len(snapshot) = %d, want 1", len(snap))
+	}
+	est := snap[0]
+	if est.Key != key {
+		t.Errorf("Key = %q, want %q", est.Key, key)
+	}
+	if est.Samples != 5 {
+		t.Errorf("Samples = %d, want 5", est.Samples)
+	}
+	// Mean should have converged somewhere near the scripted latencies,
+	// well below the 1s Max, and the deadline should be in the future
+	// relative to the fake clock by roughly mean+3*stddev, not Max.
+	if est.Mean <= 0 || est.Mean > 100*time.Millisecond {
+		t.Errorf("Mean = %v, want a small positive duration", est.Mean)
+	}
+	if est.Timeout < opts.Min || est.Timeout > opts.Max {
+		t.Errorf("Timeout = %v, want within [%v, %v]", est.Timeout, opts.Min, opts.Max)
+	}
+	if est.Timeout >= opts.Max {
+		t.Errorf("Timeout = %v, want well below Max %v once history exists", est.Timeout, opts.Max)
+	}
+
+	fakeNow = fakeNow.Add(time.Hour)
+	got := e.deadline(key)
+	if got != fakeNow.Add(est.Timeout) {
+		t.Errorf("deadline = %v, want %v", got, fakeNow.Add(est.Timeout))
+	}
+}
+
+// TestAdaptiveTimeoutStatClampsToMin checks that a bucket with very tight,
+// consistent latencies (near-zero stddev) is still floored at Min rather
+// than collapsing toward zero.
+func TestAdaptiveTimeoutStatClampsToMin(t *testing.T) {
+	opts := AdaptiveTimeoutOptions{
+		Multiplier: 3,
+		Min:        500 * time.Millisecond,
+		Max:        time.Second,
+	}
+	e := newAdaptiveTimeoutEstimator(opts)
+	for i := 0; i < 10; i++ {
+		e.observe("host/path", time.Millisecond)
+	}
+	snap := e.snapshot()
+	if len(snap) != 1 || snap[0].Timeout != opts.Min {
+		t.Fatalf("Timeout = %v, want clamped to Min %v", snap[0].Timeout, opts.Min)
+	}
+}
+
+func TestSetAdaptiveTimeoutAppliesDeadline(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+	mock.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte("ok"))
+	})
+
+	cli := NewClient().SetAdaptiveTimeout(AdaptiveTimeoutOptions{
+		Multiplier: 3,
+		Min:        time.Millisecond,
+		Max:        10 * time.Millisecond,
+	})
+
+	_, err = cli.NewRequest().Get(mock.URL() + "/slow")
+	if err == nil {
+		t.Fatal("expected the request to time out against the 10ms Max with no history")
+	}
+
+	estimates := cli.TimeoutEstimates()
+	if len(estimates) != 0 {
+		t.Errorf("TimeoutEstimates() = %v, want no samples recorded for a failed attempt", estimates)
+	}
+}
+
+func TestSetAdaptiveTimeoutDoesNotOverrideExplicitDeadline(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+	mock.HandleFunc("/fast", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+
+	cli := NewClient().SetAdaptiveTimeout(AdaptiveTimeoutOptions{
+		Multiplier: 3,
+		Min:        time.Millisecond,
+		Max:        time.Millisecond,
+	})
+
+	resp, err := cli.NewRequest().SetTimeout(time.Second).Get(mock.URL() + "/fast")
+	if err != nil {
+		t.Fatalf("explicit SetTimeout should have overridden the 1ms adaptive Max: %v", err)
+	}
+	if string(resp.Body()) != "ok" {
+		t.Errorf("body = %q, want %q", resp.Body(), "ok")
+	}
+}
+
+func TestTimeoutEstimatesNilWithoutOptIn(t *testing.T) {
+	cli := NewClient()
+	if got := cli.TimeoutEstimates(); got != nil {
+		t.Errorf("TimeoutEstimates() = %v, want nil without SetAdaptiveTimeout", got)
+	}
+}
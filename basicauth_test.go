@@ -0,0 +1,62 @@
+package requests
+
+import (
+	"encoding/base64"
+	"net/http"
+	"testing"
+)
+
+func TestBasicAuthHeaderRejectsColonInUsername(t *testing.T) {
+	_, err := BasicAuthHeader("alice:bob", "secret")
+	if err == nil {
+		t.Fatal("want an error for a username containing ':'")
+	}
+}
+
+func TestBasicAuthHeaderNormalizesToNFC(t *testing.T) {
+	// "é" as e + combining acute accent (NFD) should normalize to the
+	// single precomposed code point (NFC) before encoding.
+	decomposed := "é"
+	got, err := BasicAuthHeader(decomposed, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := base64.StdEncoding.EncodeToString([]byte("é:secret"))
+	if got != want {
+		t.Errorf("BasicAuthHeader = %q, want %q (NFC-normalized)", got, want)
+	}
+}
+
+func TestSetBasicAuthUTF8SendsNormalizedHeader(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	var gotAuth string
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get(HeaderAuthorization)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	_, err = NewRequest().SetURLByStr(mock.URL()).
+		SetBasicAuthUTF8("étoile", "secret").
+		Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Basic " + base64.StdEncoding.EncodeToString([]byte("étoile:secret"))
+	if gotAuth != want {
+		t.Errorf("Authorization = %q, want %q", gotAuth, want)
+	}
+}
+
+func TestSetBasicAuthUTF8ErrorsOnColonInUsername(t *testing.T) {
+	_, err := NewRequest().SetQuiet().SetURLByStr("http://example.invalid/").
+		SetBasicAuthUTF8("alice:bob", "secret").
+		Get("/")
+	if err == nil {
+		t.Fatal("want an error for a username containing ':'")
+	}
+}
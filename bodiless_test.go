@@ -0,0 +1,71 @@
+package requests
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+)
+
+func TestBodilessResponseCacheRoundTrip(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+
+	mock.HandleFunc("/head", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderContentLength, "5")
+	})
+	mock.HandleFunc("/204", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mock.HandleFunc("/304", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotModified)
+	})
+
+	dir, err := ioutil.TempDir("", "requests-bodiless-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cases := []struct {
+		name   string
+		path   string
+		head   bool
+		status int
+	}{
+		{"HEAD", "/head", true, http.StatusOK},
+		{"204", "/204", false, http.StatusNoContent},
+		{"304", "/304", false, http.StatusNotModified},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cli := NewClient().SetCache(FileCacheDir(dir))
+
+			for i := 0; i < 2; i++ {
+				var resp *Response
+				var err error
+				if c.head {
+					resp, err = cli.NewRequest().SetURLByStr(mock.URL()).Head(c.path)
+				} else {
+					resp, err = cli.NewRequest().SetURLByStr(mock.URL()).Get(c.path)
+				}
+				if err != nil {
+					t.Fatalf("request %d: %v", i, err)
+				}
+				if resp.StatusCode() != c.status {
+					t.Errorf("request %d: status = %d, want %d", i, resp.StatusCode(), c.status)
+				}
+				if len(resp.Body()) != 0 {
+					t.Errorf("request %d: expected empty body, got %q", i, resp.Body())
+				}
+			}
+		})
+	}
+}
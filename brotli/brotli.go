@@ -0,0 +1,23 @@
+// Package brotli adds Content-Encoding: br support on top of
+// github.com/wzshiming/requests, kept as a separate module so the
+// brotli dependency doesn't leak into the core module. Importing it
+// registers the codec with requests.RegisterEncoding via its init(), so
+// Client/Request.SetAcceptEncoding and response decompression pick it up
+// automatically; there's nothing to call from this package directly.
+package brotli
+
+import (
+	"io"
+	"io/ioutil"
+
+	"github.com/andybalholm/brotli"
+	"github.com/wzshiming/requests"
+)
+
+func init() {
+	requests.RegisterEncoding("br", func(w io.Writer) io.WriteCloser {
+		return brotli.NewWriter(w)
+	}, func(r io.Reader) (io.ReadCloser, error) {
+		return ioutil.NopCloser(brotli.NewReader(r)), nil
+	})
+}
@@ -0,0 +1,60 @@
+package brotli
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	brotlilib "github.com/andybalholm/brotli"
+	"github.com/wzshiming/requests"
+)
+
+func TestRegisteredAsAcceptEncodingCandidate(t *testing.T) {
+	names := requests.RegisteredEncodings()
+	found := false
+	for _, n := range names {
+		if n == "br" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("RegisteredEncodings() = %v, want it to include br", names)
+	}
+}
+
+func TestResponseDecompressesBrotli(t *testing.T) {
+	const want = "hello, brotli compressed response"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(requests.HeaderContentEncoding, "br")
+		bw := brotlilib.NewWriter(w)
+		bw.Write([]byte(want))
+		bw.Close()
+	}))
+	defer srv.Close()
+
+	resp, err := requests.NewRequest().SetURLByStr(srv.URL).
+		SetAcceptEncoding("br").
+		Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(resp.Body()) != want {
+		t.Errorf("Body() = %q, want %q", resp.Body(), want)
+	}
+}
+
+func TestSetAcceptEncodingDerivesFromRegisteredCodecs(t *testing.T) {
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get(requests.HeaderAcceptEncoding)
+	}))
+	defer srv.Close()
+
+	if _, err := requests.NewRequest().SetURLByStr(srv.URL).SetAcceptEncoding().Get("/"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, "br") {
+		t.Errorf("Accept-Encoding = %q, want it to include br", got)
+	}
+}
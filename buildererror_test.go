@@ -0,0 +1,51 @@
+package requests
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestDoReturnsBuilderErrorForInvalidURL(t *testing.T) {
+	_, err := NewRequest().SetQuiet().SetURLByStr("://bad").Get("")
+	if err == nil {
+		t.Fatal("want an error for an unparseable URL")
+	}
+}
+
+func TestDoReturnsBuilderErrorForUnmarshalableJSON(t *testing.T) {
+	_, err := NewRequest().SetQuiet().SetURLByStr("http://example.invalid/").
+		SetJSON(make(chan int)).
+		Post("")
+	if err == nil {
+		t.Fatal("want an error for a JSON body that can't be marshaled")
+	}
+}
+
+func TestDoReturnsErrorWhenNoURLWasEverSet(t *testing.T) {
+	_, err := NewRequest().SetQuiet().Post("")
+	if err == nil {
+		t.Fatal("want an error when Post is called without a URL")
+	}
+}
+
+func TestBuilderErrorIsReturnedBeforeTouchingTheNetwork(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	var called bool
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+
+	_, err = NewRequest().SetQuiet().SetURLByStr(mock.URL()).
+		SetJSON(make(chan int)).
+		Post("/")
+	if err == nil {
+		t.Fatal("want an error for a JSON body that can't be marshaled")
+	}
+	if called {
+		t.Error("the mock server was hit even though the request never built successfully")
+	}
+}
@@ -1,10 +1,12 @@
 package requests
 
 import (
+	"bytes"
 	"crypto/md5"
 	"encoding/hex"
 	"errors"
 	"io/ioutil"
+	"mime"
 	"net/http"
 	"net/http/httputil"
 	"os"
@@ -13,7 +15,16 @@ import (
 	"sync"
 )
 
-var ErrNotExist = errors.New("not exist")
+var (
+	ErrNotExist = errors.New("not exist")
+
+	// ErrCorrupt is returned by Cache.Load when an entry exists but failed
+	// to deserialize -- e.g. a truncated file left behind by a crash mid
+	// write. It's distinct from ErrNotExist so the client can tell "never
+	// cached" apart from "cached badly", auto-delete the bad entry, and
+	// still serve the request from the network.
+	ErrCorrupt = errors.New("cache entry corrupt")
+)
 
 type Cache interface {
 	Hash(*Request) (string, error)
@@ -85,9 +96,8 @@ func (f fileCacheDir) Load(name string) (*Response, error) {
 	}
 
 	resp := &Response{}
-	err = resp.UnarshalText(data)
-	if err != nil {
-		return nil, err
+	if err := resp.UnarshalText(data); err != nil {
+		return nil, ErrCorrupt
 	}
 	return resp, nil
 }
@@ -111,6 +121,47 @@ func (f fileCacheDir) Del(name string) error {
 	return nil
 }
 
+// CanonicalizeJSONCache wraps a Cache so that application/json request
+// bodies are normalized with CanonicalJSON before computing the cache hash,
+// so two requests that only differ in key order or whitespace hit the same
+// cache entry. Non-JSON bodies and the bytes actually sent on the wire are
+// left untouched.
+func CanonicalizeJSONCache(c Cache) Cache {
+	return &canonicalCache{Cache: c}
+}
+
+type canonicalCache struct {
+	Cache
+}
+
+func (c *canonicalCache) Hash(r *Request) (string, error) {
+	req, err := r.RawRequest()
+	if err != nil {
+		return "", err
+	}
+	mediatype, _, err := mime.ParseMediaType(req.Header.Get(HeaderContentType))
+	if err != nil || mediatype != "application/json" || req.Body == nil {
+		return c.Cache.Hash(r)
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return "", err
+	}
+	req.Body.Close()
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	canon, err := CanonicalJSON(body)
+	if err != nil {
+		// Not valid JSON despite the content type; hash as-is.
+		return c.Cache.Hash(r)
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(canon))
+	hash, err := RequestHash(req)
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return hash, err
+}
+
 func RequestHash(r *http.Request) (string, error) {
 	msg, err := httputil.DumpRequest(r, true)
 	if err != nil {
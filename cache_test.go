@@ -0,0 +1,120 @@
+package requests
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileCacheDirLoadDistinguishesCorruptFromNotExist(t *testing.T) {
+	dir := t.TempDir()
+	cache := FileCacheDir(dir)
+
+	if _, err := cache.Load("missing"); err != ErrNotExist {
+		t.Errorf("Load(missing) err = %v, want ErrNotExist", err)
+	}
+
+	corrupt := filepath.Join(dir, "corrupt")
+	if err := ioutil.WriteFile(corrupt, []byte("not a valid cached response"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cache.Load("corrupt"); err != ErrCorrupt {
+		t.Errorf("Load(corrupt) err = %v, want ErrCorrupt", err)
+	}
+}
+
+func TestCachedRequestDiscardsCorruptEntryAndFetchesFromNetwork(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	hits := 0
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Write([]byte("fresh"))
+	})
+
+	dir := t.TempDir()
+	var logBuf bytes.Buffer
+	client := NewClient().SetLogger(&logBuf).SetCache(FileCacheDir(dir))
+
+	req := client.NewRequest().SetURLByStr(mock.URL())
+	hash, err := client.cache.Hash(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cachePath := filepath.Join(dir, hash)
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(cachePath, []byte("truncated garbage"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := client.NewRequest().SetURLByStr(mock.URL()).Get("/")
+	if err != nil {
+		t.Fatalf("Get() err = %v, want nil (request should still succeed from the network)", err)
+	}
+	if string(resp.Body()) != "fresh" {
+		t.Errorf("Body() = %q, want %q", resp.Body(), "fresh")
+	}
+	if hits != 1 {
+		t.Errorf("server hit count = %d, want 1", hits)
+	}
+	if !strings.Contains(logBuf.String(), "corrupt") || !strings.Contains(logBuf.String(), hash) {
+		t.Errorf("log output missing corrupt entry and cache key %q: %q", hash, logBuf.String())
+	}
+	if got := client.CacheStats().Corrupt(); got != 1 {
+		t.Errorf("CacheStats().Corrupt() = %d, want 1", got)
+	}
+
+	// The corrupt entry was discarded and replaced with a valid one from
+	// the successful fetch, so a second request should hit the cache
+	// instead of the network.
+	resp2, err := client.NewRequest().SetURLByStr(mock.URL()).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(resp2.Body()) != "fresh" {
+		t.Errorf("Body() = %q, want %q", resp2.Body(), "fresh")
+	}
+	if hits != 1 {
+		t.Errorf("server hit count after second request = %d, want 1 (should be served from the refreshed cache entry)", hits)
+	}
+}
+
+func TestCacheStatsCountsHitsAndMisses(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+
+	client := NewClient().SetCache(FileCacheDir(t.TempDir()))
+
+	if _, err := client.NewRequest().SetURLByStr(mock.URL()).Get("/"); err != nil {
+		t.Fatal(err)
+	}
+	if got := client.CacheStats().Misses(); got != 1 {
+		t.Errorf("Misses() = %d, want 1", got)
+	}
+
+	if _, err := client.NewRequest().SetURLByStr(mock.URL()).Get("/"); err != nil {
+		t.Fatal(err)
+	}
+	if got := client.CacheStats().Hits(); got != 1 {
+		t.Errorf("Hits() = %d, want 1", got)
+	}
+}
@@ -0,0 +1,36 @@
+package requests
+
+import "sync/atomic"
+
+// CacheStats holds cumulative counters for a Client's Cache layer: how many
+// lookups found a usable entry, found nothing, or found an entry that
+// failed to deserialize and had to be discarded. Safe for concurrent use.
+type CacheStats struct {
+	hits    int64
+	misses  int64
+	corrupt int64
+}
+
+// Hits returns the number of cache lookups that returned a usable entry.
+func (s *CacheStats) Hits() int64 {
+	return atomic.LoadInt64(&s.hits)
+}
+
+// Misses returns the number of cache lookups that found no entry.
+func (s *CacheStats) Misses() int64 {
+	return atomic.LoadInt64(&s.misses)
+}
+
+// Corrupt returns the number of cache lookups that found an entry but
+// failed to deserialize it (see ErrCorrupt). Each one is auto-deleted and
+// the request falls back to the network.
+func (s *CacheStats) Corrupt() int64 {
+	return atomic.LoadInt64(&s.corrupt)
+}
+
+// CacheStats returns the client's cumulative cache counters. It's always
+// non-nil, even if the client has no cache configured, in which case every
+// counter stays 0.
+func (c *Client) CacheStats() *CacheStats {
+	return &c.cacheStats
+}
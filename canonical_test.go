@@ -0,0 +1,78 @@
+package requests
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCanonicalJSON(t *testing.T) {
+	cases := []struct {
+		in, want string
+	}{
+		{`{"b":2,"a":1}`, `{"a":1,"b":2}`},
+		{`  {  "a" : [3, 2, 1]  }  `, `{"a":[3,2,1]}`},
+		{`{"a":1.0,"b":1.50}`, `{"a":1,"b":1.5}`},
+		{`{"a":"hi A"}`, `{"a":"hi A"}`},
+		{`null`, `null`},
+		{`[true,false,null]`, `[true,false,null]`},
+	}
+	for _, c := range cases {
+		got, err := CanonicalJSON([]byte(c.in))
+		if err != nil {
+			t.Errorf("CanonicalJSON(%q): %v", c.in, err)
+			continue
+		}
+		if string(got) != c.want {
+			t.Errorf("CanonicalJSON(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestCanonicalizeJSONCacheHit(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+	calls := 0
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	})
+
+	dir, err := ioutil.TempDir("", "requests-cache")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	cli := NewClient()
+	cli.SetCache(CanonicalizeJSONCache(FileCacheDir(dir)))
+
+	post := func(body string) error {
+		_, err := cli.NewRequest().SetURLByStr(mock.URL()).
+			SetBody(strings.NewReader(body)).
+			SetContentType(MimeJSON).
+			Post("")
+		return err
+	}
+
+	if err := post(`{"b":2,  "a": 1}`); err != nil {
+		t.Error(err)
+		return
+	}
+	if err := post(`{"a":1,"b":2}`); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if calls != 1 {
+		t.Errorf("expected one network call due to canonical cache hit, got %d", calls)
+	}
+}
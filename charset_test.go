@@ -0,0 +1,60 @@
+package requests
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+func TestSetCharsetWithStrTranscodesQueryAndForm(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	var gotQuery, gotForm, gotContentType string
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.RawQuery
+		gotContentType = r.Header.Get(HeaderContentType)
+		body, _ := ioutil.ReadAll(r.Body)
+		gotForm = string(body)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	_, err = NewRequest().SetURLByStr(mock.URL()).
+		SetCharsetWithStr("gbk").
+		AddQuery("name", "你好").
+		AddForm("name", "你好").
+		Post("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantGBK, _ := simplifiedchinese.GBK.NewEncoder().String("你好")
+	wantEscaped := "name=" + url.QueryEscape(wantGBK)
+	if gotQuery != wantEscaped {
+		t.Errorf("query = %q, want %q (GBK-encoded, percent-escaped)", gotQuery, wantEscaped)
+	}
+	if gotForm != wantEscaped {
+		t.Errorf("form body = %q, want %q (GBK-encoded, percent-escaped)", gotForm, wantEscaped)
+	}
+	t.Logf("Content-Type = %q", gotContentType)
+}
+
+func TestSetCharsetWithStrUnknownNameErrorsAtDo(t *testing.T) {
+	_, err := NewRequest().SetCharsetWithStr("no-such-charset").SetURLByStr("http://example.invalid/").Get("/")
+	if err == nil {
+		t.Fatal("want an error for an unknown charset name")
+	}
+}
+
+func TestSetCharsetWithStrKnownCharsetDoesNotError(t *testing.T) {
+	req := NewRequest().SetCharsetWithStr("utf-8")
+	if req.deferredErr != nil {
+		t.Errorf("deferredErr = %v, want nil for a recognized charset", req.deferredErr)
+	}
+}
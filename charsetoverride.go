@@ -0,0 +1,54 @@
+package requests
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/transform"
+)
+
+// SetKeepUndecodedBody controls whether a Response keeps the body bytes
+// it received (after Content-Encoding is undone, before charset
+// transcoding) around for Response.DecodeBodyAs. It's off by default
+// since it doubles the memory held per response; turn it on for clients
+// talking to servers whose charset declarations aren't trustworthy.
+func (c *Client) SetKeepUndecodedBody(enable bool) *Client {
+	c.keepUndecodedBody = enable
+	return c
+}
+
+// RawUndecodedBody returns the response body as received on the wire,
+// after any Content-Encoding was undone but before charset transcoding,
+// so a caller can retry decoding with Response.DecodeBodyAs when charset
+// sniffing (see Response.Charset) guessed wrong. It is nil unless the
+// Client that produced this response had Client.SetKeepUndecodedBody(true)
+// set before the request was sent.
+func (r *Response) RawUndecodedBody() []byte {
+	return r.rawUndecodedBody
+}
+
+// Charset returns the charset declared in the response's original
+// Content-Type header, e.g. "iso-8859-1", or "" if none was declared —
+// including the case where a charset was only sniffed from an HTML meta
+// tag rather than stated on the header.
+func (r *Response) Charset() string {
+	return r.charset
+}
+
+// DecodeBodyAs re-decodes Response.RawUndecodedBody using charsetName
+// instead of whatever was sniffed during process(), for responses whose
+// charset was mislabeled or missing entirely. It returns
+// ErrUndecodedBodyDiscarded if the client wasn't set up with
+// Client.SetKeepUndecodedBody(true) before the request was sent.
+func (r *Response) DecodeBodyAs(charsetName string) ([]byte, error) {
+	if r.rawUndecodedBody == nil {
+		return nil, ErrUndecodedBodyDiscarded
+	}
+	e, _ := charset.Lookup(charsetName)
+	if e == nil {
+		return nil, fmt.Errorf("requests: unknown charset %q", charsetName)
+	}
+	return ioutil.ReadAll(transform.NewReader(bytes.NewReader(r.rawUndecodedBody), e.NewDecoder()))
+}
@@ -0,0 +1,102 @@
+package requests
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+func TestDecodeBodyAsFixesMislabeledCharset(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+
+	// The page is actually Windows-1252 (0x80 is the euro sign there), but
+	// the server mislabels it as Windows-1251 (where 0x80 is a Cyrillic
+	// letter), so the default decode mangles it.
+	want := "price: €5"
+	encoded, err := charmap.Windows1252.NewEncoder().String(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderContentType, "text/plain; charset=windows-1251")
+		w.Write([]byte(encoded))
+	})
+
+	cli := NewClient().SetKeepUndecodedBody(true)
+	resp, err := cli.NewRequest().SetURLByStr(mock.URL()).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := resp.Charset(); got != "windows-1251" {
+		t.Errorf("Charset() = %q, want %q", got, "windows-1251")
+	}
+	if string(resp.Body()) == want {
+		t.Fatal("expected the default windows-1251 decode to mangle the body, but it matched")
+	}
+
+	fixed, err := resp.DecodeBodyAs("windows-1252")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(fixed) != want {
+		t.Errorf("DecodeBodyAs(windows-1252) = %q, want %q", fixed, want)
+	}
+}
+
+func TestRawUndecodedBodyNilWithoutOptIn(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello"))
+	})
+
+	resp, err := NewRequest().SetURLByStr(mock.URL()).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.RawUndecodedBody() != nil {
+		t.Error("expected RawUndecodedBody to be nil without SetKeepUndecodedBody(true)")
+	}
+	if _, err := resp.DecodeBodyAs("utf-8"); err != ErrUndecodedBodyDiscarded {
+		t.Errorf("DecodeBodyAs error = %v, want ErrUndecodedBodyDiscarded", err)
+	}
+}
+
+func TestRawUndecodedBodyKeptWithOptIn(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello"))
+	})
+
+	cli := NewClient().SetKeepUndecodedBody(true)
+	resp, err := cli.NewRequest().SetURLByStr(mock.URL()).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(resp.RawUndecodedBody(), []byte("hello")) {
+		t.Errorf("RawUndecodedBody() = %q, want %q", resp.RawUndecodedBody(), "hello")
+	}
+}
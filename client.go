@@ -4,14 +4,21 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"fmt"
 	"io"
 	"log"
+	"math/rand"
 	"net"
 	"net/http"
 	"net/http/cookiejar"
 	"net/url"
 	"os"
+	"path"
 	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"golang.org/x/net/publicsuffix"
@@ -35,8 +42,10 @@ const (
 // NewClient the create a client
 func NewClient() *Client {
 	c := &Client{
-		cli: &http.Client{},
+		cli:                 &http.Client{},
+		suppressWindowStart: time.Now(),
 	}
+	c.cli.CheckRedirect = c.checkRedirect
 	c.SetSkipVerify(true).
 		WithLogger().
 		SetLogLevel(LogInfo)
@@ -51,11 +60,311 @@ type Client struct {
 	proxy        *url.URL
 	proxyFromEnv bool
 	cache        Cache
+	cacheStats   CacheStats
+
+	logSampleRate       float64
+	logLimiter          *tokenBucket
+	logMu               sync.Mutex
+	suppressedCount     int64
+	suppressWindowStart time.Time
+	logBodyLimit        int
+
+	stubs []*stub
+
+	baseURL        *url.URL
+	defaultHeaders map[string]string
+	defaultQueries map[string]string
+
+	maxHeaderBytes int
+	maxHeaderCount int
+
+	connLabeler func(req *http.Request) string
+	connMu      sync.Mutex
+	conns       map[*labeledConn]struct{}
+
+	acceptEncoding []string
+
+	wireSizeAccounting bool
+
+	debugMu sync.Mutex
+
+	deadlineHeader string
+
+	keepUndecodedBody bool
+
+	maxRedirects         int
+	maxCookieHeaderBytes int
+
+	errorOnStatus bool
+
+	maxResponseSize int64
+
+	adaptiveTimeout *adaptiveTimeoutEstimator
+
+	deferredQueue DeferredQueue
+	shouldDefer   func(*Request, error) bool
+
+	responseValidators []ResponseValidator
+
+	configMu     sync.Mutex
+	cachedConfig *ConfigSnapshot
+
+	eventSink func(Event)
+	eventSeq  int64
+
+	jsonrpcSeq int64
+
+	urlJoinMode URLJoinMode
+}
+
+// DefaultMaxResponseSize is a reasonable cap to opt into via
+// Client.SetMaxResponseSize(DefaultMaxResponseSize) or
+// Request.SetMaxResponseSize without having to pick a number yourself.
+const DefaultMaxResponseSize = 32 * 1024 * 1024
+
+// SetMaxResponseSize caps the response body at n bytes; once exceeded,
+// Do() fails with *ErrResponseTooLarge instead of reading the rest of the
+// body into memory. n <= 0 disables the check (the default).
+// Request.SetMaxResponseSize overrides this per request.
+func (c *Client) SetMaxResponseSize(n int64) *Client {
+	c.maxResponseSize = n
+	return c
+}
+
+// SetMaxRequestHeaderBytes caps the total size (names + values) of outgoing
+// request headers, including cookies contributed by the jar. A value <= 0
+// disables the check (the default). Exceeding it fails fill() before any
+// network I/O with a *ErrHeaderLimitExceeded naming the largest headers.
+func (c *Client) SetMaxRequestHeaderBytes(n int) *Client {
+	c.maxHeaderBytes = n
+	return c
+}
+
+// SetMaxHeaderCount caps the number of outgoing request header values,
+// including cookies contributed by the jar. A value <= 0 disables the
+// check (the default).
+func (c *Client) SetMaxHeaderCount(n int) *Client {
+	c.maxHeaderCount = n
+	return c
+}
+
+// headerEntry is one named, sized header value used to report the largest
+// offenders when a header limit is exceeded.
+type headerEntry struct {
+	name string
+	size int
+}
+
+// checkHeaderLimits enforces SetMaxRequestHeaderBytes/SetMaxHeaderCount over
+// header plus the serialized size of cookieHeader (the jar's contribution).
+func (c *Client) checkHeaderLimits(header http.Header, cookieHeader string) error {
+	if c.maxHeaderBytes <= 0 && c.maxHeaderCount <= 0 {
+		return nil
+	}
+	var entries []headerEntry
+	total := 0
+	for name, values := range header {
+		for _, v := range values {
+			size := len(name) + len(v) + 4
+			total += size
+			entries = append(entries, headerEntry{name, size})
+		}
+	}
+	if cookieHeader != "" {
+		size := len("Cookie") + len(cookieHeader) + 4
+		total += size
+		entries = append(entries, headerEntry{"Cookie", size})
+	}
+	count := len(entries)
+	bytesExceeded := c.maxHeaderBytes > 0 && total > c.maxHeaderBytes
+	countExceeded := c.maxHeaderCount > 0 && count > c.maxHeaderCount
+	if !bytesExceeded && !countExceeded {
+		return nil
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].size > entries[j].size })
+	largest := make([]string, 0, 3)
+	for i := 0; i < len(entries) && i < 3; i++ {
+		largest = append(largest, entries[i].name)
+	}
+	return &ErrHeaderLimitExceeded{TotalBytes: total, Count: count, Largest: largest}
+}
+
+// SetBaseURL sets a base URL applied to every request created by
+// NewRequest. User info and query parameters on u are moved onto the
+// request by Request.SetURL once NewRequest applies it; u's fragment,
+// if any, survives that same trip (see Request.SetFragment).
+func (c *Client) SetBaseURL(u *url.URL) *Client {
+	c.baseURL = u
+	c.invalidateConfigSnapshot()
+	return c
+}
+
+// SetDefaultHeaders sets headers applied to every request created by
+// NewRequest.
+func (c *Client) SetDefaultHeaders(headers map[string]string) *Client {
+	c.defaultHeaders = headers
+	c.invalidateConfigSnapshot()
+	return c
+}
+
+// SetDefaultQueries sets query parameters applied to every request created
+// by NewRequest.
+func (c *Client) SetDefaultQueries(queries map[string]string) *Client {
+	c.defaultQueries = queries
+	return c
+}
+
+// stub pairs a "METHOD path-glob" pattern with a response builder.
+type stub struct {
+	method  string
+	pattern string
+	build   func(*Request) *Response
+}
+
+// StubResponse registers a canned response for requests matching pattern,
+// which is "METHOD path-glob" (method defaults to GET when omitted, e.g.
+// "/health/*"). Matching requests never dial, and checking stubs happens
+// before the cache and the network in Client.do. The build func receives
+// the request being served so it can inspect parameters.
+func (c *Client) StubResponse(pattern string, build func(*Request) *Response) *Client {
+	method := MethodGet
+	p := pattern
+	if i := strings.IndexByte(pattern, ' '); i >= 0 {
+		method = strings.ToUpper(pattern[:i])
+		p = strings.TrimSpace(pattern[i+1:])
+	}
+	c.stubs = append(c.stubs, &stub{method: method, pattern: p, build: build})
+	return c
+}
+
+// matchStub returns the first stub matching req, if any.
+func (c *Client) matchStub(req *Request) *stub {
+	for _, s := range c.stubs {
+		if s.method != req.method {
+			continue
+		}
+		matched, err := path.Match(s.pattern, req.rawRequest.URL.Path)
+		if err == nil && matched {
+			return s
+		}
+	}
+	return nil
+}
+
+// SetLogSampling logs only a random fraction (0, 1] of non-error log lines.
+// A rate <= 0 or >= 1 disables sampling (the default).
+func (c *Client) SetLogSampling(rate float64) *Client {
+	c.logSampleRate = rate
+	return c
+}
+
+// SetLogRateLimit caps non-error log lines to perSecond per second using a
+// token bucket. A value <= 0 disables the limit (the default).
+func (c *Client) SetLogRateLimit(perSecond int) *Client {
+	if perSecond <= 0 {
+		c.logLimiter = nil
+		return c
+	}
+	c.logLimiter = newTokenBucket(perSecond)
+	return c
+}
+
+// defaultLogBodyLimit is how much of a request body Request.Message
+// dumps when Client.SetLogBodyLimit was never called.
+const defaultLogBodyLimit = 16 * 1024
+
+// SetLogBodyLimit caps how many bytes of a replayable request body
+// Request.Message/Message dumps at LogMessageAll, so logging a huge
+// upload doesn't buffer it all into memory just to print it. A value
+// <= 0 restores the default of defaultLogBodyLimit.
+func (c *Client) SetLogBodyLimit(n int) *Client {
+	c.logBodyLimit = n
+	return c
+}
+
+// shouldLog reports whether a non-error log line should be emitted, applying
+// sampling and rate limiting and accounting suppressed lines.
+func (c *Client) shouldLog() bool {
+	if c.logSampleRate > 0 && c.logSampleRate < 1 && rand.Float64() >= c.logSampleRate {
+		c.noteSuppressed()
+		return false
+	}
+	if c.logLimiter != nil && !c.logLimiter.Allow() {
+		c.noteSuppressed()
+		return false
+	}
+	return true
+}
+
+// noteSuppressed accounts a suppressed log line and periodically reports
+// the suppressed count so failures due to sampling are never silent.
+func (c *Client) noteSuppressed() {
+	atomic.AddInt64(&c.suppressedCount, 1)
+
+	c.logMu.Lock()
+	defer c.logMu.Unlock()
+	if time.Since(c.suppressWindowStart) < 10*time.Second {
+		return
+	}
+	n := atomic.SwapInt64(&c.suppressedCount, 0)
+	c.suppressWindowStart = time.Now()
+	if n > 0 && c.log != nil {
+		c.log.Printf("suppressed %d request logs in last 10s", n)
+	}
+}
+
+// tokenBucket is a simple concurrency-safe token bucket rate limiter.
+type tokenBucket struct {
+	mu       sync.Mutex
+	rate     float64
+	capacity float64
+	tokens   float64
+	last     time.Time
+}
+
+func newTokenBucket(perSecond int) *tokenBucket {
+	return &tokenBucket{
+		rate:     float64(perSecond),
+		capacity: float64(perSecond),
+		tokens:   float64(perSecond),
+		last:     time.Now(),
+	}
+}
+
+// Allow reports whether a token is available, consuming it if so.
+func (b *tokenBucket) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.capacity {
+		b.tokens = b.capacity
+	}
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true
+	}
+	return false
 }
 
 // NewRequest creates a request instance.
 func (c *Client) NewRequest() *Request {
-	return newRequest(c)
+	r := newRequest(c)
+	if c.baseURL != nil {
+		u := *c.baseURL
+		r.SetURL(&u)
+	}
+	for k, v := range c.defaultHeaders {
+		r.SetHeader(k, v)
+	}
+	for k, v := range c.defaultQueries {
+		r.SetQuery(k, v)
+	}
+	if len(c.acceptEncoding) > 0 {
+		r.AddHeaderIfNot(HeaderAcceptEncoding, strings.Join(c.acceptEncoding, ", "))
+	}
+	return r
 }
 
 // AddCookies adds cookie to the client.
@@ -122,17 +431,22 @@ func (c *Client) WithLogger() *Client {
 // SetTimeout sets timeout for request raised from client.
 func (c *Client) SetTimeout(timeout time.Duration) *Client {
 	c.cli.Timeout = timeout
+	c.invalidateConfigSnapshot()
 	return c
 }
 
-// SetTLSClientConfig sets TLSClientConfig.
+// SetTLSClientConfig sets TLSClientConfig. It swaps the transport
+// atomically rather than mutating the live one in place, so it's safe to
+// call while the client is handling traffic: requests already in flight
+// finish on the transport they started on instead of racing with config.
 func (c *Client) SetTLSClientConfig(config *tls.Config) *Client {
-	transport, err := c.getTransport()
+	err := c.reconfigureTransport(func(t *http.Transport) {
+		t.TLSClientConfig = config
+	})
 	if err != nil {
 		c.printError(err)
-		return c
 	}
-	transport.TLSClientConfig = config
+	c.invalidateConfigSnapshot()
 	return c
 }
 
@@ -180,19 +494,26 @@ func (c *Client) SetKeepAlives(enable bool) *Client {
 	return c
 }
 
-// SetProxyFunc sets the Proxy function.
+// SetProxyFunc sets the Proxy function. Like SetTLSClientConfig, it swaps
+// the transport atomically instead of mutating the live one in place, so
+// it can be called safely while the client is handling traffic (e.g.
+// rotating proxies on a schedule). The old transport's idle connections
+// are closed after transportSwapGracePeriod, once requests already in
+// flight on it have had time to finish.
 func (c *Client) SetProxyFunc(proxy func(*http.Request) (*url.URL, error)) *Client {
-	transport, err := c.getTransport()
+	err := c.reconfigureTransport(func(t *http.Transport) {
+		t.Proxy = proxy
+	})
 	if err != nil {
 		c.printError(err)
-		return c
 	}
-	transport.Proxy = proxy
+	c.invalidateConfigSnapshot()
 	return c
 }
 
 // SetProxyURL sets the Proxy URL.
 func (c *Client) SetProxyURL(u *url.URL) *Client {
+	c.proxy = u
 	return c.SetProxyFunc(http.ProxyURL(u))
 }
 
@@ -209,50 +530,52 @@ func (c *Client) SetProxyURLByStr(rawurl string) *Client {
 // SetProxyFromEnvironment sets the Proxy URL.
 func (c *Client) SetProxyFromEnvironment(u bool) *Client {
 	c.proxyFromEnv = u
+	c.invalidateConfigSnapshot()
 	return c
 }
 
 // AddRootCert adds one or more root certificates into requests client
 func (c *Client) AddRootCert(cert *x509.Certificate) *Client {
-	config, err := c.getTLSConfig()
+	err := c.reconfigureTLSConfig(func(config *tls.Config) {
+		if config.RootCAs == nil {
+			config.RootCAs = x509.NewCertPool()
+		}
+		config.RootCAs.AddCert(cert)
+	})
 	if err != nil {
 		c.printError(err)
-		return c
 	}
-	if config.RootCAs == nil {
-		config.RootCAs = x509.NewCertPool()
-	}
-	config.RootCAs.AddCert(cert)
 	return c
 }
 
 // WithSystemCertPool sets system cert poll
 func (c *Client) WithSystemCertPool() *Client {
-	config, err := c.getTLSConfig()
-	if err != nil {
-		c.printError(err)
-		return c
-	}
-
-	if runtime.GOOS != "windows" {
+	err := c.reconfigureTLSConfig(func(config *tls.Config) {
+		if runtime.GOOS == "windows" {
+			return
+		}
 		ca, err := x509.SystemCertPool()
 		if err != nil {
 			c.printError(err)
-			return c
+			return
 		}
 		config.RootCAs = ca
+	})
+	if err != nil {
+		c.printError(err)
 	}
 	return c
 }
 
 // SetSkipVerify sets skip ca verify
 func (c *Client) SetSkipVerify(b bool) *Client {
-	config, err := c.getTLSConfig()
+	err := c.reconfigureTLSConfig(func(config *tls.Config) {
+		config.InsecureSkipVerify = b
+	})
 	if err != nil {
 		c.printError(err)
-		return c
 	}
-	config.InsecureSkipVerify = b
+	c.invalidateConfigSnapshot()
 	return c
 }
 
@@ -275,99 +598,373 @@ func (c *Client) NoRedirect() *Client {
 	})
 }
 
-// getTLSConfig returns a TLS config
-func (c *Client) getTLSConfig() (*tls.Config, error) {
-	transport, err := c.getTransport()
+// reconfigureTLSConfig is reconfigureTransport for fields nested under
+// TLSClientConfig, so a read-modify-write like AddRootCert swaps in a
+// cloned tls.Config instead of mutating the one in-flight requests may
+// already be using for a handshake.
+func (c *Client) reconfigureTLSConfig(mutate func(*tls.Config)) error {
+	return c.reconfigureTransport(func(t *http.Transport) {
+		config := &tls.Config{}
+		if t.TLSClientConfig != nil {
+			config = t.TLSClientConfig.Clone()
+		}
+		mutate(config)
+		t.TLSClientConfig = config
+	})
+}
+
+// getTransport returns the transport currently in effect.
+func (c *Client) getTransport() (*http.Transport, error) {
+	s, err := c.getTransportSwapper()
 	if err != nil {
 		return nil, err
 	}
-	if transport.TLSClientConfig == nil {
-		transport.TLSClientConfig = &tls.Config{}
+	return s.Load(), nil
+}
+
+// Process executes and returns response
+func (c *Client) Process(req *http.Request) (*http.Response, error) {
+	return c.cli.Do(req)
+}
+
+// processRequest sends hreq, the *http.Request built for req, using a
+// shallow copy of the client's http.Client with its own CheckRedirect
+// and/or Transport when req.SetFollowRedirect/SetMaxRedirects/SetProxyURL
+// override the client's policy for this one execution -- so the override
+// can never race state shared with other in-flight requests. With none
+// of those set, it's exactly Process.
+func (c *Client) processRequest(req *Request, hreq *http.Request) (*http.Response, error) {
+	req.redirectHistory = nil
+	if req.uploadProgress != nil && hreq.Body != nil {
+		// Wrapping the already-rewound per-attempt Body here, rather than
+		// in RawRequest or rewindRequestBody, makes the counter reset on
+		// every attempt for free and leaves GetBody -- which always reads
+		// from the original, unwrapped source -- untouched.
+		hreq.Body = newProgressReadCloser(hreq.Body, hreq.ContentLength, req.uploadProgress)
+	}
+	if req.followRedirect == nil && req.maxRedirects == nil && req.proxyURL == nil {
+		return c.Process(hreq)
 	}
-	return transport.TLSClientConfig, nil
+	cli := *c.cli
+	if req.followRedirect != nil || req.maxRedirects != nil {
+		if req.followRedirect != nil && !*req.followRedirect {
+			cli.CheckRedirect = func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			}
+		} else {
+			max := c.maxRedirects
+			if req.maxRedirects != nil {
+				max = *req.maxRedirects
+			}
+			cli.CheckRedirect = func(r *http.Request, via []*http.Request) error {
+				return c.checkRedirectMax(r, via, max)
+			}
+		}
+	}
+	if req.proxyURL != nil {
+		transport, err := c.getTransport()
+		if err != nil {
+			return nil, err
+		}
+		t := transport.Clone()
+		t.Proxy = http.ProxyURL(req.proxyURL)
+		cli.Transport = t
+	}
+	return cli.Do(hreq)
 }
 
-// getTransport returns a transport
-func (c *Client) getTransport() (*http.Transport, error) {
-	if c.cli.Transport == nil {
-		c.cli.Transport = &http.Transport{}
+// Transport returns an http.RoundTripper backed by this client, so the
+// client's TLS, proxy and logging configuration can be reused as the
+// transport of an httputil.ReverseProxy or any other library that accepts
+// a RoundTripper. The response body is left untouched for the caller to
+// stream and close.
+func (c *Client) Transport() http.RoundTripper {
+	return &clientRoundTripper{client: c}
+}
+
+// clientRoundTripper adapts a Client to the http.RoundTripper interface.
+type clientRoundTripper struct {
+	client *Client
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *clientRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.RequestURI = ""
+	t.client.printRawRequest(req)
+	resp, err := t.client.Process(req)
+	if err != nil {
+		t.client.printError(err)
+		return nil, err
 	}
+	t.client.printRawResponse(resp)
+	return resp, nil
+}
+
+// defaultRetryBackoffBase and defaultRetryBackoffMax are the backoff
+// bounds SetRetry uses when the request never called SetRetryBackoff.
+const (
+	defaultRetryBackoffBase = 100 * time.Millisecond
+	defaultRetryBackoffMax  = 10 * time.Second
+)
 
-	if transport, ok := c.cli.Transport.(*http.Transport); ok {
-		return transport, nil
+// waitForRetry decides whether do should send another attempt after
+// attemptNum, which the retry condition has already judged as
+// shouldRetry. It doesn't evaluate the condition itself -- just the
+// backoff and cancellation that gate it -- and logs the upcoming attempt
+// at LogInfo. It returns false without waiting if shouldRetry is false or
+// req's context is already done, and false if the context finishes
+// before the backoff does.
+func (c *Client) waitForRetry(req *Request, attemptNum int, shouldRetry bool) bool {
+	if !shouldRetry {
+		return false
+	}
+	ctx := req.rawRequest.Context()
+	if ctx.Err() != nil {
+		return false
+	}
+	wait := retryBackoffDuration(req, attemptNum)
+	c.printErrorLevel(fmt.Errorf("requests: %s %s attempt %d failed, retrying (attempt %d) after %s", req.method, req.baseURL, attemptNum, attemptNum+1, wait), LogInfo)
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
 	}
-	return nil, ErrNotTransport
 }
 
-// Process executes and returns response
-func (c *Client) Process(req *http.Request) (*http.Response, error) {
-	return c.cli.Do(req)
+// retryBackoffDuration returns the full-jitter exponential backoff before
+// the retry following attemptNum: a random duration in
+// [0, base*2^(attemptNum-1)], capped at max. base and max come from
+// req's SetRetryBackoff, or defaultRetryBackoffBase/defaultRetryBackoffMax
+// if it was never called.
+func retryBackoffDuration(req *Request, attemptNum int) time.Duration {
+	base := req.retryBackoffBase
+	if base <= 0 {
+		base = defaultRetryBackoffBase
+	}
+	max := req.retryBackoffMax
+	if max <= 0 {
+		max = defaultRetryBackoffMax
+	}
+	d := base << uint(attemptNum-1)
+	if d <= 0 || d > max {
+		d = max
+	}
+	return time.Duration(rand.Int63n(int64(d) + 1))
+}
+
+// rewindRequestBody replaces req.rawRequest.Body with a fresh read of the
+// original body for a retry, via the GetBody func RawRequest set up --
+// the same one the standard library uses to replay a 307/308 redirect. It
+// is a no-op if the request never had a body.
+func (c *Client) rewindRequestBody(req *Request) error {
+	if req.rawRequest.GetBody == nil {
+		return nil
+	}
+	body, err := req.rawRequest.GetBody()
+	if err != nil {
+		return err
+	}
+	req.rawRequest.Body = body
+	return nil
 }
 
 // do executes and returns response
 func (c *Client) do(req *Request) (*Response, error) {
+	var adaptiveKey string
+	if c.adaptiveTimeout != nil {
+		adaptiveKey = adaptiveTimeoutKey(req)
+		if !req.hasDeadline() {
+			req.SetDeadline(c.adaptiveTimeout.deadline(adaptiveKey))
+		}
+	}
+
 	_, err := req.RawRequest()
 	if err != nil {
 		return nil, err
 	}
+	defer req.cancelContext()
+	config := c.configSnapshot()
+
+	var eventID string
+	if c.eventSink != nil {
+		eventID = c.nextEventRequestID()
+		ctx := req.ctx
+		if ctx == nil {
+			ctx = req.rawRequest.Context()
+		}
+		req.ctx = context.WithValue(ctx, eventRequestIDContextKey, eventID)
+		req.withContext()
+	}
+	fail := func(err error) error {
+		c.emitEvent(Event{Kind: ErrorOccurred, RequestID: eventID, Method: req.method, URL: req.baseURL.String(), Err: err})
+		return err
+	}
+	c.emitEvent(Event{Kind: RequestStarted, RequestID: eventID, Method: req.method, URL: req.baseURL.String()})
+
+	if s := c.matchStub(req); s != nil {
+		req.sendAt = time.Now()
+		response := s.build(req)
+		response.stubbed = true
+		response.config = config
+		response.init(req.sendAt, req.method, req.baseURL)
+		c.printRequest(req)
+		c.printResponse(req, response)
+		c.emitEvent(Event{Kind: ResponseReceived, RequestID: eventID, Method: req.method, URL: req.baseURL.String(), StatusCode: response.StatusCode()})
+		return response, nil
+	}
 
 	hash := ""
 	if c.cache != nil {
 		hash, err = c.cache.Hash(req)
 		if err != nil {
-			return nil, err
+			return nil, fail(err)
 		}
 		if req.noCache {
 			err = c.cache.Del(hash)
 			if err != nil {
-				return nil, err
+				return nil, fail(err)
 			}
 		} else {
 			response, err := c.cache.Load(hash)
 			switch err {
 			default:
-				return nil, err
+				c.printErrorLevel(fmt.Errorf("requests: cache lookup for %q failed: %v", hash, err), LogError)
+				return nil, fail(err)
 			case nil:
+				atomic.AddInt64(&c.cacheStats.hits, 1)
 				c.printCacheHit(req)
+				response.config = config
 				response.init(req.sendAt, req.method, req.baseURL)
+				c.emitEvent(Event{Kind: CacheHit, RequestID: eventID, Method: req.method, URL: req.baseURL.String(), StatusCode: response.StatusCode(), CacheKey: hash})
 				return response, nil
 			case ErrNotExist:
-				// No action
+				atomic.AddInt64(&c.cacheStats.misses, 1)
+			case ErrCorrupt:
+				atomic.AddInt64(&c.cacheStats.corrupt, 1)
+				c.printErrorLevel(fmt.Errorf("requests: cache entry %q is corrupt, discarding: %v", hash, err), LogError)
+				if derr := c.cache.Del(hash); derr != nil {
+					c.printErrorLevel(fmt.Errorf("requests: failed to delete corrupt cache entry %q: %v", hash, derr), LogError)
+				}
 			}
 		}
 	}
-	c.printRequest(req)
-	req.sendAt = time.Now()
-	resp, err := c.Process(req.rawRequest)
-	if err != nil {
-		return nil, err
+	rewindable := req.rawRequest.Body == nil || req.rawRequest.GetBody != nil
+	retriesLeft := req.retryCount
+	if retriesLeft > 0 && !rewindable {
+		c.printErrorLevel(fmt.Errorf("requests: %s %s has a non-rewindable body, ignoring SetRetry", req.method, req.baseURL), LogInfo)
+		retriesLeft = 0
 	}
-	response, err := newResponse(resp)
-	if err != nil {
-		return nil, err
+	condition := req.retryCondition
+	if condition == nil {
+		condition = defaultRetryCondition
+	}
+
+	var response *Response
+	totalAttempts := 0
+	for attemptNum := 1; ; attemptNum++ {
+		c.setDeadlineHeader(req.rawRequest)
+		c.printRequest(req)
+		req.sendAt = time.Now()
+		resp, err := c.processRequest(req, req.rawRequest)
+		if err != nil {
+			if retriesLeft <= 0 || !c.waitForRetry(req, attemptNum, condition(nil, err)) {
+				if c.deferredQueue != nil && c.shouldDefer != nil && !req.deferredReplay && c.shouldDefer(req, err) {
+					if derr := c.deferRequest(req); derr != nil {
+						c.printError(derr)
+					}
+				}
+				return nil, fail(err)
+			}
+			totalAttempts++
+			retriesLeft--
+			c.emitEvent(Event{Kind: RequestRetried, RequestID: eventID, Method: req.method, URL: req.baseURL.String(), Attempt: totalAttempts + 1})
+			if err := c.rewindRequestBody(req); err != nil {
+				return nil, fail(err)
+			}
+			continue
+		}
+
+		attempts := 1
+		if resp.StatusCode == http.StatusExpectationFailed && req.rawRequest.Header.Get(HeaderExpect) != "" {
+			if fallback, ok := buildExpectContinueFallback(req.rawRequest); ok {
+				resp.Body.Close()
+				c.printErrorLevel(fmt.Errorf("requests: %s %s got 417 Expectation Failed, retrying without %s header", req.method, req.baseURL, HeaderExpect), LogInfo)
+				c.setDeadlineHeader(fallback)
+				c.emitEvent(Event{Kind: RequestRetried, RequestID: eventID, Method: req.method, URL: req.baseURL.String(), Attempt: totalAttempts + 2})
+				resp, err = c.processRequest(req, fallback)
+				if err != nil {
+					return nil, fail(err)
+				}
+				attempts = 2
+			}
+		}
+
+		response, err = newResponse(resp, c, req.doNotParseResponse, req.effectiveMaxResponseSize(), req.downloadProgress)
+		if err != nil {
+			if response == nil {
+				return nil, fail(err)
+			}
+			response.attempts = totalAttempts + attempts
+			response.wireSize = req.wireSize
+			response.config = config
+			response.init(req.sendAt, req.method, req.baseURL)
+			setRedirectFields(response, req, resp)
+			setTraceFields(response, req)
+			return response, fail(err)
+		}
+		totalAttempts += attempts
+		response.attempts = totalAttempts
+		response.wireSize = req.wireSize
+		response.jsonrpcID = req.jsonrpcID
+		response.jsonrpcBatchIDs = req.jsonrpcBatchIDs
+		response.config = config
+		response.init(req.sendAt, req.method, req.baseURL)
+		setRedirectFields(response, req, resp)
+		setTraceFields(response, req)
+		if c.adaptiveTimeout != nil {
+			c.adaptiveTimeout.observe(adaptiveKey, response.Time())
+		}
+		c.printResponse(req, response)
+
+		if retriesLeft <= 0 || !c.waitForRetry(req, attemptNum, condition(response, nil)) {
+			break
+		}
+		retriesLeft--
+		c.emitEvent(Event{Kind: RequestRetried, RequestID: eventID, Method: req.method, URL: req.baseURL.String(), Attempt: totalAttempts + 1})
+		if err := c.rewindRequestBody(req); err != nil {
+			return nil, fail(err)
+		}
 	}
-	response.init(req.sendAt, req.method, req.baseURL)
-	c.printResponse(response)
 	if c.cache != nil {
 		if code := response.StatusCode(); code >= 200 && code < 400 {
 			err = c.cache.Save(hash, response)
 			if err != nil {
-				return nil, err
+				return nil, fail(err)
 			}
+			c.emitEvent(Event{Kind: CacheStored, RequestID: eventID, Method: req.method, URL: req.baseURL.String(), StatusCode: response.StatusCode(), CacheKey: hash})
 		}
 	}
+	c.emitEvent(Event{Kind: ResponseReceived, RequestID: eventID, Method: req.method, URL: req.baseURL.String(), StatusCode: response.StatusCode()})
 	return response, nil
 }
 
 func (c *Client) printError(err error) {
-	if c.log != nil && c.logLevel >= LogError {
+	c.printErrorLevel(err, c.logLevel)
+}
+
+func (c *Client) printErrorLevel(err error, level logLevel) {
+	if c.log != nil && level >= LogError {
 		c.log.Printf("Error: %v", err.Error())
 	}
 }
 
 func (c *Client) printCacheHit(r *Request) {
-	if c.log != nil {
-		switch c.logLevel {
+	level := r.effectiveLogLevel()
+	if c.log != nil && level > LogError && c.shouldLog() {
+		switch level {
 		case LogInfo:
 			c.log.Printf("CacheHit: %s", r.String())
 		case LogMessageHead:
@@ -379,8 +976,9 @@ func (c *Client) printCacheHit(r *Request) {
 }
 
 func (c *Client) printRequest(r *Request) {
-	if c.log != nil {
-		switch c.logLevel {
+	level := r.effectiveLogLevel()
+	if c.log != nil && level > LogError && c.shouldLog() {
+		switch level {
 		case LogInfo:
 			c.log.Printf("Request:  %s", r.String())
 		case LogMessageHead:
@@ -391,15 +989,30 @@ func (c *Client) printRequest(r *Request) {
 	}
 }
 
-func (c *Client) printResponse(r *Response) {
-	if c.log != nil {
-		switch c.logLevel {
+// printResponse logs resp for the request that produced it, honoring the
+// request's effective log level.
+func (c *Client) printResponse(req *Request, resp *Response) {
+	level := req.effectiveLogLevel()
+	if c.log != nil && level > LogError && c.shouldLog() {
+		switch level {
 		case LogInfo:
-			c.log.Printf("Response: %s", r.String())
+			c.log.Printf("Response: %s", resp.String())
 		case LogMessageHead:
-			c.log.Printf("Response: %s", r.MessageHead())
+			c.log.Printf("Response: %s", resp.MessageHead())
 		case LogMessageAll:
-			c.log.Printf("Response: %s", r.Message())
+			c.log.Printf("Response: %s", resp.Message())
 		}
 	}
 }
+
+func (c *Client) printRawRequest(r *http.Request) {
+	if c.log != nil && c.logLevel >= LogInfo {
+		c.log.Printf("Request:  %s %s", r.Method, r.URL.String())
+	}
+}
+
+func (c *Client) printRawResponse(r *http.Response) {
+	if c.log != nil && c.logLevel >= LogInfo {
+		c.log.Printf("Response: %s %s %d", r.Request.Method, r.Request.URL.String(), r.StatusCode)
+	}
+}
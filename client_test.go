@@ -0,0 +1,146 @@
+package requests
+
+import (
+	"bytes"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"strings"
+	"testing"
+)
+
+func TestTransportReverseProxy(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+
+	mock.HandleFunc("/hello", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello"))
+	})
+
+	target := URL(mock.URL())
+	cli := NewClient()
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	proxy.Transport = cli.Transport()
+
+	ps := httptest.NewServer(proxy)
+	defer ps.Close()
+
+	resp, err := http.Get(ps.URL + "/hello")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if string(body) != "hello" {
+		t.Errorf("unexpected body: %s", body)
+	}
+}
+
+func TestLogSampling(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {})
+
+	buf := &bytes.Buffer{}
+	cli := NewClient()
+	cli.log = log.New(buf, "", 0)
+	cli.SetLogSampling(0.5)
+	cli.SetLogLevel(LogInfo)
+
+	const n = 400
+	for i := 0; i < n; i++ {
+		_, err := cli.NewRequest().SetURLByStr(mock.URL()).Get("")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	lines := strings.Count(buf.String(), "Request:")
+	if lines == 0 || lines >= n {
+		t.Errorf("expected roughly half of %d requests logged, got %d", n, lines)
+	}
+}
+
+func TestLogAlwaysLogsErrors(t *testing.T) {
+	buf := &bytes.Buffer{}
+	cli := NewClient()
+	cli.log = log.New(buf, "", 0)
+	cli.SetLogSampling(0.0001)
+	cli.SetLogRateLimit(1)
+	cli.SetLogLevel(LogInfo)
+
+	for i := 0; i < 20; i++ {
+		cli.printError(ErrNotTransport)
+	}
+
+	if strings.Count(buf.String(), "Error:") != 20 {
+		t.Error("error logging must never be suppressed")
+	}
+}
+
+func TestStubResponse(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+	hit := false
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		hit = true
+	})
+
+	cli := NewClient()
+	cli.StubResponse("GET /stubbed", func(r *Request) *Response {
+		return NewStubResponse(200, []byte("canned"), nil)
+	})
+
+	resp, err := cli.NewRequest().SetURLByStr(mock.URL()).Get("/stubbed")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !resp.Stubbed() {
+		t.Error("expected stubbed response")
+	}
+	if string(resp.Body()) != "canned" {
+		t.Errorf("unexpected body: %s", resp.Body())
+	}
+	if hit {
+		t.Error("stub must take precedence over the network")
+	}
+
+	resp, err = cli.NewRequest().SetURLByStr(mock.URL()).Get("/")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if resp.Stubbed() {
+		t.Error("non-matching request must not be stubbed")
+	}
+	if !hit {
+		t.Error("expected request to reach the network")
+	}
+}
@@ -0,0 +1,130 @@
+package requests
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ConfigSnapshot is an immutable, point-in-time copy of the Client
+// settings most useful for a bug report: timeout, proxy, TLS mode,
+// redirect limit, whether a deferred-failure queue is configured, base URL
+// and default headers (with secret-looking values redacted). Retry
+// settings (Request.SetRetry and friends) are not captured.
+// Client.configSnapshot builds one copy-on-write, caching
+// it until the next setter call invalidates it, so repeated requests on an
+// unchanged Client are cheap.
+type ConfigSnapshot struct {
+	Timeout                 time.Duration
+	BaseURL                 string
+	ProxyURL                string
+	ProxyFromEnvironment    bool
+	TLSInsecureSkipVerify   bool
+	MaxRedirects            int
+	DeferredQueueConfigured bool
+	DefaultHeaders          map[string]string
+}
+
+// String renders the snapshot as a stable, multi-line report suitable for
+// pasting into a bug report.
+func (s *ConfigSnapshot) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "ConfigSnapshot{\n")
+	fmt.Fprintf(&b, "\tTimeout: %s\n", s.Timeout)
+	fmt.Fprintf(&b, "\tBaseURL: %q\n", s.BaseURL)
+	fmt.Fprintf(&b, "\tProxyURL: %q\n", s.ProxyURL)
+	fmt.Fprintf(&b, "\tProxyFromEnvironment: %t\n", s.ProxyFromEnvironment)
+	fmt.Fprintf(&b, "\tTLSInsecureSkipVerify: %t\n", s.TLSInsecureSkipVerify)
+	fmt.Fprintf(&b, "\tMaxRedirects: %d\n", s.MaxRedirects)
+	fmt.Fprintf(&b, "\tDeferredQueueConfigured: %t\n", s.DeferredQueueConfigured)
+
+	names := make([]string, 0, len(s.DefaultHeaders))
+	for name := range s.DefaultHeaders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintf(&b, "\tDefaultHeaders: {\n")
+	for _, name := range names {
+		fmt.Fprintf(&b, "\t\t%s: %s\n", name, s.DefaultHeaders[name])
+	}
+	b.WriteString("\t}\n}")
+	return b.String()
+}
+
+// invalidateConfigSnapshot discards the cached snapshot, so the next call
+// to configSnapshot rebuilds it from the client's current settings. Every
+// setter that touches a field configSnapshot reads must call this.
+func (c *Client) invalidateConfigSnapshot() {
+	c.configMu.Lock()
+	c.cachedConfig = nil
+	c.configMu.Unlock()
+}
+
+// configSnapshot returns the client's cached ConfigSnapshot, rebuilding it
+// if a setter has invalidated it since the last call.
+func (c *Client) configSnapshot() *ConfigSnapshot {
+	c.configMu.Lock()
+	defer c.configMu.Unlock()
+	if c.cachedConfig != nil {
+		return c.cachedConfig
+	}
+
+	baseURL := ""
+	if c.baseURL != nil {
+		baseURL = c.baseURL.String()
+	}
+	proxyURL := ""
+	if c.proxy != nil {
+		proxyURL = c.proxy.Redacted()
+	}
+	insecureSkipVerify := false
+	if transport, err := c.getTransport(); err == nil && transport.TLSClientConfig != nil {
+		insecureSkipVerify = transport.TLSClientConfig.InsecureSkipVerify
+	}
+	maxRedirects := c.maxRedirects
+	if maxRedirects == 0 {
+		maxRedirects = defaultMaxRedirects
+	}
+
+	s := &ConfigSnapshot{
+		Timeout:                 c.cli.Timeout,
+		BaseURL:                 baseURL,
+		ProxyURL:                proxyURL,
+		ProxyFromEnvironment:    c.proxyFromEnv,
+		TLSInsecureSkipVerify:   insecureSkipVerify,
+		MaxRedirects:            maxRedirects,
+		DeferredQueueConfigured: c.deferredQueue != nil && c.shouldDefer != nil,
+		DefaultHeaders:          redactHeaders(c.defaultHeaders),
+	}
+	c.cachedConfig = s
+	return s
+}
+
+// redactHeaders copies headers, replacing the value of any header whose
+// name looks like it carries a credential (authorization, cookies,
+// tokens, API keys, ...) with "REDACTED", so a ConfigSnapshot is safe to
+// paste into a bug report.
+func redactHeaders(headers map[string]string) map[string]string {
+	if headers == nil {
+		return nil
+	}
+	out := make(map[string]string, len(headers))
+	for name, value := range headers {
+		if isSecretHeaderName(name) {
+			value = "REDACTED"
+		}
+		out[name] = value
+	}
+	return out
+}
+
+func isSecretHeaderName(name string) bool {
+	lower := strings.ToLower(name)
+	for _, substr := range []string{"authorization", "cookie", "token", "secret", "password", "api-key", "apikey"} {
+		if strings.Contains(lower, substr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package requests
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResponseClientConfigReflectsSettingsAtSendTime(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {})
+
+	client := NewClient().SetTimeout(time.Second).SetDefaultHeaders(map[string]string{
+		"Authorization": "Bearer abc123",
+		"X-Client":      "requests",
+	})
+
+	resp1, err := client.NewRequest().SetURLByStr(mock.URL()).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg1 := resp1.ClientConfig()
+	if cfg1 == nil {
+		t.Fatal("ClientConfig() = nil")
+	}
+	if cfg1.Timeout != time.Second {
+		t.Errorf("cfg1.Timeout = %s, want %s", cfg1.Timeout, time.Second)
+	}
+	if cfg1.DefaultHeaders["Authorization"] != "REDACTED" {
+		t.Errorf("cfg1.DefaultHeaders[Authorization] = %q, want REDACTED", cfg1.DefaultHeaders["Authorization"])
+	}
+	if cfg1.DefaultHeaders["X-Client"] != "requests" {
+		t.Errorf("cfg1.DefaultHeaders[X-Client] = %q, want unredacted", cfg1.DefaultHeaders["X-Client"])
+	}
+
+	client.SetTimeout(5 * time.Second)
+	resp2, err := client.NewRequest().SetURLByStr(mock.URL()).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg2 := resp2.ClientConfig()
+	if cfg2.Timeout != 5*time.Second {
+		t.Errorf("cfg2.Timeout = %s, want %s", cfg2.Timeout, 5*time.Second)
+	}
+
+	if cfg1.Timeout != time.Second {
+		t.Errorf("earlier response's snapshot mutated: cfg1.Timeout = %s, want %s", cfg1.Timeout, time.Second)
+	}
+}
+
+func TestConfigSnapshotCachedUntilSetterRuns(t *testing.T) {
+	client := NewClient()
+
+	first := client.configSnapshot()
+	second := client.configSnapshot()
+	if first != second {
+		t.Error("configSnapshot() should return the cached pointer when nothing has changed")
+	}
+
+	client.SetMaxRedirects(3)
+	third := client.configSnapshot()
+	if third == first {
+		t.Error("configSnapshot() should rebuild after a setter invalidates the cache")
+	}
+	if third.MaxRedirects != 3 {
+		t.Errorf("third.MaxRedirects = %d, want 3", third.MaxRedirects)
+	}
+}
+
+func TestConfigSnapshotStringIncludesKeyFields(t *testing.T) {
+	client := NewClient().SetTimeout(2 * time.Second).SetMaxRedirects(4)
+	s := client.configSnapshot().String()
+	if !strings.Contains(s, "MaxRedirects: 4") || !strings.Contains(s, "Timeout: 2s") {
+		t.Errorf("String() = %q, missing expected fields", s)
+	}
+}
@@ -0,0 +1,88 @@
+package requests
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestCloneDoesNotShareParamSlicesBetweenClones(t *testing.T) {
+	// Three pre-existing headers leave spare capacity in the backing
+	// array (Go's slice growth: 1, 2, 4, ...), which is exactly the
+	// condition that lets two clones silently collide when Clone doesn't
+	// give each of them an independent paramPairs array.
+	template := NewRequest().
+		SetHeader("X-Common-1", "base1").
+		SetHeader("X-Common-2", "base2").
+		SetHeader("X-Common-3", "base3")
+
+	var wg sync.WaitGroup
+	a := template.Clone()
+	b := template.Clone()
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			a.SetHeader("X-A", "a")
+			a.SetQuery("qa", "a")
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			b.SetHeader("X-B", "b")
+			b.SetQuery("qb", "b")
+		}
+	}()
+	wg.Wait()
+
+	if _, ok := a.headerParam.Search("X-B"); ok {
+		t.Errorf("clone a should not see clone b's header")
+	}
+	if _, ok := b.headerParam.Search("X-A"); ok {
+		t.Errorf("clone b should not see clone a's header")
+	}
+	if _, ok := a.queryParam.Search("qb"); ok {
+		t.Errorf("clone a should not see clone b's query")
+	}
+	if _, ok := b.queryParam.Search("qa"); ok {
+		t.Errorf("clone b should not see clone a's query")
+	}
+	if pp, ok := a.headerParam.Search("X-Common-1"); !ok || pp.Value != "base1" {
+		t.Errorf("clone a lost the shared template header")
+	}
+	if pp, ok := b.headerParam.Search("X-Common-1"); !ok || pp.Value != "base1" {
+		t.Errorf("clone b lost the shared template header")
+	}
+	if _, ok := template.headerParam.Search("X-A"); ok {
+		t.Errorf("template should not see clone a's header")
+	}
+	if _, ok := template.headerParam.Search("X-B"); ok {
+		t.Errorf("template should not see clone b's header")
+	}
+}
+
+func TestCloneDoesNotShareMultiFilesBetweenClones(t *testing.T) {
+	template := NewRequest().
+		SetFile("shared-1", "shared1.txt", MimeOctetStream, nil).
+		SetFile("shared-2", "shared2.txt", MimeOctetStream, nil).
+		SetFile("shared-3", "shared3.txt", MimeOctetStream, nil)
+
+	a := template.Clone().SetFile("a", "a.txt", MimeOctetStream, nil)
+	b := template.Clone().SetFile("b", "b.txt", MimeOctetStream, nil)
+
+	if len(template.multiFiles) != 3 {
+		t.Errorf("template.multiFiles = %d entries, want 3", len(template.multiFiles))
+	}
+	if len(a.multiFiles) != 4 {
+		t.Errorf("a.multiFiles = %d entries, want 4", len(a.multiFiles))
+	}
+	if len(b.multiFiles) != 4 {
+		t.Errorf("b.multiFiles = %d entries, want 4", len(b.multiFiles))
+	}
+	if a.multiFiles[3].Param != "a" {
+		t.Errorf("a.multiFiles[3].Param = %q, want %q", a.multiFiles[3].Param, "a")
+	}
+	if b.multiFiles[3].Param != "b" {
+		t.Errorf("b.multiFiles[3].Param = %q, want %q", b.multiFiles[3].Param, "b")
+	}
+}
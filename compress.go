@@ -0,0 +1,212 @@
+package requests
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Encoder wraps w so that writes to the returned io.WriteCloser are
+// compressed into w. Close must flush and finalize the stream.
+type Encoder func(w io.Writer) io.WriteCloser
+
+// Decoder wraps r so that reads from the returned io.ReadCloser are
+// decompressed from r.
+type Decoder func(r io.Reader) (io.ReadCloser, error)
+
+type codec struct {
+	enc Encoder
+	dec Decoder
+}
+
+var encodingRegistry = struct {
+	mu     sync.RWMutex
+	codecs map[string]codec
+}{codecs: map[string]codec{}}
+
+func init() {
+	RegisterEncoding("gzip", func(w io.Writer) io.WriteCloser {
+		return gzip.NewWriter(w)
+	}, func(r io.Reader) (io.ReadCloser, error) {
+		return gzip.NewReader(r)
+	})
+	RegisterEncoding("deflate", func(w io.Writer) io.WriteCloser {
+		fw, _ := flate.NewWriter(w, flate.DefaultCompression)
+		return fw
+	}, func(r io.Reader) (io.ReadCloser, error) {
+		return flate.NewReader(r), nil
+	})
+}
+
+// RegisterEncoding registers a codec for Content-Encoding/Accept-Encoding
+// name, used by Request.SetCompressBody for request bodies and
+// automatically for response decompression. Either enc or dec may be nil
+// if that direction isn't supported. Registering a name a second time
+// replaces the previous codec.
+func RegisterEncoding(name string, enc Encoder, dec Decoder) {
+	encodingRegistry.mu.Lock()
+	defer encodingRegistry.mu.Unlock()
+	encodingRegistry.codecs[name] = codec{enc: enc, dec: dec}
+}
+
+// RegisteredEncodings returns the names of every codec with a registered
+// decoder, sorted, suitable for deriving an Accept-Encoding header.
+func RegisteredEncodings() []string {
+	encodingRegistry.mu.RLock()
+	defer encodingRegistry.mu.RUnlock()
+	names := make([]string, 0, len(encodingRegistry.codecs))
+	for name, c := range encodingRegistry.codecs {
+		if c.dec != nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func lookupEncoder(name string) (Encoder, bool) {
+	encodingRegistry.mu.RLock()
+	defer encodingRegistry.mu.RUnlock()
+	c, ok := encodingRegistry.codecs[name]
+	if !ok || c.enc == nil {
+		return nil, false
+	}
+	return c.enc, true
+}
+
+func lookupDecoder(name string) (Decoder, bool) {
+	encodingRegistry.mu.RLock()
+	defer encodingRegistry.mu.RUnlock()
+	c, ok := encodingRegistry.codecs[name]
+	if !ok || c.dec == nil {
+		return nil, false
+	}
+	return c.dec, true
+}
+
+// SetCompressBody compresses the request body with the codec registered
+// under name (e.g. "gzip", "deflate", or one added via RegisterEncoding)
+// and sets the Content-Encoding header accordingly. The body is streamed
+// through the codec as it's sent rather than compressed into memory up
+// front, so RawRequest also drops Content-Length and lets the transport
+// send it chunked. It composes with SetJSON, SetForm and multipart bodies,
+// since it applies to whatever fill() assembled into the body, and is a
+// no-op when no body was ever set.
+func (r *Request) SetCompressBody(name string) *Request {
+	r.compressEncoding = name
+	return r
+}
+
+// SetAcceptEncoding sets the Accept-Encoding header the client advertises,
+// deriving it from every codec with a registered decoder unless names are
+// given explicitly.
+func (c *Client) SetAcceptEncoding(names ...string) *Client {
+	if len(names) == 0 {
+		names = RegisteredEncodings()
+	}
+	c.acceptEncoding = names
+	return c
+}
+
+// SetAcceptEncoding sets the Accept-Encoding header for this request,
+// overriding Client.SetAcceptEncoding. It derives the header from every
+// codec with a registered decoder unless names are given explicitly, so
+// the request never advertises an encoding it can't decode.
+func (r *Request) SetAcceptEncoding(names ...string) *Request {
+	if len(names) == 0 {
+		names = RegisteredEncodings()
+	}
+	r.SetHeader(HeaderAcceptEncoding, strings.Join(names, ", "))
+	return r
+}
+
+// compressBody compresses body with the codec registered under name,
+// returning the compressed bytes.
+func compressBody(name string, body io.Reader) ([]byte, error) {
+	enc, ok := lookupEncoder(name)
+	if !ok {
+		return nil, fmt.Errorf("requests: no registered encoder for %q", name)
+	}
+	var buf bytes.Buffer
+	w := enc(&buf)
+	if _, err := io.Copy(w, body); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// compressBodyStream returns a reader that produces body compressed with
+// the codec registered under name, one chunk at a time via an io.Pipe,
+// instead of compressing the whole body into memory before the request is
+// sent. A goroutine drives the encoder; any error from reading body or
+// from the encoder itself is delivered to the reader side through the
+// pipe instead of being returned here.
+func compressBodyStream(name string, body io.Reader) (io.ReadCloser, error) {
+	enc, ok := lookupEncoder(name)
+	if !ok {
+		return nil, fmt.Errorf("requests: no registered encoder for %q", name)
+	}
+	pr, pw := io.Pipe()
+	go func() {
+		w := enc(pw)
+		_, err := io.Copy(w, body)
+		if cerr := w.Close(); err == nil {
+			err = cerr
+		}
+		pw.CloseWithError(err)
+	}()
+	return pr, nil
+}
+
+// decodeContentEncoding wraps body with the decoder(s) registered for
+// encoding, which may list several comma-separated names (e.g.
+// "zstd, gzip") when a response went through more than one codec.
+// Per RFC 7231 section 3.1.2.2, encodings are listed in the order they
+// were applied, so they're undone in reverse: the last-listed
+// (outermost) layer first. An unknown name within the list passes
+// through unchanged at that layer and contributes to the returned
+// warning message for the caller to log, rather than failing outright.
+func decodeContentEncoding(body io.Reader, encoding string) (io.Reader, string, error) {
+	if encoding == "" || encoding == "identity" {
+		return body, "", nil
+	}
+	parts := strings.Split(encoding, ",")
+	var warnings []string
+	for i := len(parts) - 1; i >= 0; i-- {
+		name := strings.TrimSpace(parts[i])
+		if name == "" || name == "identity" {
+			continue
+		}
+		dec, ok := lookupDecoder(name)
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("requests: unknown Content-Encoding %q, passing through uncompressed", name))
+			continue
+		}
+		rc, err := dec(body)
+		if err != nil {
+			return nil, "", wrapContentEncodingErr(err, name)
+		}
+		body = rc
+	}
+	return body, strings.Join(warnings, "; "), nil
+}
+
+// wrapContentEncodingErr adds encoding to err, since a raw gzip/flate
+// error like "unexpected EOF" or "invalid header" on its own doesn't say
+// what was being decoded. A decoder like flate's that defers its first
+// real read until later surfaces corruption here too, not just from
+// decodeContentEncoding's initial call.
+func wrapContentEncodingErr(err error, encoding string) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("requests: decoding Content-Encoding %q: %w", encoding, err)
+}
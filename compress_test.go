@@ -0,0 +1,293 @@
+package requests
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type xorWriter struct {
+	w io.Writer
+}
+
+func (x xorWriter) Write(p []byte) (int, error) {
+	out := make([]byte, len(p))
+	for i, b := range p {
+		out[i] = b ^ 0xFF
+	}
+	return x.w.Write(out)
+}
+
+func (x xorWriter) Close() error { return nil }
+
+type xorReader struct {
+	r io.Reader
+}
+
+func (x xorReader) Read(p []byte) (int, error) {
+	n, err := x.r.Read(p)
+	for i := 0; i < n; i++ {
+		p[i] ^= 0xFF
+	}
+	return n, err
+}
+
+func (x xorReader) Close() error { return nil }
+
+func TestRegisterEncodingRoundTrip(t *testing.T) {
+	RegisterEncoding("xor", func(w io.Writer) io.WriteCloser {
+		return xorWriter{w}
+	}, func(r io.Reader) (io.ReadCloser, error) {
+		return xorReader{r}, nil
+	})
+
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+
+	const want = "hello, requests"
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(HeaderContentEncoding) != "xor" {
+			t.Errorf("request Content-Encoding = %q, want xor", r.Header.Get(HeaderContentEncoding))
+		}
+		body, _ := ioutil.ReadAll(xorReader{r.Body})
+		if string(body) != want {
+			t.Errorf("request body = %q, want %q", body, want)
+		}
+		w.Header().Set(HeaderContentEncoding, "xor")
+		out := make([]byte, len(body))
+		for i, b := range body {
+			out[i] = b ^ 0xFF
+		}
+		w.Write(out)
+	})
+
+	resp, err := NewRequest().SetURLByStr(mock.URL()).
+		SetBody(strings.NewReader(want)).
+		SetCompressBody("xor").
+		Post("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(resp.Body()) != want {
+		t.Errorf("response body = %q, want %q", resp.Body(), want)
+	}
+}
+
+func TestSetCompressBodyGzip(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	want := strings.Repeat("hello, gzip compressed request body! ", 1000)
+	var gotEncoding string
+	var gotBody string
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		gotEncoding = r.Header.Get(HeaderContentEncoding)
+		if r.Header.Get(HeaderContentLength) != "" {
+			t.Errorf("request Content-Length = %q, want unset (streamed, unknown length)", r.Header.Get(HeaderContentLength))
+		}
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := ioutil.ReadAll(zr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gotBody = string(body)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	_, err = NewRequest().SetURLByStr(mock.URL()).
+		SetBodyString(want).
+		SetCompressBody("gzip").
+		Post("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotEncoding != "gzip" {
+		t.Errorf("Content-Encoding = %q, want gzip", gotEncoding)
+	}
+	if gotBody != want {
+		t.Errorf("decompressed body = %q, want %q", gotBody, want)
+	}
+}
+
+func TestSetCompressBodyDeflateComposesWithJSON(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	var gotBody string
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fr := flate.NewReader(r.Body)
+		body, err := ioutil.ReadAll(fr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gotBody = string(body)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	_, err = NewRequest().SetURLByStr(mock.URL()).
+		SetJSON(map[string]string{"hello": "world"}).
+		SetCompressBody("deflate").
+		Post("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(gotBody, `"hello":"world"`) {
+		t.Errorf("decompressed body = %q, want it to contain the JSON payload", gotBody)
+	}
+}
+
+func TestResponseDecompressesGzipWithManuallySetAcceptEncoding(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	const want = "hello, manually negotiated gzip"
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(HeaderAcceptEncoding) != "gzip" {
+			t.Errorf("Accept-Encoding = %q, want gzip", r.Header.Get(HeaderAcceptEncoding))
+		}
+		w.Header().Set(HeaderContentEncoding, "gzip")
+		gw := gzip.NewWriter(w)
+		gw.Write([]byte(want))
+		gw.Close()
+	})
+
+	resp, err := NewRequest().SetURLByStr(mock.URL()).
+		SetHeader(HeaderAcceptEncoding, "gzip").
+		Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(resp.Body()) != want {
+		t.Errorf("Body() = %q, want %q", resp.Body(), want)
+	}
+	if got := resp.Header().Get(HeaderContentEncoding); got != "" {
+		t.Errorf("Content-Encoding header = %q, want stripped once decoded", got)
+	}
+	if resp.ContentEncoding() != "gzip" {
+		t.Errorf("ContentEncoding() = %q, want gzip", resp.ContentEncoding())
+	}
+}
+
+func TestResponseCorruptedGzipReturnsWrappedError(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderContentEncoding, "gzip")
+		w.Write([]byte("not actually gzip"))
+	})
+
+	_, err = NewRequest().SetURLByStr(mock.URL()).
+		SetHeader(HeaderAcceptEncoding, "gzip").
+		Get("/")
+	if err == nil {
+		t.Fatal("want an error for a corrupted gzip body")
+	}
+	if !strings.Contains(err.Error(), `Content-Encoding "gzip"`) {
+		t.Errorf("error = %v, want it to name the Content-Encoding", err)
+	}
+}
+
+func TestResponseCompressedSizeTracksWireSizeAccounting(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	want := strings.Repeat("a", 10000)
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderContentEncoding, "gzip")
+		gw := gzip.NewWriter(w)
+		gw.Write([]byte(want))
+		gw.Close()
+	})
+
+	client := NewClient().SetWireSizeAccounting(true)
+	resp, err := client.NewRequest().SetURLByStr(mock.URL()).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(resp.Body()) != want {
+		t.Fatal("decompressed body mismatch")
+	}
+	if resp.CompressedSize() == 0 || resp.CompressedSize() >= int64(resp.Size()) {
+		t.Errorf("CompressedSize() = %d, want a nonzero value smaller than Size() = %d", resp.CompressedSize(), resp.Size())
+	}
+}
+
+func TestResponseDecodesMultipleContentEncodingsInReverseOrder(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	// Applied in listed order: gzip first, then deflate over the gzip
+	// output, so Content-Encoding lists "gzip, deflate" and must be
+	// undone deflate-first.
+	const want = "hello, chained content-encodings"
+	var gzipped bytes.Buffer
+	gw := gzip.NewWriter(&gzipped)
+	gw.Write([]byte(want))
+	gw.Close()
+
+	var both bytes.Buffer
+	fw, _ := flate.NewWriter(&both, flate.DefaultCompression)
+	fw.Write(gzipped.Bytes())
+	fw.Close()
+
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderContentEncoding, "gzip, deflate")
+		w.Write(both.Bytes())
+	})
+
+	resp, err := NewRequest().SetURLByStr(mock.URL()).
+		SetAcceptEncoding("gzip", "deflate").
+		Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(resp.Body()) != want {
+		t.Errorf("Body() = %q, want %q", resp.Body(), want)
+	}
+}
+
+func TestSetCompressBodySkippedForNoBody(t *testing.T) {
+	req := NewRequest().SetCompressBody("gzip")
+	req.method = MethodGet
+	req.SetURLByStr("http://example.invalid/")
+
+	rawReq, err := req.RawRequest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := rawReq.Header.Get(HeaderContentEncoding); got != "" {
+		t.Errorf("Content-Encoding = %q, want unset when no body was set", got)
+	}
+}
@@ -0,0 +1,111 @@
+package requests
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestSetIfNoneMatchQuotesAndTriggers304(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	const etag = `"v1"`
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderETag, etag)
+		if r.Header.Get(HeaderIfNoneMatch) == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Write([]byte("body"))
+	})
+
+	resp, err := NewRequest().SetURLByStr(mock.URL()).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.ETag() != etag {
+		t.Errorf("ETag() = %q, want %q", resp.ETag(), etag)
+	}
+
+	resp, err = NewRequest().SetURLByStr(mock.URL()).SetIfNoneMatch("v1").Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.IsNotModified() {
+		t.Errorf("StatusCode = %d, want 304", resp.StatusCode())
+	}
+}
+
+func TestSetIfMatchQuotesAlreadyQuotedUnchanged(t *testing.T) {
+	req := NewRequest().SetIfMatch(`"already-quoted"`)
+	p, ok := req.headerParam.Search(HeaderIfMatch)
+	if !ok || p.Value != `"already-quoted"` {
+		t.Errorf("If-Match = %v, %v, want %q", p, ok, `"already-quoted"`)
+	}
+}
+
+func TestSetIfModifiedSinceFormatsAsHTTPDate(t *testing.T) {
+	when := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	req := NewRequest().SetIfModifiedSince(when)
+	p, ok := req.headerParam.Search(HeaderIfModifiedSince)
+	if !ok || p.Value != when.Format(http.TimeFormat) {
+		t.Errorf("If-Modified-Since = %v, %v, want %q", p, ok, when.Format(http.TimeFormat))
+	}
+}
+
+func TestSetIfUnmodifiedSinceSetsHeader(t *testing.T) {
+	when := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	req := NewRequest().SetIfUnmodifiedSince(when)
+	if _, ok := req.headerParam.Search(HeaderIfUnmodifiedSince); !ok {
+		t.Error("If-Unmodified-Since not set")
+	}
+}
+
+func TestLastModifiedParsesHeader(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	when := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderLastModified, when.Format(http.TimeFormat))
+	})
+
+	resp, err := NewRequest().SetURLByStr(mock.URL()).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, ok := resp.LastModified()
+	if !ok || !got.Equal(when) {
+		t.Errorf("LastModified() = %v, %v, want %v, true", got, ok, when)
+	}
+}
+
+func TestLastModifiedAbsent(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {})
+
+	resp, err := NewRequest().SetURLByStr(mock.URL()).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := resp.LastModified(); ok {
+		t.Error("LastModified() ok = true without a Last-Modified header")
+	}
+}
@@ -0,0 +1,151 @@
+package requests
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type connLabelContextKeyType struct{}
+
+var connLabelContextKey = connLabelContextKeyType{}
+
+// ConnInfo describes one currently open connection tracked via
+// Client.SetConnectionLabeler, for attributing sockets back to the code
+// that opened them when a box has thousands of them.
+type ConnInfo struct {
+	Label      string
+	LocalAddr  net.Addr
+	RemoteAddr net.Addr
+	CreatedAt  time.Time
+	LastUsed   time.Time
+	Requests   int64
+}
+
+// SetConnectionLabeler installs label, which derives a name from the
+// request about to be dialed (e.g. its host or a caller-supplied tag).
+// The label is recorded once per connection rather than per request, and
+// the live inventory is available through OpenConnections. Like
+// SetTLSClientConfig, it swaps the transport atomically rather than
+// mutating the live one in place, so it's safe to call while the client
+// is handling traffic.
+func (c *Client) SetConnectionLabeler(label func(req *http.Request) string) *Client {
+	c.connLabeler = label
+
+	err := c.reconfigureTransport(func(t *http.Transport) {
+		baseDial := t.DialContext
+		if baseDial == nil {
+			baseDial = (&net.Dialer{}).DialContext
+		}
+		t.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			conn, err := baseDial(ctx, network, addr)
+			if err != nil {
+				return nil, err
+			}
+			label, _ := ctx.Value(connLabelContextKey).(string)
+			return c.trackConn(conn, label), nil
+		}
+	})
+	if err != nil {
+		c.printError(err)
+	}
+	return c
+}
+
+// OpenConnections returns a snapshot of every connection currently tracked
+// because it was opened under Client.SetConnectionLabeler.
+func (c *Client) OpenConnections() []ConnInfo {
+	c.connMu.Lock()
+	defer c.connMu.Unlock()
+	infos := make([]ConnInfo, 0, len(c.conns))
+	for lc := range c.conns {
+		infos = append(infos, lc.snapshot())
+	}
+	return infos
+}
+
+func (c *Client) trackConn(conn net.Conn, label string) net.Conn {
+	now := time.Now()
+	lc := &labeledConn{
+		Conn:   conn,
+		client: c,
+		info: ConnInfo{
+			Label:      label,
+			LocalAddr:  conn.LocalAddr(),
+			RemoteAddr: conn.RemoteAddr(),
+			CreatedAt:  now,
+			LastUsed:   now,
+		},
+	}
+	c.connMu.Lock()
+	if c.conns == nil {
+		c.conns = map[*labeledConn]struct{}{}
+	}
+	c.conns[lc] = struct{}{}
+	c.connMu.Unlock()
+	return lc
+}
+
+func (c *Client) untrackConn(lc *labeledConn) {
+	c.connMu.Lock()
+	delete(c.conns, lc)
+	c.connMu.Unlock()
+}
+
+// labeledConn wraps a net.Conn dialed by a labeler-equipped Client,
+// tracking usage and request counts for OpenConnections.
+type labeledConn struct {
+	net.Conn
+	client *Client
+	mu     sync.Mutex
+	info   ConnInfo
+}
+
+func (lc *labeledConn) snapshot() ConnInfo {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	return lc.info
+}
+
+func (lc *labeledConn) Write(p []byte) (int, error) {
+	lc.mu.Lock()
+	lc.info.LastUsed = time.Now()
+	if looksLikeRequestLine(p) {
+		lc.info.Requests++
+	}
+	lc.mu.Unlock()
+	return lc.Conn.Write(p)
+}
+
+func (lc *labeledConn) Read(p []byte) (int, error) {
+	n, err := lc.Conn.Read(p)
+	lc.mu.Lock()
+	lc.info.LastUsed = time.Now()
+	lc.mu.Unlock()
+	return n, err
+}
+
+func (lc *labeledConn) Close() error {
+	lc.client.untrackConn(lc)
+	return lc.Conn.Close()
+}
+
+// httpRequestLinePrefixes lists the request-line prefixes looksLikeRequestLine
+// recognizes to approximate a per-connection request count.
+var httpRequestLinePrefixes = []string{
+	"GET ", "HEAD ", "POST ", "PUT ", "DELETE ", "CONNECT ", "OPTIONS ", "TRACE ", "PATCH ",
+}
+
+// looksLikeRequestLine reports whether p begins with an HTTP request line,
+// used as a best-effort way to count requests multiplexed over one
+// keep-alive connection without parsing the full HTTP wire protocol.
+func looksLikeRequestLine(p []byte) bool {
+	for _, prefix := range httpRequestLinePrefixes {
+		if len(p) >= len(prefix) && string(p[:len(prefix)]) == prefix {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package requests
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestConnectionLabeler(t *testing.T) {
+	mockA, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mockA.Close()
+	mockA.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {})
+
+	mockB, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mockB.Close()
+	mockB.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {})
+
+	cli := NewClient().SetConnectionLabeler(func(req *http.Request) string {
+		return req.URL.Host
+	})
+
+	if _, err := cli.NewRequest().Get(mockA.URL()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cli.NewRequest().Get(mockB.URL()); err != nil {
+		t.Fatal(err)
+	}
+
+	conns := cli.OpenConnections()
+	if len(conns) != 2 {
+		t.Fatalf("expected 2 open connections, got %d", len(conns))
+	}
+
+	labels := map[string]bool{}
+	for _, c := range conns {
+		labels[c.Label] = true
+		if c.Requests < 1 {
+			t.Errorf("expected at least 1 request on conn %q, got %d", c.Label, c.Requests)
+		}
+	}
+	if len(labels) != 2 {
+		t.Errorf("expected 2 distinct labels, got %v", labels)
+	}
+}
@@ -0,0 +1,112 @@
+package requests
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+)
+
+func TestSetContentLengthOverridesDetectedLength(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	var gotContentLength int64
+	var gotTransferEncoding []string
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		gotContentLength = r.ContentLength
+		gotTransferEncoding = r.TransferEncoding
+		ioutil.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	_, err = NewRequest().SetURLByStr(mock.URL()).
+		SetBodyString("hi").
+		SetContentLength(2).
+		Post("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotContentLength != 2 {
+		t.Errorf("Content-Length = %d, want 2", gotContentLength)
+	}
+	if len(gotTransferEncoding) != 0 {
+		t.Errorf("Transfer-Encoding = %v, want none", gotTransferEncoding)
+	}
+}
+
+func TestRawRequestAutoDetectsFileContentLength(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	f, err := ioutil.TempFile("", "requests-contentlength-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString("hello file body"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var gotContentLength int64
+	var gotTransferEncoding []string
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		gotContentLength = r.ContentLength
+		gotTransferEncoding = r.TransferEncoding
+		ioutil.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	_, err = NewRequest().SetURLByStr(mock.URL()).
+		SetBody(f).
+		Post("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotContentLength != 15 {
+		t.Errorf("Content-Length = %d, want 15", gotContentLength)
+	}
+	if len(gotTransferEncoding) != 0 {
+		t.Errorf("Transfer-Encoding = %v, want none", gotTransferEncoding)
+	}
+}
+
+func TestSetChunkedForcesChunkedEvenWithKnownLength(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	var gotTransferEncoding []string
+	var gotBody []byte
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		gotTransferEncoding = r.TransferEncoding
+		gotBody, _ = ioutil.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	_, err = NewRequest().SetURLByStr(mock.URL()).
+		SetBodyString("chunk me").
+		SetChunked(true).
+		Post("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gotTransferEncoding) == 0 || gotTransferEncoding[0] != "chunked" {
+		t.Errorf("Transfer-Encoding = %v, want [chunked]", gotTransferEncoding)
+	}
+	if string(gotBody) != "chunk me" {
+		t.Errorf("body = %q, want %q", gotBody, "chunk me")
+	}
+}
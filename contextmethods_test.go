@@ -0,0 +1,94 @@
+package requests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestGetCtxCancellationSurfacesAsContextCanceled(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	unblock := make(chan struct{})
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		<-unblock
+		w.WriteHeader(http.StatusOK)
+	})
+	defer close(unblock)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+
+	_, err = NewRequest().GetCtx(ctx, mock.URL()+"/")
+	if err == nil {
+		t.Fatal("expected an error from the cancelled context")
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, does not wrap context.Canceled", err)
+	}
+}
+
+func TestDoContextDoesNotMutateTemplate(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	template := NewRequest().SetMethod(MethodGet).SetURLByStr(mock.URL() + "/")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if _, err := template.DoContext(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if template.ctx != nil {
+		t.Errorf("template.ctx = %v, want nil -- DoContext must not mutate the template", template.ctx)
+	}
+
+	// Sending the template again afterwards, with no context at all, must
+	// still work -- it was never poisoned by the earlier DoContext call.
+	if _, err := template.Clone().do(); err != nil {
+		t.Fatalf("template unusable after DoContext: %v", err)
+	}
+}
+
+func TestGetCtxArgumentOverridesStoredContext(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	type key struct{}
+	stored := context.WithValue(context.Background(), key{}, "stored")
+	argument := context.WithValue(context.Background(), key{}, "argument")
+
+	template := NewRequest().SetContext(stored)
+	resp, err := template.GetCtx(argument, mock.URL()+"/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode() != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode())
+	}
+	// The argument replaces the stored context outright, so the template
+	// itself -- whose context SetContext put in place -- is untouched.
+	if template.ctx.Value(key{}) != "stored" {
+		t.Errorf("template.ctx was mutated by GetCtx")
+	}
+}
@@ -0,0 +1,49 @@
+package requests
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+	"testing"
+)
+
+func TestSetCookies(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+
+	var got []*http.Cookie
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		got = r.Cookies()
+	})
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cli := NewClient()
+	cli.cli.Jar = jar
+
+	req := cli.NewRequest().SetURLByStr(mock.URL()).
+		SetCookie(&http.Cookie{Name: "from-set-cookie", Value: "1"}).
+		SetCookies(Cookies("a=1; b=2"))
+
+	cloned := req.Clone()
+	if _, err := cloned.Get("/"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{"from-set-cookie": "1", "a": "1", "b": "2"}
+	if len(got) != len(want) {
+		t.Fatalf("got %d cookies, want %d: %v", len(got), len(want), got)
+	}
+	for _, c := range got {
+		if want[c.Name] != c.Value {
+			t.Errorf("cookie %s = %q, want %q", c.Name, c.Value, want[c.Name])
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package requests
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestCurlCommandRendersMethodHeaderAndBody(t *testing.T) {
+	req := NewRequest().
+		SetURLByStr("http://example.com/items").
+		SetMethod(MethodPost).
+		SetHeader("X-Trace", "abc").
+		SetBodyString("hello")
+
+	cmd, err := req.CurlCommand()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"curl -X 'POST'",
+		"-H 'X-Trace: abc'",
+		"--data-binary 'hello'",
+		"'http://example.com/items'",
+	} {
+		if !strings.Contains(cmd, want) {
+			t.Errorf("CurlCommand() = %q, want it to contain %q", cmd, want)
+		}
+	}
+}
+
+func TestCurlCommandDoesNotConsumeTheRequestBody(t *testing.T) {
+	req := NewRequest().SetURLByStr("http://example.com/").SetBodyString("payload")
+
+	if _, err := req.CurlCommand(); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := req.RawRequest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw.Body == nil {
+		t.Fatal("RawRequest().Body is nil after CurlCommand")
+	}
+	got, err := ioutil.ReadAll(raw.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "payload" {
+		t.Errorf("RawRequest().Body = %q, want %q", got, "payload")
+	}
+}
+
+func TestCurlCommandRedactedReplacesAuthorizationByDefault(t *testing.T) {
+	req := NewRequest().SetURLByStr("http://example.com/").SetAuthToken("super-secret")
+
+	cmd, err := req.CurlCommandRedacted()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(cmd, "super-secret") {
+		t.Errorf("CurlCommandRedacted() leaked the token: %q", cmd)
+	}
+	if !strings.Contains(cmd, "Authorization: REDACTED") {
+		t.Errorf("CurlCommandRedacted() = %q, want it to contain redacted Authorization header", cmd)
+	}
+
+	plain, err := req.CurlCommand()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(plain, "super-secret") {
+		t.Errorf("CurlCommand() = %q, want it to still contain the token", plain)
+	}
+}
+
+func TestCurlCommandUsesFormForMultipartParts(t *testing.T) {
+	req := NewRequest().
+		SetURLByStr("http://example.com/upload").
+		SetForm("title", "report").
+		SetFile("file", "data.csv", "text/csv", strings.NewReader("a,b\n1,2\n"))
+
+	cmd, err := req.CurlCommand()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"--form 'title=report'",
+		"--form 'file=@data.csv'",
+	} {
+		if !strings.Contains(cmd, want) {
+			t.Errorf("CurlCommand() = %q, want it to contain %q", cmd, want)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package requests
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// SetDeadlinePropagation sets the header used to tell the server how much
+// time is left on the request's deadline (SetTimeout/SetDeadline), in
+// whole milliseconds, so it can shed work it won't finish in time. The
+// header is set right before each attempt is sent and is skipped entirely
+// when the request has no deadline. Pass "" to disable.
+func (c *Client) SetDeadlinePropagation(header string) *Client {
+	c.deadlineHeader = header
+	return c
+}
+
+// setDeadlineHeader sets c.deadlineHeader on req to the remaining time
+// until req's context deadline, if both a header name is configured and
+// the context has a deadline. It is called once per attempt, so retries
+// see the reduced remainder rather than the original budget.
+func (c *Client) setDeadlineHeader(req *http.Request) {
+	if c.deadlineHeader == "" {
+		return
+	}
+	deadline, ok := req.Context().Deadline()
+	if !ok {
+		return
+	}
+	remaining := time.Until(deadline)
+	if remaining < 0 {
+		remaining = 0
+	}
+	req.Header.Set(c.deadlineHeader, strconv.FormatInt(remaining.Milliseconds(), 10))
+}
@@ -0,0 +1,81 @@
+package requests
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+)
+
+const testDeadlineHeader = "X-Request-Timeout-Ms"
+
+func TestDeadlinePropagationShrinksAcrossRetries(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+
+	var remainings []time.Duration
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		d, ok := mock.RequestDeadlineRemaining(r, testDeadlineHeader)
+		if !ok {
+			t.Error("expected deadline header to be set")
+		}
+		remainings = append(remainings, d)
+		ioutil.ReadAll(r.Body)
+		if r.Header.Get(HeaderExpect) != "" {
+			time.Sleep(10 * time.Millisecond)
+			w.WriteHeader(http.StatusExpectationFailed)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	cli := NewClient().SetDeadlinePropagation(testDeadlineHeader)
+	resp, err := cli.NewRequest().SetURLByStr(mock.URL()).
+		SetTimeout(time.Second).
+		SetExpectContinue().
+		SetBody(bytes.NewReader([]byte("hello"))).
+		Post("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode() != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode())
+	}
+	if len(remainings) != 2 {
+		t.Fatalf("expected 2 attempts, got %d", len(remainings))
+	}
+	if remainings[1] >= remainings[0] {
+		t.Errorf("remaining budget did not shrink across retries: first=%s second=%s", remainings[0], remainings[1])
+	}
+}
+
+func TestDeadlinePropagationAbsentWithoutDeadline(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+
+	var sawHeader bool
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, sawHeader = mock.RequestDeadlineRemaining(r, testDeadlineHeader)
+	})
+
+	cli := NewClient().SetDeadlinePropagation(testDeadlineHeader)
+	if _, err := cli.NewRequest().Get(mock.URL()); err != nil {
+		t.Fatal(err)
+	}
+	if sawHeader {
+		t.Error("expected no deadline header on a request without a deadline")
+	}
+}
@@ -0,0 +1,79 @@
+package requests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// TestSetTimeoutCancelsContextOnceDone sends several thousand quick requests
+// each carrying a SetTimeout(time.Minute) deadline and asserts every one of
+// their contexts is already Done once the request returns. Before
+// Client.do released the context.WithDeadline cancel func it discarded,
+// each of these contexts -- and the runtime timer backing its deadline --
+// would stay alive for the full minute rather than for the few
+// milliseconds the request actually took.
+func TestSetTimeoutCancelsContextOnceDone(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	client := NewClient()
+	const n = 3000
+	for i := 0; i < n; i++ {
+		// do(), unlike the public Get/Post/Do methods, sends req itself
+		// rather than a Clone of it, so req.ctx is the exact context the
+		// request was sent (and, we're asserting, cancelled) with.
+		req := client.NewRequest().SetMethod(MethodGet).SetTimeout(time.Minute).SetURLByStr(mock.URL())
+		if _, err := req.do(); err != nil {
+			t.Fatal(err)
+		}
+		if req.ctx.Err() != context.Canceled {
+			t.Fatalf("request %d: ctx.Err() = %v, want context.Canceled -- its deadline timer is still live", i, req.ctx.Err())
+		}
+	}
+}
+
+// TestCloneOwnsItsOwnCancel ensures a Clone of a request with a deadline
+// gets a cancel scoped to itself: sending the clone must not cancel the
+// context a still-pending sibling or the template depends on.
+func TestCloneOwnsItsOwnCancel(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	template := NewClient().NewRequest().SetMethod(MethodGet).SetTimeout(time.Minute).SetURLByStr(mock.URL())
+	a := template.Clone()
+	b := template.Clone()
+
+	if _, err := a.do(); err != nil {
+		t.Fatal(err)
+	}
+	if a.ctx.Err() != context.Canceled {
+		t.Errorf("a.ctx.Err() = %v, want context.Canceled", a.ctx.Err())
+	}
+	if template.ctx.Err() != nil {
+		t.Errorf("template.ctx.Err() = %v, want nil -- a child clone must not cancel the template", template.ctx.Err())
+	}
+	if b.ctx.Err() != nil {
+		t.Errorf("b.ctx.Err() = %v, want nil -- sending sibling clone a must not cancel clone b", b.ctx.Err())
+	}
+
+	if _, err := b.do(); err != nil {
+		t.Fatal(err)
+	}
+	if b.ctx.Err() != context.Canceled {
+		t.Errorf("b.ctx.Err() = %v, want context.Canceled", b.ctx.Err())
+	}
+}
@@ -0,0 +1,81 @@
+package requests
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"sync"
+	"time"
+)
+
+type debugDumpContextKeyType struct{}
+
+var debugDumpContextKey = debugDumpContextKeyType{}
+
+// debugDump is what Request.RawRequest stashes in the request's context
+// for debugRoundTripper to pick up: the writer Request.SetDebugWriter was
+// given, plus the client-wide mutex guarding it so two requests sharing a
+// writer never interleave their dumps.
+type debugDump struct {
+	w  io.Writer
+	mu *sync.Mutex
+}
+
+// debugRoundTripper wraps an http.RoundTripper, dumping the request and
+// response of every hop that carries a *debugDump in its context to that
+// dump's writer. Wrapping at the RoundTrip boundary rather than hooking
+// the dialer means every hop of a redirect chain is dumped individually,
+// and TLS traffic is dumped already decrypted, since that's the only
+// layer at which the plaintext is available for an https:// request.
+type debugRoundTripper struct {
+	next http.RoundTripper
+}
+
+func (t *debugRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	dd, _ := req.Context().Value(debugDumpContextKey).(*debugDump)
+	if dd == nil {
+		return t.next.RoundTrip(req)
+	}
+
+	if reqDump, err := httputil.DumpRequest(req, true); err == nil {
+		dd.write("-> request", reqDump)
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if respDump, err := httputil.DumpResponse(resp, true); err == nil {
+		dd.write("<- response", respDump)
+	}
+	return resp, nil
+}
+
+// write serializes one direction's dump behind the shared mutex, preceded
+// by a timestamped banner naming the direction, so concurrent requests (or
+// hops racing a shared connection) on the same writer never interleave.
+func (dd *debugDump) write(direction string, data []byte) {
+	dd.mu.Lock()
+	defer dd.mu.Unlock()
+	fmt.Fprintf(dd.w, "=== %s %s ===\n", time.Now().Format(time.RFC3339Nano), direction)
+	dd.w.Write(data)
+	if len(data) == 0 || data[len(data)-1] != '\n' {
+		fmt.Fprintln(dd.w)
+	}
+}
+
+// ensureDebugTransport wraps the client's transport with a
+// debugRoundTripper the first time any request sets a debug writer.
+// Idempotent, since a Client used by many requests should only wrap once.
+func (c *Client) ensureDebugTransport() error {
+	if _, ok := c.cli.Transport.(*debugRoundTripper); ok {
+		return nil
+	}
+	if _, err := c.getTransport(); err != nil {
+		return err
+	}
+	c.cli.Transport = &debugRoundTripper{next: c.cli.Transport}
+	return nil
+}
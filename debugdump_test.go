@@ -0,0 +1,80 @@
+package requests
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestSetDebugWriterDumpsBothDirections(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("pong"))
+	})
+
+	var buf bytes.Buffer
+	_, err = NewClient().NewRequest().SetURLByStr(mock.URL()).SetDebugWriter(&buf).SetBody(strings.NewReader("ping")).Post("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dump := buf.String()
+	if !strings.Contains(dump, "-> request") || !strings.Contains(dump, "ping") {
+		t.Errorf("dump missing request side: %q", dump)
+	}
+	if !strings.Contains(dump, "<- response") || !strings.Contains(dump, "pong") {
+		t.Errorf("dump missing response side: %q", dump)
+	}
+}
+
+func TestSetDebugWriterCoversRedirectHops(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/start", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/end", http.StatusFound)
+	})
+	mock.HandleFunc("/end", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("done"))
+	})
+
+	var buf bytes.Buffer
+	_, err = NewClient().NewRequest().SetURLByStr(mock.URL()).SetDebugWriter(&buf).Get("/start")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dump := buf.String()
+	if strings.Count(dump, "-> request") != 2 {
+		t.Errorf("expected a request dump for each of the two hops, got: %q", dump)
+	}
+	if !strings.Contains(dump, "/start") || !strings.Contains(dump, "/end") {
+		t.Errorf("dump missing one of the hops: %q", dump)
+	}
+}
+
+func TestSetDebugWriterNoopWithoutOne(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {})
+
+	if _, err := NewRequest().SetURLByStr(mock.URL()).Get("/"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,205 @@
+package requests
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"mime"
+	"net/url"
+	"reflect"
+	"strconv"
+	"sync"
+
+	"gopkg.in/yaml.v2"
+)
+
+// BodyDecoder decodes data into v, same contract as json.Unmarshal.
+type BodyDecoder func(data []byte, v interface{}) error
+
+var decoderRegistry = struct {
+	mu       sync.RWMutex
+	decoders map[string]BodyDecoder
+}{decoders: map[string]BodyDecoder{}}
+
+func init() {
+	RegisterBodyDecoder("application/json", json.Unmarshal)
+	RegisterBodyDecoder("application/xml", xml.Unmarshal)
+	RegisterBodyDecoder("text/xml", xml.Unmarshal)
+	RegisterBodyDecoder("application/yaml", yaml.Unmarshal)
+	RegisterBodyDecoder("application/x-yaml", yaml.Unmarshal)
+	RegisterBodyDecoder("text/yaml", yaml.Unmarshal)
+	RegisterBodyDecoder("application/x-www-form-urlencoded", decodeURLValues)
+	RegisterBodyDecoder("text/plain", decodeTextPlain)
+}
+
+// RegisterBodyDecoder registers fn as the decoder Response.Decode
+// dispatches to for mediaType. A codec subpackage that wants to stay out
+// of the core module's dependencies, e.g. requests/msgpack, calls this
+// from its own init(), so just importing it for that side effect is
+// enough to make Decode recognize its media type. Registering a
+// mediaType a second time replaces the previous decoder.
+func RegisterBodyDecoder(mediaType string, fn BodyDecoder) {
+	decoderRegistry.mu.Lock()
+	defer decoderRegistry.mu.Unlock()
+	decoderRegistry.decoders[mediaType] = fn
+}
+
+func lookupBodyDecoder(mediaType string) (BodyDecoder, bool) {
+	decoderRegistry.mu.RLock()
+	defer decoderRegistry.mu.RUnlock()
+	fn, ok := decoderRegistry.decoders[mediaType]
+	return fn, ok
+}
+
+// ErrUnsupportedContentType is returned by Response.Decode when no
+// decoder is registered for the response's Content-Type, so callers can
+// type-assert it (as opposed to an opaque error) and fall back, e.g. to
+// reading Body() raw themselves.
+type ErrUnsupportedContentType struct {
+	MediaType string
+}
+
+func (e *ErrUnsupportedContentType) Error() string {
+	return fmt.Sprintf("requests: Decode: no registered decoder for content type %q", e.MediaType)
+}
+
+// Decode decodes the response body into v using the decoder registered,
+// via RegisterBodyDecoder, for this response's Content-Type media type.
+// JSON, XML, YAML, application/x-www-form-urlencoded and text/plain are
+// registered out of the box; import a codec subpackage for its init()
+// side effect (e.g. requests/msgpack, requests/pb) to extend Decode to
+// other formats. An unregistered media type fails with
+// *ErrUnsupportedContentType.
+func (r *Response) Decode(v interface{}) error {
+	if r.streamBody != nil {
+		return ErrBodyNotBuffered
+	}
+	mediatype, _, err := mime.ParseMediaType(r.ContentType())
+	if err != nil {
+		mediatype = r.ContentType()
+	}
+	fn, ok := lookupBodyDecoder(mediatype)
+	if !ok {
+		return &ErrUnsupportedContentType{MediaType: mediatype}
+	}
+	if err := fn(r.body, v); err != nil {
+		return fmt.Errorf("requests: Decode: decoding %q body: %w", mediatype, err)
+	}
+	return nil
+}
+
+// decodeURLValues is the built-in decoder for
+// application/x-www-form-urlencoded bodies. v may be a *url.Values, a
+// *map[string][]string, a *map[string]string (taking the first value per
+// key), or a pointer to a struct using the same `form:"name"` tag
+// SetFormStruct uses for encoding.
+func decodeURLValues(data []byte, v interface{}) error {
+	values, err := url.ParseQuery(string(data))
+	if err != nil {
+		return err
+	}
+	switch dst := v.(type) {
+	case *url.Values:
+		*dst = values
+		return nil
+	case *map[string][]string:
+		*dst = map[string][]string(values)
+		return nil
+	case *map[string]string:
+		m := make(map[string]string, len(values))
+		for k, vs := range values {
+			if len(vs) > 0 {
+				m[k] = vs[0]
+			}
+		}
+		*dst = m
+		return nil
+	}
+	return decodeFormStruct(reflect.ValueOf(v), values)
+}
+
+func decodeFormStruct(v reflect.Value, values url.Values) error {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return fmt.Errorf("requests: Decode: nil destination")
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("requests: Decode: application/x-www-form-urlencoded requires a *url.Values, *map[string]string, *map[string][]string or struct pointer, got %T", v.Interface())
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" { // unexported
+			continue
+		}
+		name, _, skip := formFieldNameAndOptions(field)
+		if skip {
+			continue
+		}
+		vals, ok := values[name]
+		if !ok || len(vals) == 0 {
+			continue
+		}
+		if err := decodeFormField(v.Field(i), vals[0]); err != nil {
+			return fmt.Errorf("requests: Decode: field %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func decodeFormField(fv reflect.Value, s string) error {
+	for fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			fv.Set(reflect.New(fv.Type().Elem()))
+		}
+		fv = fv.Elem()
+	}
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported field type %s", fv.Type())
+	}
+	return nil
+}
+
+// decodeTextPlain is the built-in decoder for text/plain bodies. v must
+// be a *string or *[]byte.
+func decodeTextPlain(data []byte, v interface{}) error {
+	switch dst := v.(type) {
+	case *string:
+		*dst = string(data)
+		return nil
+	case *[]byte:
+		*dst = append([]byte(nil), data...)
+		return nil
+	}
+	return fmt.Errorf("requests: Decode: text/plain requires a *string or *[]byte, got %T", v)
+}
@@ -0,0 +1,185 @@
+package requests
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestResponseDecodeDispatchesOnContentType(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	mock.HandleFunc("/json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderContentType, MimeJSON)
+		w.Write([]byte(`{"name":"gopher"}`))
+	})
+	mock.HandleFunc("/yaml", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderContentType, MimeYAML)
+		w.Write([]byte("name: gopher\n"))
+	})
+
+	var v struct {
+		Name string `json:"name" yaml:"name"`
+	}
+	resp, err := NewRequest().SetURLByStr(mock.URL()).Get("/json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := resp.Decode(&v); err != nil {
+		t.Fatal(err)
+	}
+	if v.Name != "gopher" {
+		t.Errorf("Name = %q, want gopher", v.Name)
+	}
+
+	v.Name = ""
+	resp, err = NewRequest().SetURLByStr(mock.URL()).Get("/yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := resp.Decode(&v); err != nil {
+		t.Fatal(err)
+	}
+	if v.Name != "gopher" {
+		t.Errorf("Name = %q, want gopher", v.Name)
+	}
+}
+
+func TestResponseDecodeUnknownContentTypeErrors(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderContentType, "application/x-no-such-format")
+		w.Write([]byte("whatever"))
+	})
+
+	resp, err := NewRequest().SetURLByStr(mock.URL()).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var v struct{}
+	err = resp.Decode(&v)
+	if err == nil {
+		t.Fatal("want an error for an unregistered content type")
+	}
+	unsupported, ok := err.(*ErrUnsupportedContentType)
+	if !ok {
+		t.Fatalf("err = %T, want *ErrUnsupportedContentType", err)
+	}
+	if unsupported.MediaType != "application/x-no-such-format" {
+		t.Errorf("MediaType = %q, want application/x-no-such-format", unsupported.MediaType)
+	}
+}
+
+func TestResponseDecodeFormURLEncoded(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderContentType, MimeURLEncoded)
+		w.Write([]byte("name=gopher&age=10"))
+	})
+
+	resp, err := NewRequest().SetURLByStr(mock.URL()).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var values url.Values
+	if err := resp.Decode(&values); err != nil {
+		t.Fatal(err)
+	}
+	if values.Get("name") != "gopher" {
+		t.Errorf("values[name] = %q, want gopher", values.Get("name"))
+	}
+
+	var m map[string]string
+	if err := resp.Decode(&m); err != nil {
+		t.Fatal(err)
+	}
+	if m["name"] != "gopher" {
+		t.Errorf("m[name] = %q, want gopher", m["name"])
+	}
+
+	var out struct {
+		Name string `form:"name"`
+		Age  int    `form:"age"`
+	}
+	if err := resp.Decode(&out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Name != "gopher" || out.Age != 10 {
+		t.Errorf("out = %+v, want {gopher 10}", out)
+	}
+}
+
+func TestResponseDecodeTextPlain(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderContentType, MimeTextPlain)
+		w.Write([]byte("hello"))
+	})
+
+	resp, err := NewRequest().SetURLByStr(mock.URL()).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var s string
+	if err := resp.Decode(&s); err != nil {
+		t.Fatal(err)
+	}
+	if s != "hello" {
+		t.Errorf("s = %q, want hello", s)
+	}
+
+	var b []byte
+	if err := resp.Decode(&b); err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "hello" {
+		t.Errorf("b = %q, want hello", b)
+	}
+}
+
+func TestRegisterBodyDecoderExtendsDecode(t *testing.T) {
+	RegisterBodyDecoder("application/x-requests-test-decoder", func(data []byte, v interface{}) error {
+		*(v.(*string)) = string(data) + "-decoded"
+		return nil
+	})
+
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderContentType, "application/x-requests-test-decoder")
+		w.Write([]byte("raw"))
+	})
+
+	resp, err := NewRequest().SetURLByStr(mock.URL()).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s string
+	if err := resp.Decode(&s); err != nil {
+		t.Fatal(err)
+	}
+	if s != "raw-decoded" {
+		t.Errorf("s = %q, want raw-decoded", s)
+	}
+}
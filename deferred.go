@@ -0,0 +1,259 @@
+package requests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DeferredItem is the durable, serialized form of a Request that failed in
+// a way Client.SetDeferredQueue's predicate recognized. It captures the
+// already-built *http.Request's method, URL, headers and body, rather than
+// the Request builder itself, since the builder's unexported paramPairs,
+// transformer and similar fields aren't serializable.
+type DeferredItem struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   []byte      `json:"body,omitempty"`
+}
+
+// DeferredQueue is a durable, ordered queue of DeferredItems, appended to by
+// Client.do when a request fails in a way SetDeferredQueue's predicate
+// matches, and drained in order by Client.ReplayDeferred.
+type DeferredQueue interface {
+	// Enqueue appends item to the end of the queue.
+	Enqueue(item *DeferredItem) error
+	// Peek returns the oldest item still in the queue without removing it,
+	// or ErrNotExist if the queue is empty.
+	Peek() (*DeferredItem, error)
+	// Ack removes the item last returned by Peek.
+	Ack() error
+	// Len reports the number of items currently queued.
+	Len() (int, error)
+}
+
+// Decision tells ReplayDeferred what to do with the item it just replayed.
+type Decision int
+
+const (
+	// DecisionDrop removes the item and continues with the next one: it
+	// replayed successfully, or the caller has otherwise decided it isn't
+	// worth keeping around.
+	DecisionDrop Decision = iota
+	// DecisionKeep leaves the item at the front of the queue and stops
+	// ReplayDeferred, e.g. because the failure still looks like "offline".
+	// The next ReplayDeferred call, this run or a later one, starts over
+	// from the same item.
+	DecisionKeep
+	// DecisionRetry resends the same item immediately, without consulting
+	// the queue again, before onResult is asked what to do next.
+	DecisionRetry
+)
+
+// SetDeferredQueue configures q as a durable store for requests that fail
+// in a way shouldDefer recognizes, e.g. no network, so they can be sent
+// later with ReplayDeferred instead of being lost. shouldDefer receives the
+// Request that failed and the error do() is about to return for it; items
+// are appended to q in the order their requests failed.
+func (c *Client) SetDeferredQueue(q DeferredQueue, shouldDefer func(*Request, error) bool) *Client {
+	c.deferredQueue = q
+	c.shouldDefer = shouldDefer
+	c.invalidateConfigSnapshot()
+	return c
+}
+
+// deferRequest enqueues req onto the client's deferred queue, capturing its
+// method, URL, headers and full body via readAndRestoreBody -- unlike
+// Request.Message's messageBody, the deferred copy needs the whole body
+// to replay later, not just a bounded preview to log.
+func (c *Client) deferRequest(req *Request) error {
+	body, err := readAndRestoreBody(req.rawRequest)
+	if err != nil {
+		return err
+	}
+	return c.deferredQueue.Enqueue(&DeferredItem{
+		Method: req.rawRequest.Method,
+		URL:    req.rawRequest.URL.String(),
+		Header: req.rawRequest.Header,
+		Body:   body,
+	})
+}
+
+// ReplayDeferred drains the client's deferred queue, configured with
+// SetDeferredQueue, in the order items were enqueued: each is rebuilt into
+// a Request and sent, and onResult is called with that request, its
+// response, and its error, if any. onResult's Decision says what happens
+// next: DecisionDrop removes the item and moves on, DecisionKeep leaves it
+// in place and returns, DecisionRetry resends the same item immediately.
+// An item is only removed once onResult returns DecisionDrop for it, so
+// replay is resumable after a crash: the worst a crash mid-replay does is
+// replay the same item again. ReplayDeferred also stops and returns
+// ctx.Err() if ctx is cancelled between items.
+func (c *Client) ReplayDeferred(ctx context.Context, onResult func(req *Request, resp *Response, err error) Decision) error {
+	if c.deferredQueue == nil {
+		return nil
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		item, err := c.deferredQueue.Peek()
+		if err == ErrNotExist {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		for {
+			req := c.requestFromDeferred(item)
+			resp, sendErr := req.Do()
+			switch onResult(req, resp, sendErr) {
+			case DecisionRetry:
+				continue
+			case DecisionKeep:
+				return nil
+			default: // DecisionDrop
+				if err := c.deferredQueue.Ack(); err != nil {
+					return err
+				}
+			}
+			break
+		}
+	}
+}
+
+// requestFromDeferred rebuilds a Request from a DeferredItem the same way a
+// caller would by hand: method, URL, header and body set directly, since
+// none of the original builder's param slices survive serialization.
+func (c *Client) requestFromDeferred(item *DeferredItem) *Request {
+	req := c.NewRequest().SetMethod(item.Method).SetURLByStr(item.URL).SetHeaderValues(item.Header)
+	if len(item.Body) != 0 {
+		req.SetBodyBytes(item.Body)
+	}
+	req.deferredReplay = true
+	return req
+}
+
+// FileDeferredQueue returns a DeferredQueue backed by one file per queued
+// item under dir, named by a monotonically increasing sequence number so
+// ordering survives a crash: Peek always reads the lowest-numbered file
+// present, and Ack removes only that file, so a crash between Peek and Ack
+// just means the same item is returned again on the next Peek.
+func FileDeferredQueue(dir string) *fileDeferredQueue {
+	return &fileDeferredQueue{dir: dir}
+}
+
+const deferredFileExt = ".json"
+
+type fileDeferredQueue struct {
+	dir string
+	mu  sync.Mutex
+}
+
+func (q *fileDeferredQueue) Enqueue(item *DeferredItem) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err := os.MkdirAll(q.dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+	seqs, err := q.sequences()
+	if err != nil {
+		return err
+	}
+	var next int64 = 1
+	if len(seqs) != 0 {
+		next = seqs[len(seqs)-1] + 1
+	}
+	return ioutil.WriteFile(filepath.Join(q.dir, deferredFileName(next)), data, 0644)
+}
+
+func (q *fileDeferredQueue) Peek() (*DeferredItem, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	seqs, err := q.sequences()
+	if err != nil {
+		return nil, err
+	}
+	if len(seqs) == 0 {
+		return nil, ErrNotExist
+	}
+	data, err := ioutil.ReadFile(filepath.Join(q.dir, deferredFileName(seqs[0])))
+	if err != nil {
+		return nil, err
+	}
+	item := &DeferredItem{}
+	if err := json.Unmarshal(data, item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (q *fileDeferredQueue) Ack() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	seqs, err := q.sequences()
+	if err != nil {
+		return err
+	}
+	if len(seqs) == 0 {
+		return ErrNotExist
+	}
+	return os.Remove(filepath.Join(q.dir, deferredFileName(seqs[0])))
+}
+
+func (q *fileDeferredQueue) Len() (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	seqs, err := q.sequences()
+	if err != nil {
+		return 0, err
+	}
+	return len(seqs), nil
+}
+
+// sequences returns the sequence numbers of every item file in q.dir, in
+// ascending (queue) order.
+func (q *fileDeferredQueue) sequences() ([]int64, error) {
+	entries, err := ioutil.ReadDir(q.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	seqs := make([]int64, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		n, err := strconv.ParseInt(strings.TrimSuffix(e.Name(), deferredFileExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		seqs = append(seqs, n)
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+	return seqs, nil
+}
+
+func deferredFileName(seq int64) string {
+	return fmt.Sprintf("%020d%s", seq, deferredFileExt)
+}
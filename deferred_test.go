@@ -0,0 +1,195 @@
+package requests
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func alwaysDefer(r *Request, err error) bool { return err != nil }
+
+func TestFileDeferredQueueEnqueuePeekAckOrder(t *testing.T) {
+	q := FileDeferredQueue(filepath.Join(t.TempDir(), "queue"))
+
+	for _, body := range []string{"a", "b", "c"} {
+		if err := q.Enqueue(&DeferredItem{Method: MethodPost, URL: "https://example.com", Body: []byte(body)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n, err := q.Len(); err != nil || n != 3 {
+		t.Fatalf("Len() = %d, %v, want 3, nil", n, err)
+	}
+
+	for _, want := range []string{"a", "b", "c"} {
+		item, err := q.Peek()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(item.Body) != want {
+			t.Errorf("Peek().Body = %q, want %q", item.Body, want)
+		}
+		// Peeking again without acking returns the same item, for
+		// resumability after a crash between Peek and Ack.
+		if item, err := q.Peek(); err != nil || string(item.Body) != want {
+			t.Errorf("repeated Peek() = %q, %v, want %q, nil", item.Body, err, want)
+		}
+		if err := q.Ack(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := q.Peek(); err != ErrNotExist {
+		t.Errorf("Peek() on empty queue = %v, want ErrNotExist", err)
+	}
+}
+
+func TestReplayDeferredDrainsInOrder(t *testing.T) {
+	down, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	down.HandleFunc("/items", func(w http.ResponseWriter, r *http.Request) {})
+	downURL := down.URL()
+	down.Close() // simulate offline: nothing is listening at downURL anymore
+
+	cli := NewClient().SetDeferredQueue(FileDeferredQueue(filepath.Join(t.TempDir(), "queue")), alwaysDefer)
+
+	for _, n := range []string{"1", "2", "3"} {
+		_, err := cli.NewRequest().SetURLByStr(downURL + "/items?n=" + n).Post("")
+		if err == nil {
+			t.Fatal("Post() against a dead server returned nil error")
+		}
+	}
+	if n, _ := cli.deferredQueue.Len(); n != 3 {
+		t.Fatalf("deferred queue length = %d, want 3", n)
+	}
+
+	// The server is still dead, so every replay fails too; what this
+	// checks is that ReplayDeferred still rebuilds and replays each item
+	// in the order it was enqueued, and still drains the queue as
+	// onResult decides.
+	var replayedOrder []string
+	err = cli.ReplayDeferred(context.Background(), func(req *Request, resp *Response, sendErr error) Decision {
+		if sendErr == nil {
+			t.Error("replay against a still-dead server returned nil error")
+		}
+		replayedOrder = append(replayedOrder, req.GetURL("").Query().Get("n"))
+		return DecisionDrop
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Join(replayedOrder, ","), "1,2,3"; got != want {
+		t.Errorf("replay order = %q, want %q", got, want)
+	}
+	if n, _ := cli.deferredQueue.Len(); n != 0 {
+		t.Fatalf("deferred queue length after full drain = %d, want 0", n)
+	}
+}
+
+func TestReplayDeferredKeepStopsAtFailingItem(t *testing.T) {
+	down, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	down.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {})
+	downURL := down.URL()
+	down.Close()
+
+	cli := NewClient().SetDeferredQueue(FileDeferredQueue(filepath.Join(t.TempDir(), "queue")), alwaysDefer)
+	for i := 0; i < 2; i++ {
+		cli.NewRequest().SetURLByStr(downURL).Post("")
+	}
+
+	calls := 0
+	err = cli.ReplayDeferred(context.Background(), func(req *Request, resp *Response, sendErr error) Decision {
+		calls++
+		return DecisionKeep
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Errorf("onResult called %d times, want 1 (stop at the first failing item)", calls)
+	}
+	if n, _ := cli.deferredQueue.Len(); n != 2 {
+		t.Errorf("deferred queue length after DecisionKeep = %d, want 2 (nothing acked)", n)
+	}
+}
+
+// TestReplayDeferredSucceedsAfterServerComesBackOnline simulates the
+// offline-then-back-online cycle end to end: three POSTs fail against a
+// dead server and land in the deferred queue, then a server listening on
+// that exact same address comes up and ReplayDeferred successfully
+// resends all three, in order, dropping each as it succeeds.
+func TestReplayDeferredSucceedsAfterServerComesBackOnline(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	down := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	downLn, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Skipf("could not rebind %s for the dead server: %v", addr, err)
+	}
+	down.Listener = downLn
+	down.Start()
+	downURL := down.URL
+	down.Close() // simulate offline: nothing is listening at addr anymore
+
+	cli := NewClient().SetDeferredQueue(FileDeferredQueue(filepath.Join(t.TempDir(), "queue")), alwaysDefer)
+	for _, n := range []string{"1", "2", "3"} {
+		_, err := cli.NewRequest().SetURLByStr(downURL + "/?n=" + n).Post("")
+		if err == nil {
+			t.Fatal("Post() against a dead server returned nil error")
+		}
+	}
+	if n, _ := cli.deferredQueue.Len(); n != 3 {
+		t.Fatalf("deferred queue length = %d, want 3", n)
+	}
+
+	var received []string
+	live := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.URL.Query().Get("n"))
+	}))
+	liveLn, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Skipf("could not rebind %s for the revived server: %v", addr, err)
+	}
+	live.Listener = liveLn
+	live.Start()
+	defer live.Close()
+
+	err = cli.ReplayDeferred(context.Background(), func(req *Request, resp *Response, sendErr error) Decision {
+		if sendErr != nil {
+			t.Errorf("replay against the revived server failed: %v", sendErr)
+		}
+		return DecisionDrop
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Join(received, ","), "1,2,3"; got != want {
+		t.Errorf("server received n=%q in order, want %q", got, want)
+	}
+	if n, _ := cli.deferredQueue.Len(); n != 0 {
+		t.Fatalf("deferred queue length after full drain = %d, want 0", n)
+	}
+}
+
+func TestReplayDeferredWithoutQueueIsNoop(t *testing.T) {
+	cli := NewClient()
+	if err := cli.ReplayDeferred(context.Background(), func(*Request, *Response, error) Decision {
+		t.Fatal("onResult called with no deferred queue configured")
+		return DecisionDrop
+	}); err != nil {
+		t.Fatalf("ReplayDeferred() = %v, want nil", err)
+	}
+}
@@ -0,0 +1,112 @@
+package requests
+
+import (
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func TestSetDoNotParseResponseStreamsBody(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("streamed content"))
+	})
+
+	client := NewClient()
+	resp, err := client.NewRequest().SetURLByStr(mock.URL()).SetDoNotParseResponse(true).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Close()
+
+	if resp.Body() != nil {
+		t.Errorf("Body() = %q, want nil for a streamed response", resp.Body())
+	}
+
+	stream := resp.BodyStream()
+	if stream == nil {
+		t.Fatal("BodyStream() = nil, want the live body reader")
+	}
+	got, err := ioutil.ReadAll(stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "streamed content" {
+		t.Errorf("BodyStream content = %q, want %q", got, "streamed content")
+	}
+}
+
+func TestSetDoNotParseResponseRejectsBufferedAccessors(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderContentType, "application/json")
+		w.Write([]byte(`{"a":1}`))
+	})
+
+	client := NewClient()
+	resp, err := client.NewRequest().SetURLByStr(mock.URL()).SetDoNotParseResponse(true).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Close()
+
+	var v map[string]int
+	if err := resp.JSON(&v); err != ErrBodyNotBuffered {
+		t.Errorf("JSON() err = %v, want ErrBodyNotBuffered", err)
+	}
+	if err := resp.Decode(&v); err != ErrBodyNotBuffered {
+		t.Errorf("Decode() err = %v, want ErrBodyNotBuffered", err)
+	}
+	if err := resp.WriteFile(t.TempDir() + "/out"); err != ErrBodyNotBuffered {
+		t.Errorf("WriteFile() err = %v, want ErrBodyNotBuffered", err)
+	}
+	if msg := resp.Message(); msg != ErrBodyNotBuffered.Error() {
+		t.Errorf("Message() = %q, want %q", msg, ErrBodyNotBuffered.Error())
+	}
+
+	ioutil.ReadAll(resp.BodyStream())
+}
+
+func TestSetDoNotParseResponseReusesConnectionAfterClose(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("body"))
+	})
+
+	client := NewClient().SetConnectionLabeler(func(req *http.Request) string {
+		return "conn"
+	})
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.NewRequest().SetURLByStr(mock.URL()).SetDoNotParseResponse(true).Get("/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ioutil.ReadAll(resp.BodyStream()); err != nil {
+			t.Fatal(err)
+		}
+		if err := resp.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	conns := client.OpenConnections()
+	if len(conns) != 1 {
+		t.Fatalf("expected 1 reused connection, got %d", len(conns))
+	}
+	if conns[0].Requests != 2 {
+		t.Errorf("Requests = %d, want 2 (same connection reused)", conns[0].Requests)
+	}
+}
@@ -22,7 +22,14 @@ func MarshalResponse(resp *http.Response) ([]byte, error) {
 	return httputil.DumpResponse(resp, true)
 }
 
-// UnmarshalResponse reads and returns an HTTP response from data.
-func UnmarshalResponse(data []byte) (resp *http.Response, err error) {
-	return http.ReadResponse(bufio.NewReader(bytes.NewBuffer(data)), nil)
+// UnmarshalResponse reads and returns an HTTP response from data. method is
+// the method of the request that produced the response (e.g. "HEAD"), so
+// http.ReadResponse can interpret the body framing correctly for bodiless
+// responses; pass "" if the method is unknown.
+func UnmarshalResponse(data []byte, method string) (resp *http.Response, err error) {
+	var req *http.Request
+	if method != "" {
+		req = &http.Request{Method: method}
+	}
+	return http.ReadResponse(bufio.NewReader(bytes.NewBuffer(data)), req)
 }
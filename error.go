@@ -1,8 +1,171 @@
 package requests
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
 
 var (
 	ErrNoRedirect   = errors.New("No Redirect")
 	ErrNotTransport = errors.New("not a *http.Transport")
+
+	// ErrUndecodedBodyDiscarded is returned by Response.DecodeBodyAs when
+	// the client that produced the response wasn't set up with
+	// Client.SetKeepUndecodedBody(true), so the raw body needed to retry
+	// charset decoding was never kept around.
+	ErrUndecodedBodyDiscarded = errors.New("requests: raw undecoded body was discarded, call Client.SetKeepUndecodedBody(true) before sending the request")
+
+	// ErrBodyNotBuffered is returned by methods that need the fully read
+	// response body (JSON, XML, YAML, Decode, WriteFile, Message) when
+	// Request.SetDoNotParseResponse(true) was set. Use RawBody/BodyStream
+	// to read the live body instead, and Response.Close to release the
+	// connection once done.
+	ErrBodyNotBuffered = errors.New("requests: body not buffered, Request.SetDoNotParseResponse(true) was set; use RawBody()/BodyStream() and Close() instead")
 )
+
+// ErrUnexpectedContentType is returned by Do() when Request.ExpectContentType
+// was set and the response's media type isn't among the allowed types.
+type ErrUnexpectedContentType struct {
+	ContentType string
+	Allowed     []string
+	Response    *Response
+}
+
+func (e *ErrUnexpectedContentType) Error() string {
+	return fmt.Sprintf("requests: unexpected content type %q, expected one of %v", e.ContentType, e.Allowed)
+}
+
+// matchMediaType reports whether mediatype matches one of patterns, which
+// may use "*" for the type or subtype, e.g. "application/*".
+func matchMediaType(mediatype string, patterns []string) bool {
+	typ, sub := splitMediaType(mediatype)
+	for _, p := range patterns {
+		ptyp, psub := splitMediaType(p)
+		if (ptyp == "*" || ptyp == typ) && (psub == "*" || psub == sub) {
+			return true
+		}
+	}
+	return false
+}
+
+func splitMediaType(mediatype string) (string, string) {
+	i := strings.IndexByte(mediatype, '/')
+	if i < 0 {
+		return mediatype, ""
+	}
+	return mediatype[:i], mediatype[i+1:]
+}
+
+// ErrUnexpectedStatus is returned by Response.ExpectStatus when the
+// response's status code isn't among Allowed. Response is the real
+// response, so StatusCode() and Body() remain available for callers that
+// want to branch programmatically instead of matching on Error() text.
+type ErrUnexpectedStatus struct {
+	Response *Response
+	Allowed  []int
+}
+
+func (e *ErrUnexpectedStatus) StatusCode() int {
+	return e.Response.StatusCode()
+}
+
+func (e *ErrUnexpectedStatus) Body() []byte {
+	return e.Response.Body()
+}
+
+func (e *ErrUnexpectedStatus) Error() string {
+	return fmt.Sprintf("requests: expected status %s, got %d: %s",
+		joinInts(e.Allowed, "/"), e.StatusCode(), jsonBodySnippet(e.Body()))
+}
+
+func joinInts(ints []int, sep string) string {
+	parts := make([]string, len(ints))
+	for i, n := range ints {
+		parts[i] = strconv.Itoa(n)
+	}
+	return strings.Join(parts, sep)
+}
+
+// StatusError is returned by Do() when Client.SetErrorOnStatus (or
+// Request.SetErrorOnStatus) is enabled and the response status is 4xx or
+// 5xx. It copies the fields a caller usually needs to diagnose or log
+// the failure, so they don't have to hold onto the *Response just for
+// that -- though Do() still returns it alongside StatusError too.
+type StatusError struct {
+	StatusCode int
+	Status     string
+	Header     http.Header
+	Body       []byte
+	Method     string
+	URL        string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("requests: %s %s: %s: %s", e.Method, e.URL, e.Status, jsonBodySnippet(e.Body))
+}
+
+// NewStatusError builds a *StatusError from resp. It's exported so a
+// custom Request.SetRetryCondition can build one to inspect, without
+// needing Client.SetErrorOnStatus enabled.
+func NewStatusError(resp *Response) *StatusError {
+	return &StatusError{
+		StatusCode: resp.StatusCode(),
+		Status:     resp.Status(),
+		Header:     resp.Header(),
+		Body:       resp.Body(),
+		Method:     resp.method,
+		URL:        fmt.Sprint(resp.Location()),
+	}
+}
+
+// ErrDecodeResult is returned by Do() when Request.SetResult or
+// SetError failed to decode the response body into the given target.
+// Response is the real, fully populated response, so callers can still
+// read StatusCode(), Body(), headers and so on off it instead of losing
+// that information behind an opaque decode error.
+type ErrDecodeResult struct {
+	Response *Response
+	Err      error
+}
+
+func (e *ErrDecodeResult) Error() string {
+	return fmt.Sprintf("requests: decoding status %d response body into SetResult/SetError target: %v", e.Response.StatusCode(), e.Err)
+}
+
+func (e *ErrDecodeResult) Unwrap() error {
+	return e.Err
+}
+
+// ErrResponseTooLarge is returned by Do() when Client.SetMaxResponseSize
+// (or Request.SetMaxResponseSize) is set and the response body exceeds
+// it. ContentLength is the server-declared size, or -1 if it didn't send
+// one; BytesRead is how much was actually read before giving up. The
+// partial body read so far is still available through Response.Body/
+// RawBody for diagnostics.
+type ErrResponseTooLarge struct {
+	Limit         int64
+	BytesRead     int64
+	ContentLength int64
+}
+
+func (e *ErrResponseTooLarge) Error() string {
+	return fmt.Sprintf("requests: response body exceeds limit of %d bytes (read %d, Content-Length %d)",
+		e.Limit, e.BytesRead, e.ContentLength)
+}
+
+// ErrHeaderLimitExceeded is returned by fill() when a request's headers
+// exceed Client.SetMaxRequestHeaderBytes or SetMaxHeaderCount, naming the
+// largest header values so the caller can see what to trim.
+type ErrHeaderLimitExceeded struct {
+	TotalBytes int
+	Count      int
+	Largest    []string
+}
+
+func (e *ErrHeaderLimitExceeded) Error() string {
+	return fmt.Sprintf("requests: request headers too large (%d bytes, %d values), largest: %s",
+		e.TotalBytes, e.Count, strings.Join(e.Largest, ", "))
+}
@@ -0,0 +1,119 @@
+package requests
+
+import (
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// EventKind identifies what a Client did when it emitted an Event.
+type EventKind int
+
+const (
+	// RequestStarted fires once per Client.do call, right after the
+	// request's URL, headers and body are filled in and before any cache
+	// lookup or network I/O.
+	RequestStarted EventKind = iota
+	// RequestRetried fires each time do() re-sends the same logical
+	// request: a retry allowed by Request.SetRetry and its retry
+	// condition, or a 417 Expectation Failed retried without the Expect
+	// header. Attempt is the attempt number about to be sent.
+	RequestRetried
+	// ResponseReceived fires once a response comes back from the network
+	// (or a matching stub), terminating the request.
+	ResponseReceived
+	// CacheHit fires when a cached response satisfies the request instead
+	// of a network round trip, terminating the request.
+	CacheHit
+	// CacheStored fires after a fresh response is written to the cache,
+	// just before the ResponseReceived it accompanies.
+	CacheStored
+	// RedirectFollowed fires for each redirect hop the client decides to
+	// follow, reporting the method and URL of the hop about to be sent.
+	RedirectFollowed
+	// ErrorOccurred fires when do() is about to return an error,
+	// terminating the request.
+	ErrorOccurred
+)
+
+// Event is one lifecycle occurrence emitted synchronously by Client.do to
+// the sink installed with SetEventSink. Only the fields relevant to Kind
+// are populated; see each EventKind's doc comment.
+//
+// Ordering guarantees, per RequestID (one logical Client.do call,
+// including every redirect and retry it triggers):
+//   - Exactly one RequestStarted is emitted first.
+//   - Zero or more RedirectFollowed and RequestRetried events may follow,
+//     in the order they actually happened.
+//   - Exactly one terminal event -- ResponseReceived, CacheHit, or
+//     ErrorOccurred -- is always emitted last, paired with the
+//     RequestStarted that opened this RequestID.
+//   - CacheStored, when it happens, is emitted right before the
+//     ResponseReceived it accompanies, never after.
+type Event struct {
+	Kind      EventKind
+	Time      time.Time
+	RequestID string
+
+	Method string
+	URL    string
+
+	// Attempt is set on RequestRetried: the attempt number about to be
+	// sent (2 for the first retry).
+	Attempt int
+
+	// StatusCode is set on ResponseReceived and CacheHit.
+	StatusCode int
+
+	// CacheKey is set on CacheHit and CacheStored.
+	CacheKey string
+
+	// Err is set on ErrorOccurred.
+	Err error
+}
+
+// SetEventSink registers fn to be called synchronously, from within
+// Client.do, for every lifecycle event of every request this client
+// sends -- see Event for the event kinds and their ordering guarantees.
+// A nil fn (the default) disables the sink.
+func (c *Client) SetEventSink(fn func(Event)) *Client {
+	c.eventSink = fn
+	return c
+}
+
+// nextEventRequestID returns a new ID unique within this client's
+// lifetime, shared by every event belonging to one Client.do call.
+func (c *Client) nextEventRequestID() string {
+	return fmt.Sprintf("%d", atomic.AddInt64(&c.eventSeq, 1))
+}
+
+func (c *Client) emitEvent(ev Event) {
+	if c.eventSink == nil {
+		return
+	}
+	ev.Time = time.Now()
+	c.eventSink(ev)
+}
+
+// eventRequestIDContextKeyType is the context key used to carry an
+// in-flight request's event RequestID onto the *http.Request CheckRedirect
+// sees, since it's net/http, not Client.do, that constructs each redirect
+// hop's request.
+type eventRequestIDContextKeyType struct{}
+
+var eventRequestIDContextKey = eventRequestIDContextKeyType{}
+
+// emitRedirectFollowed is called from checkRedirect once it's decided to
+// allow the redirect to req. It's a no-op unless the original request was
+// tagged with an event RequestID, which requires a sink to be installed.
+func (c *Client) emitRedirectFollowed(req *http.Request) {
+	if c.eventSink == nil {
+		return
+	}
+	id, ok := req.Context().Value(eventRequestIDContextKey).(string)
+	if !ok {
+		return
+	}
+	c.emitEvent(Event{Kind: RedirectFollowed, RequestID: id, Method: req.Method, URL: req.URL.String()})
+}
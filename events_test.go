@@ -0,0 +1,148 @@
+package requests
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+)
+
+func kinds(events []Event) []EventKind {
+	out := make([]EventKind, len(events))
+	for i, ev := range events {
+		out[i] = ev.Kind
+	}
+	return out
+}
+
+func eqKinds(t *testing.T, got []EventKind, want ...EventKind) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("event kinds = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("event kinds = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestEventSinkPlainSuccess(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	var events []Event
+	client := NewClient().SetEventSink(func(ev Event) { events = append(events, ev) })
+	resp, err := client.NewRequest().SetURLByStr(mock.URL()).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode() != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode())
+	}
+
+	eqKinds(t, kinds(events), RequestStarted, ResponseReceived)
+	if events[0].RequestID == "" {
+		t.Error("RequestStarted.RequestID is empty")
+	}
+	if events[1].RequestID != events[0].RequestID {
+		t.Error("ResponseReceived.RequestID does not match RequestStarted.RequestID")
+	}
+	if events[1].StatusCode != http.StatusOK {
+		t.Errorf("ResponseReceived.StatusCode = %d, want 200", events[1].StatusCode)
+	}
+}
+
+func TestEventSinkRetryThenSuccess(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(HeaderExpect) != "" {
+			w.WriteHeader(http.StatusExpectationFailed)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	var events []Event
+	client := NewClient().SetEventSink(func(ev Event) { events = append(events, ev) })
+	resp, err := client.NewRequest().SetURLByStr(mock.URL()).
+		SetExpectContinue().
+		SetBody(bytes.NewReader([]byte("hello"))).
+		Post("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Attempts() != 2 {
+		t.Fatalf("Attempts() = %d, want 2", resp.Attempts())
+	}
+
+	eqKinds(t, kinds(events), RequestStarted, RequestRetried, ResponseReceived)
+	if events[1].Attempt != 2 {
+		t.Errorf("RequestRetried.Attempt = %d, want 2", events[1].Attempt)
+	}
+	for _, ev := range events {
+		if ev.RequestID != events[0].RequestID {
+			t.Errorf("event %v has RequestID %q, want %q", ev.Kind, ev.RequestID, events[0].RequestID)
+		}
+	}
+}
+
+func TestEventSinkCacheHit(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	var events []Event
+	client := NewClient().SetCache(FileCacheDir(t.TempDir())).
+		SetEventSink(func(ev Event) { events = append(events, ev) })
+
+	if _, err := client.NewRequest().SetURLByStr(mock.URL()).Get("/"); err != nil {
+		t.Fatal(err)
+	}
+	eqKinds(t, kinds(events), RequestStarted, CacheStored, ResponseReceived)
+
+	events = nil
+	resp, err := client.NewRequest().SetURLByStr(mock.URL()).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode() != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode())
+	}
+
+	eqKinds(t, kinds(events), RequestStarted, CacheHit)
+	if events[1].CacheKey == "" {
+		t.Error("CacheHit.CacheKey is empty")
+	}
+}
+
+func TestEventSinkTransportFailure(t *testing.T) {
+	var events []Event
+	client := NewClient().SetEventSink(func(ev Event) { events = append(events, ev) })
+	_, err := client.NewRequest().SetURLByStr("http://127.0.0.1:1").Get("/")
+	if err == nil {
+		t.Fatal("expected an error dialing a closed port")
+	}
+
+	eqKinds(t, kinds(events), RequestStarted, ErrorOccurred)
+	if events[1].Err == nil {
+		t.Error("ErrorOccurred.Err is nil")
+	}
+	if events[1].RequestID != events[0].RequestID {
+		t.Error("ErrorOccurred.RequestID does not match RequestStarted.RequestID")
+	}
+}
@@ -0,0 +1,59 @@
+package requests
+
+import (
+	"io"
+	"net/http"
+	"time"
+)
+
+// SetExpectContinue adds the "Expect: 100-continue" header to the
+// request, so a body is only sent once the server has responded 100
+// Continue. If the server replies 417 Expectation Failed instead, the
+// request is retried once without the header (see Response.Attempts);
+// servers that silently ignore the header entirely are bounded by
+// Client.SetExpectContinueTimeout.
+func (r *Request) SetExpectContinue() *Request {
+	r.headerParam.AddReplace(HeaderExpect, "100-continue")
+	return r
+}
+
+// SetExpectContinueTimeout bounds how long the transport waits for a
+// server to respond "100 Continue" before sending the request body
+// anyway, for servers that understand Expect: 100-continue but never
+// reply to it. Like SetTLSClientConfig, it swaps the transport atomically
+// rather than mutating the live one in place, so it's safe to call while
+// the client is handling traffic.
+func (c *Client) SetExpectContinueTimeout(d time.Duration) *Client {
+	err := c.reconfigureTransport(func(t *http.Transport) {
+		t.ExpectContinueTimeout = d
+	})
+	if err != nil {
+		c.printError(err)
+	}
+	return c
+}
+
+// buildExpectContinueFallback builds a clone of req without its Expect
+// header, for retrying after a 417 Expectation Failed. It reports false if
+// req's body can't be replayed (no GetBody and a non-nil Body).
+func buildExpectContinueFallback(req *http.Request) (*http.Request, bool) {
+	var body io.Reader
+	if req.GetBody != nil {
+		b, err := req.GetBody()
+		if err != nil {
+			return nil, false
+		}
+		body = b
+	} else if req.Body != nil {
+		return nil, false
+	}
+
+	clone, err := http.NewRequest(req.Method, req.URL.String(), body)
+	if err != nil {
+		return nil, false
+	}
+	clone.Header = req.Header.Clone()
+	clone.Header.Del(HeaderExpect)
+	clone.GetBody = req.GetBody
+	return clone.WithContext(req.Context()), true
+}
@@ -0,0 +1,75 @@
+package requests
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func TestExpectContinueFallbackOn417(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+
+	var receivedBodies [][]byte
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		receivedBodies = append(receivedBodies, b)
+		if r.Header.Get(HeaderExpect) != "" {
+			w.WriteHeader(http.StatusExpectationFailed)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	resp, err := NewRequest().SetURLByStr(mock.URL()).
+		SetExpectContinue().
+		SetBody(bytes.NewReader([]byte("hello"))).
+		Post("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode() != http.StatusOK {
+		t.Errorf("status = %d, want 200", resp.StatusCode())
+	}
+	if resp.Attempts() != 2 {
+		t.Errorf("Attempts() = %d, want 2", resp.Attempts())
+	}
+}
+
+func TestExpectContinueIgnoredByServer(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	cli := NewClient().SetExpectContinueTimeout(0)
+	resp, err := cli.NewRequest().SetURLByStr(mock.URL()).
+		SetExpectContinue().
+		SetBody(bytes.NewReader([]byte("hello"))).
+		Post("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode() != http.StatusOK {
+		t.Errorf("status = %d, want 200", resp.StatusCode())
+	}
+	if resp.Attempts() != 1 {
+		t.Errorf("Attempts() = %d, want 1", resp.Attempts())
+	}
+}
@@ -0,0 +1,91 @@
+package requests
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestResponseStatusClassHelpers(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	for _, code := range []int{200, 301, 404, 503} {
+		code := code
+		mock.HandleFunc("/"+strconv.Itoa(code), func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(code)
+		})
+	}
+
+	cases := []struct {
+		code                                        int
+		success, redirect, clientError, serverError bool
+	}{
+		{200, true, false, false, false},
+		{301, false, true, false, false},
+		{404, false, false, true, false},
+		{503, false, false, false, true},
+	}
+	for _, c := range cases {
+		resp, err := NewRequest().SetURLByStr(mock.URL()).SetFollowRedirect(false).Get("/" + strconv.Itoa(c.code))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.IsSuccess() != c.success {
+			t.Errorf("status %d: IsSuccess() = %v, want %v", c.code, resp.IsSuccess(), c.success)
+		}
+		if resp.IsRedirect() != c.redirect {
+			t.Errorf("status %d: IsRedirect() = %v, want %v", c.code, resp.IsRedirect(), c.redirect)
+		}
+		if resp.IsClientError() != c.clientError {
+			t.Errorf("status %d: IsClientError() = %v, want %v", c.code, resp.IsClientError(), c.clientError)
+		}
+		if resp.IsServerError() != c.serverError {
+			t.Errorf("status %d: IsServerError() = %v, want %v", c.code, resp.IsServerError(), c.serverError)
+		}
+	}
+}
+
+func TestResponseExpectStatus(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("backend is down for maintenance"))
+	})
+
+	resp, err := NewRequest().SetURLByStr(mock.URL()).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := resp.ExpectStatus(http.StatusOK, http.StatusCreated); err == nil {
+		t.Fatal("want ExpectStatus to fail for a 503")
+	} else {
+		unexpected, ok := err.(*ErrUnexpectedStatus)
+		if !ok {
+			t.Fatalf("err = %T, want *ErrUnexpectedStatus", err)
+		}
+		if unexpected.StatusCode() != http.StatusServiceUnavailable {
+			t.Errorf("StatusCode() = %d, want 503", unexpected.StatusCode())
+		}
+		if !strings.Contains(string(unexpected.Body()), "maintenance") {
+			t.Errorf("Body() = %q, want it to contain the response body", unexpected.Body())
+		}
+		if !strings.Contains(err.Error(), "200/201") || !strings.Contains(err.Error(), "503") {
+			t.Errorf("Error() = %q, want it to mention 200/201 and 503", err.Error())
+		}
+	}
+
+	if err := resp.ExpectStatus(http.StatusServiceUnavailable); err != nil {
+		t.Errorf("want ExpectStatus to succeed when 503 is allowed, got %v", err)
+	}
+}
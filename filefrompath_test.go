@@ -0,0 +1,65 @@
+package requests
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSetFileFromPath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "requests-filefrompath")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "upload.txt")
+	if err := ioutil.WriteFile(path, []byte("file contents"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+
+	var (
+		gotFileName string
+		gotBody     []byte
+	)
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		f, header, err := r.FormFile("file")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer f.Close()
+		gotFileName = header.Filename
+		gotBody, _ = ioutil.ReadAll(f)
+	})
+
+	req := NewRequest().SetURLByStr(mock.URL()).SetFileFromPath("file", path)
+	if _, err := req.Post("/"); err != nil {
+		t.Fatal(err)
+	}
+
+	if gotFileName != "upload.txt" {
+		t.Errorf("uploaded filename = %q, want %q", gotFileName, "upload.txt")
+	}
+	if string(gotBody) != "file contents" {
+		t.Errorf("uploaded body = %q, want %q", gotBody, "file contents")
+	}
+}
+
+func TestSetFileFromPathMissingFile(t *testing.T) {
+	req := NewRequest().SetURLByStr("http://example.com").SetFileFromPath("file", filepath.Join(os.TempDir(), "requests-does-not-exist"))
+	if _, err := req.RawRequest(); err == nil {
+		t.Error("expected an error for a missing file, got nil")
+	}
+}
@@ -0,0 +1,70 @@
+package requests
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+)
+
+// fingerprintVersion is prefixed onto every Request.Fingerprint, so a
+// future change to the algorithm below produces visibly different values
+// instead of silently colliding with or diverging from old ones.
+const fingerprintVersion = "v1"
+
+// fingerprintHeaders lists the headers folded into Request.Fingerprint.
+// Headers outside this list (Date, User-Agent, request IDs, ...) don't
+// affect request semantics, so two logically identical requests built by
+// different call sites still fingerprint the same.
+var fingerprintHeaders = []string{
+	HeaderContentType,
+	HeaderAuthorization,
+	HeaderAccept,
+}
+
+// Fingerprint returns a stable hash over the request's method, normalized
+// URL (sorted query string, path parameters already resolved into the
+// path), a fixed whitelist of headers (fingerprintHeaders), and a digest
+// of the body. It is deterministic across process restarts and library
+// versions and carries a version prefix ("v1:") so a future change to the
+// algorithm below is visible rather than silently colliding with old
+// values. Unlike RequestHash, it needs no Cache configured and is meant
+// for client-side duplicate detection rather than response caching.
+func (r *Request) Fingerprint() (string, error) {
+	req, err := r.Clone().RawRequest()
+	if err != nil {
+		return "", err
+	}
+
+	var body []byte
+	if req.Body != nil {
+		body, err = ioutil.ReadAll(req.Body)
+		if err != nil {
+			return "", err
+		}
+		req.Body.Close()
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		r.body = bytes.NewReader(body) // keep the body replayable for a later Do()
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(req.Method)
+	buf.WriteByte('\n')
+	buf.WriteString(req.URL.Scheme)
+	buf.WriteString("://")
+	buf.WriteString(req.URL.Host)
+	buf.WriteString(req.URL.Path)
+	buf.WriteByte('\n')
+	buf.WriteString(req.URL.Query().Encode()) // url.Values.Encode sorts by key
+	buf.WriteByte('\n')
+
+	for _, name := range fingerprintHeaders {
+		buf.WriteString(name)
+		buf.WriteByte(':')
+		buf.WriteString(req.Header.Get(name))
+		buf.WriteByte('\n')
+	}
+
+	sum := sha256.Sum256(append(buf.Bytes(), body...))
+	return fingerprintVersion + ":" + hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,88 @@
+package requests
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func TestFingerprintPinned(t *testing.T) {
+	req := NewRequest().SetURLByStr("http://example.com/items").
+		SetQuery("b", "2").
+		SetQuery("a", "1").
+		SetHeader(HeaderContentType, MimeTextPlain).
+		SetBody(bytes.NewReader([]byte("payload")))
+
+	got, err := req.Fingerprint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = "v1:aeb5c978af119ee499e8aca4a95fd890b9d41db175e7facce9d1b14b142d274a"
+	if got != want {
+		t.Errorf("Fingerprint() = %q, want %q (pinned: a change here means the algorithm changed)", got, want)
+	}
+}
+
+func TestFingerprintStableAcrossHeaderAndQueryOrder(t *testing.T) {
+	a, err := NewRequest().SetURLByStr("http://example.com/items").
+		SetQuery("a", "1").SetQuery("b", "2").
+		SetHeader(HeaderAccept, MimeTextPlain).
+		SetHeader(HeaderContentType, MimeTextPlain).
+		Fingerprint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewRequest().SetURLByStr("http://example.com/items").
+		SetQuery("b", "2").SetQuery("a", "1").
+		SetHeader(HeaderContentType, MimeTextPlain).
+		SetHeader(HeaderAccept, MimeTextPlain).
+		Fingerprint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a != b {
+		t.Errorf("fingerprints differ across header/query insertion order: %q vs %q", a, b)
+	}
+}
+
+func TestFingerprintChangesWithBody(t *testing.T) {
+	a, err := NewRequest().SetURLByStr("http://example.com/items").SetBody(bytes.NewReader([]byte("x"))).Fingerprint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewRequest().SetURLByStr("http://example.com/items").SetBody(bytes.NewReader([]byte("y"))).Fingerprint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a == b {
+		t.Errorf("expected different fingerprints for different bodies, got %q for both", a)
+	}
+}
+
+func TestFingerprintBodySurvivesForDo(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+
+	var got []byte
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		got, _ = ioutil.ReadAll(r.Body)
+	})
+
+	req := NewRequest().SetURLByStr(mock.URL()).SetBody(bytes.NewReader([]byte("payload")))
+	if _, err := req.Fingerprint(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := req.Post("/"); err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "payload" {
+		t.Errorf("body sent after Fingerprint() = %q, want %q", got, "payload")
+	}
+}
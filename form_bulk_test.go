@@ -0,0 +1,84 @@
+package requests
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestSetFormsBulk(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+
+	var got url.Values
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		got = r.Form
+	})
+
+	_, err = NewRequest().SetURLByStr(mock.URL()).
+		SetForms(map[string]string{"a": "1"}).
+		SetFormValues(url.Values{"b": {"2", "3", "4"}}).
+		Post("/")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	sort.Strings(got["b"])
+	want := url.Values{"a": {"1"}, "b": {"2", "3", "4"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestAddFormValuesWithFile(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+
+	var got url.Values
+	var gotFile string
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseMultipartForm(1 << 20)
+		got = r.MultipartForm.Value
+		f, _, err := r.FormFile("upload")
+		if err == nil {
+			buf := make([]byte, 64)
+			n, _ := f.Read(buf)
+			gotFile = string(buf[:n])
+		}
+	})
+
+	_, err = NewRequest().SetURLByStr(mock.URL()).
+		AddFormValues(url.Values{"b": {"2", "3"}}).
+		SetFile("upload", "f.txt", MimeTextPlain, strings.NewReader("payload")).
+		Post("/")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	sort.Strings(got["b"])
+	want := url.Values{"b": {"2", "3"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if gotFile != "payload" {
+		t.Errorf("file content = %q, want %q", gotFile, "payload")
+	}
+}
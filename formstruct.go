@@ -0,0 +1,176 @@
+package requests
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SetFormStruct encodes the public fields of v (a struct or pointer to
+// struct) into form parameters, using a `form:"name"` tag for the
+// parameter name (falling back to the lowercased field name) and an
+// `,omitempty` tag option to skip zero values. Supported field types are
+// string, integer, bool, float, time.Time (RFC 3339), slices (encoded as
+// repeated fields) and pointers; embedded structs are flattened into the
+// parent with no prefix. It composes with SetFile exactly like manually
+// built form fields: with files attached the request goes out as
+// multipart, otherwise urlencoded. An error for an unsupported field type
+// is deferred and returned by the same error path Do() returns, rather
+// than being dropped.
+func (r *Request) SetFormStruct(v interface{}) *Request {
+	if err := encodeFormStruct(r, reflect.ValueOf(v)); err != nil && r.deferredErr == nil {
+		r.deferredErr = err
+	}
+	return r
+}
+
+func encodeFormStruct(r *Request, v reflect.Value) error {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("requests: SetFormStruct requires a struct, got %s", v.Kind())
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" { // unexported
+			continue
+		}
+
+		fv := v.Field(i)
+		if field.Anonymous {
+			for fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					fv = reflect.Value{}
+					break
+				}
+				fv = fv.Elem()
+			}
+			if fv.IsValid() && fv.Kind() == reflect.Struct {
+				if err := encodeFormStruct(r, fv); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		name, omitempty, skip := formFieldNameAndOptions(field)
+		if skip {
+			continue
+		}
+		if err := encodeFormField(r, name, fv, omitempty); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func formFieldNameAndOptions(field reflect.StructField) (name string, omitempty, skip bool) {
+	tag := field.Tag.Get("form")
+	if tag == "-" {
+		return "", false, true
+	}
+	parts := strings.Split(tag, ",")
+	name = parts[0]
+	if name == "" {
+		name = strings.ToLower(field.Name)
+	}
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" {
+			omitempty = true
+		}
+	}
+	return name, omitempty, false
+}
+
+func encodeFormField(r *Request, name string, fv reflect.Value, omitempty bool) error {
+	for fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			return nil
+		}
+		fv = fv.Elem()
+	}
+
+	if t, ok := fv.Interface().(time.Time); ok {
+		if omitempty && t.IsZero() {
+			return nil
+		}
+		r.formParam.AddReplace(name, t.Format(time.RFC3339))
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		if omitempty && fv.String() == "" {
+			return nil
+		}
+		r.formParam.AddReplace(name, fv.String())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if omitempty && fv.Int() == 0 {
+			return nil
+		}
+		r.formParam.AddReplace(name, strconv.FormatInt(fv.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if omitempty && fv.Uint() == 0 {
+			return nil
+		}
+		r.formParam.AddReplace(name, strconv.FormatUint(fv.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		if omitempty && fv.Float() == 0 {
+			return nil
+		}
+		r.formParam.AddReplace(name, strconv.FormatFloat(fv.Float(), 'f', -1, 64))
+	case reflect.Bool:
+		if omitempty && !fv.Bool() {
+			return nil
+		}
+		r.formParam.AddReplace(name, strconv.FormatBool(fv.Bool()))
+	case reflect.Slice, reflect.Array:
+		if omitempty && fv.Len() == 0 {
+			return nil
+		}
+		for i := 0; i < fv.Len(); i++ {
+			if err := encodeFormSliceElem(r, name, fv.Index(i)); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("requests: SetFormStruct: unsupported field %q of type %s", name, fv.Type())
+	}
+	return nil
+}
+
+func encodeFormSliceElem(r *Request, name string, ev reflect.Value) error {
+	for ev.Kind() == reflect.Ptr {
+		if ev.IsNil() {
+			return nil
+		}
+		ev = ev.Elem()
+	}
+	if t, ok := ev.Interface().(time.Time); ok {
+		r.formParam.Add(name, t.Format(time.RFC3339))
+		return nil
+	}
+	switch ev.Kind() {
+	case reflect.String:
+		r.formParam.Add(name, ev.String())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		r.formParam.Add(name, strconv.FormatInt(ev.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		r.formParam.Add(name, strconv.FormatUint(ev.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		r.formParam.Add(name, strconv.FormatFloat(ev.Float(), 'f', -1, 64))
+	case reflect.Bool:
+		r.formParam.Add(name, strconv.FormatBool(ev.Bool()))
+	default:
+		return fmt.Errorf("requests: SetFormStruct: unsupported slice element of field %q, type %s", name, ev.Type())
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package requests
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"testing"
+)
+
+type Credentials struct {
+	User string `form:"user"`
+	Pass string `form:"pass"`
+}
+
+type loginForm struct {
+	Credentials
+	Remember bool     `form:"remember,omitempty"`
+	Scopes   []string `form:"scope"`
+}
+
+func TestSetFormStruct(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+
+	var got url.Values
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		got = r.Form
+	})
+
+	_, err = NewRequest().SetURLByStr(mock.URL()).
+		SetFormStruct(loginForm{
+			Credentials: Credentials{User: "a", Pass: "b"},
+			Scopes:      []string{"read", "write"},
+		}).
+		Post("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Get("user") != "a" || got.Get("pass") != "b" {
+		t.Errorf("user/pass = %q/%q", got.Get("user"), got.Get("pass"))
+	}
+	if got.Has("remember") {
+		t.Errorf("expected remember to be omitted, got %q", got.Get("remember"))
+	}
+	sort.Strings(got["scope"])
+	if len(got["scope"]) != 2 || got["scope"][0] != "read" || got["scope"][1] != "write" {
+		t.Errorf("scope = %v", got["scope"])
+	}
+}
+
+func TestSetFormStructWithFile(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+
+	var got url.Values
+	var gotFile string
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseMultipartForm(1 << 20)
+		got = r.MultipartForm.Value
+		f, _, err := r.FormFile("upload")
+		if err == nil {
+			buf := make([]byte, 64)
+			n, _ := f.Read(buf)
+			gotFile = string(buf[:n])
+		}
+	})
+
+	_, err = NewRequest().SetURLByStr(mock.URL()).
+		SetFormStruct(Credentials{User: "a", Pass: "b"}).
+		SetFile("upload", "f.txt", MimeTextPlain, strings.NewReader("payload")).
+		Post("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Get("user") != "a" || got.Get("pass") != "b" {
+		t.Errorf("user/pass = %q/%q", got.Get("user"), got.Get("pass"))
+	}
+	if gotFile != "payload" {
+		t.Errorf("file content = %q, want %q", gotFile, "payload")
+	}
+}
+
+type badFormStruct struct {
+	Ch chan int `form:"ch"`
+}
+
+func TestSetFormStructUnsupportedType(t *testing.T) {
+	_, err := NewRequest().SetFormStruct(badFormStruct{}).RawRequest()
+	if err == nil {
+		t.Fatal("expected an error for unsupported field type")
+	}
+}
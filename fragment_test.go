@@ -0,0 +1,91 @@
+package requests
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestFragmentSurvivesFill(t *testing.T) {
+	req, err := NewRequest().SetURLByStr("https://example.com/a/b#section").RawRequest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.URL.Fragment != "section" {
+		t.Errorf("Fragment = %q, want %q", req.URL.Fragment, "section")
+	}
+	if req.URL.RequestURI() != "/a/b" {
+		t.Errorf("RequestURI() = %q, want no fragment on the wire", req.URL.RequestURI())
+	}
+}
+
+func TestFragmentSurvivesPathAndQueryParams(t *testing.T) {
+	req, err := NewRequest().
+		SetURLByStr("https://example.com/users/{id}#profile").
+		SetPath("id", "42").
+		SetQuery("verbose", "1").
+		RawRequest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.URL.Fragment != "profile" {
+		t.Errorf("Fragment = %q, want %q", req.URL.Fragment, "profile")
+	}
+	if req.URL.Path != "/users/42" {
+		t.Errorf("Path = %q, want %q", req.URL.Path, "/users/42")
+	}
+	if req.URL.Query().Get("verbose") != "1" {
+		t.Errorf("query verbose = %q, want %q", req.URL.Query().Get("verbose"), "1")
+	}
+}
+
+func TestSetFragmentOverridesURLFragment(t *testing.T) {
+	req, err := NewRequest().SetURLByStr("https://example.com/a#old").SetFragment("new").RawRequest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.URL.Fragment != "new" {
+		t.Errorf("Fragment = %q, want %q", req.URL.Fragment, "new")
+	}
+}
+
+func TestFragmentAppearsInLocation(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {})
+
+	resp, err := NewRequest().SetURLByStr(mock.URL()).SetFragment("top").Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Location().Fragment != "top" {
+		t.Errorf("Location().Fragment = %q, want %q", resp.Location().Fragment, "top")
+	}
+}
+
+func TestFragmentFromBaseURL(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+	mock.HandleFunc("/x", func(w http.ResponseWriter, r *http.Request) {})
+
+	u := NewRequest().GetURL(mock.URL() + "/x#frag")
+	cli := NewClient().SetBaseURL(u)
+	resp, err := cli.NewRequest().Get("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Location().Fragment != "frag" {
+		t.Errorf("Location().Fragment = %q, want %q (inherited from Client.SetBaseURL)", resp.Location().Fragment, "frag")
+	}
+}
@@ -0,0 +1,127 @@
+package requests
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// GroupPolicy controls how a Group reacts once one of its requests
+// finishes, deciding whether the others still in flight keep running or
+// get cancelled.
+type GroupPolicy int
+
+const (
+	// GroupWaitAll lets every request in the group run to completion
+	// independently; nothing is cancelled early.
+	GroupWaitAll GroupPolicy = iota
+	// GroupCancelOnFirstError cancels every request still in flight as
+	// soon as one of them returns an error.
+	GroupCancelOnFirstError
+	// GroupFirstSuccessWins cancels every request still in flight as soon
+	// as one of them succeeds, e.g. when racing mirrors of the same
+	// resource.
+	GroupFirstSuccessWins
+)
+
+// Group runs a batch of related requests concurrently with shared
+// cancellation. Depending on its GroupPolicy, one request failing (or
+// succeeding) can cancel the others still in flight; cancellation
+// propagates to each in-flight request through its context, the same way
+// Request.SetContext / SetTimeout do, so the underlying transport aborts
+// the round trip and closes the request's body.
+//
+// The zero value is not usable; create one with NewGroup.
+type Group struct {
+	policy GroupPolicy
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu        sync.Mutex
+	wg        sync.WaitGroup
+	responses []*Response
+	errs      []error
+}
+
+// NewGroup creates a Group whose requests are all descendants of ctx:
+// cancelling ctx directly, or the group cancelling itself per its
+// GroupPolicy, aborts every request still in flight.
+func NewGroup(ctx context.Context, policy GroupPolicy) *Group {
+	ctx, cancel := context.WithCancel(ctx)
+	return &Group{policy: policy, ctx: ctx, cancel: cancel}
+}
+
+// Go submits req to run concurrently as part of the group. req keeps
+// whatever context or deadline it already has (see Request.SetContext,
+// Request.SetTimeout); the group's cancellation is layered on top, not
+// substituted for it, so req is aborted if either its own deadline
+// expires or the group cancels it first.
+func (g *Group) Go(req *Request) {
+	g.mu.Lock()
+	idx := len(g.responses)
+	g.responses = append(g.responses, nil)
+	g.errs = append(g.errs, nil)
+	g.mu.Unlock()
+
+	base := req.ctx
+	if base == nil {
+		base = context.Background()
+	}
+	ctx, cancel := context.WithCancel(base)
+	go func() {
+		select {
+		case <-g.ctx.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	req.SetContext(ctx)
+
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		defer cancel()
+
+		resp, err := req.do()
+		if err != nil && g.ctx.Err() != nil && errors.Is(err, context.Canceled) {
+			// Aborted by the group's own cancellation, not a genuine
+			// failure of this request -- don't let it show up in
+			// Wait's MultiError.
+			err = nil
+		}
+
+		g.mu.Lock()
+		g.responses[idx] = resp
+		g.errs[idx] = err
+		g.mu.Unlock()
+
+		switch {
+		case err != nil && g.policy == GroupCancelOnFirstError:
+			g.cancel()
+		case err == nil && g.policy == GroupFirstSuccessWins:
+			g.cancel()
+		}
+	}()
+}
+
+// Wait blocks until every request submitted via Go has finished or been
+// cancelled, then returns the responses in submission order -- a nil
+// entry marks a request that errored or was cancelled -- along with a
+// *MultiError aggregating every non-nil error, or nil if none occurred.
+// Requests aborted by the group's own cancellation (as opposed to failing
+// on their own) leave a nil response but don't contribute an error.
+func (g *Group) Wait() ([]*Response, error) {
+	g.wg.Wait()
+	g.cancel()
+
+	var errs []error
+	for _, err := range g.errs {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 0 {
+		return g.responses, nil
+	}
+	return g.responses, &MultiError{Errors: errs}
+}
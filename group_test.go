@@ -0,0 +1,160 @@
+package requests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func slowHandler(cancelled chan<- struct{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(2 * time.Second):
+			w.WriteHeader(http.StatusOK)
+		case <-r.Context().Done():
+			cancelled <- struct{}{}
+		}
+	}
+}
+
+// hijackAfterHandler closes the connection out from under the client after
+// delay, producing a deterministic transport-level error instead of a
+// status code -- used in place of dialing an unreachable address so the
+// failure lands only once its sibling request is reliably already in
+// flight.
+func hijackAfterHandler(delay time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(delay)
+		hj, ok := w.(http.Hijacker)
+		if !ok {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		conn, _, err := hj.Hijack()
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		conn.Close()
+	}
+}
+
+func TestGroupWaitAllRunsEveryRequestToCompletion(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	g := NewGroup(context.Background(), GroupWaitAll)
+	g.Go(NewClient().NewRequest().SetURLByStr("http://127.0.0.1:1/"))
+	g.Go(NewClient().NewRequest().SetURLByStr(mock.URL() + "/slow"))
+
+	resps, err := g.Wait()
+	if err == nil {
+		t.Fatal("Wait() err = nil, want a *MultiError for the unreachable request")
+	}
+	if _, ok := err.(*MultiError); !ok {
+		t.Errorf("Wait() err type = %T, want *MultiError", err)
+	}
+	if len(resps) != 2 {
+		t.Fatalf("len(resps) = %d, want 2", len(resps))
+	}
+	if resps[1] == nil || resps[1].StatusCode() != http.StatusOK {
+		t.Error("the slow request should have completed successfully despite the other one failing")
+	}
+}
+
+func TestGroupCancelOnFirstErrorCancelsInFlightRequests(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	cancelled := make(chan struct{}, 1)
+	mock.HandleFunc("/slow", slowHandler(cancelled))
+	mock.HandleFunc("/fail", hijackAfterHandler(50*time.Millisecond))
+
+	g := NewGroup(context.Background(), GroupCancelOnFirstError)
+	g.Go(NewClient().NewRequest().SetURLByStr(mock.URL() + "/fail"))
+	g.Go(NewClient().NewRequest().SetURLByStr(mock.URL() + "/slow"))
+
+	resps, err := g.Wait()
+	if err == nil {
+		t.Fatal("Wait() err = nil, want a *MultiError")
+	}
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Error("the slow request was never cancelled after its sibling failed")
+	}
+	if resps[1] != nil {
+		t.Error("the cancelled request's response should be nil")
+	}
+}
+
+func TestGroupFirstSuccessWinsCancelsTheRest(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	cancelled := make(chan struct{}, 1)
+	mock.HandleFunc("/fast", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mock.HandleFunc("/slow", slowHandler(cancelled))
+
+	g := NewGroup(context.Background(), GroupFirstSuccessWins)
+	g.Go(NewClient().NewRequest().SetURLByStr(mock.URL() + "/fast"))
+	g.Go(NewClient().NewRequest().SetURLByStr(mock.URL() + "/slow"))
+
+	resps, err := g.Wait()
+	if err != nil {
+		t.Fatalf("Wait() err = %v, want nil", err)
+	}
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Error("the mirror request was never cancelled after the other one succeeded")
+	}
+	if len(resps) != 2 {
+		t.Fatalf("len(resps) = %d, want 2", len(resps))
+	}
+}
+
+func TestGroupRequestKeepsItsOwnDeadline(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	g := NewGroup(context.Background(), GroupWaitAll)
+	req := NewClient().NewRequest().SetURLByStr(mock.URL() + "/slow").SetTimeout(20 * time.Millisecond)
+	g.Go(req)
+
+	_, err = g.Wait()
+	if err == nil {
+		t.Fatal("Wait() err = nil, want a deadline error from the request's own timeout")
+	}
+}
@@ -0,0 +1,44 @@
+package requests
+
+import "testing"
+
+func TestMaxHeaderCountExceeded(t *testing.T) {
+	cli := NewClient()
+	cli.SetMaxHeaderCount(2)
+
+	req := cli.NewRequest().SetURLByStr("http://example.com").
+		AddHeader("X-A", "aaaaaaaaaa").
+		AddHeader("X-B", "bbbbbbbbbb").
+		AddHeader("X-C", "cccccccccc")
+
+	_, err := req.RawRequest()
+	if err == nil {
+		t.Fatal("expected error for too many headers")
+	}
+	herr, ok := err.(*ErrHeaderLimitExceeded)
+	if !ok {
+		t.Fatalf("expected *ErrHeaderLimitExceeded, got %T: %v", err, err)
+	}
+	if herr.Count <= 2 {
+		t.Errorf("unexpected count: %d", herr.Count)
+	}
+	if len(herr.Largest) == 0 {
+		t.Error("expected largest headers to be named")
+	}
+}
+
+func TestMaxRequestHeaderBytesExceeded(t *testing.T) {
+	cli := NewClient()
+	cli.SetMaxRequestHeaderBytes(20)
+
+	req := cli.NewRequest().SetURLByStr("http://example.com").
+		AddHeader("X-Big", "this value is definitely over the byte limit")
+
+	_, err := req.RawRequest()
+	if err == nil {
+		t.Fatal("expected error for oversized headers")
+	}
+	if _, ok := err.(*ErrHeaderLimitExceeded); !ok {
+		t.Fatalf("expected *ErrHeaderLimitExceeded, got %T: %v", err, err)
+	}
+}
@@ -0,0 +1,35 @@
+package requests
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestSetHeadersBulk(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+
+	var gotA, gotB string
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		gotA = r.Header.Get("X-A")
+		gotB = r.Header.Get("X-B")
+	})
+
+	_, err = NewRequest().SetURLByStr(mock.URL()).
+		SetHeaders(map[string]string{"x-a": "1", "x-b": "2"}).
+		SetHeaderValues(http.Header{"X-B": {"3"}}).
+		Get("")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if gotA != "1" || gotB != "3" {
+		t.Errorf("got X-A=%q X-B=%q", gotA, gotB)
+	}
+}
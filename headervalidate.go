@@ -0,0 +1,78 @@
+package requests
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"golang.org/x/net/http/httpguts"
+)
+
+// ErrInvalidMessageHead is returned by RawRequest when the method, URL host,
+// or headers assembled for the request cannot be serialized as a
+// well-formed HTTP request line and header block -- most commonly because
+// one of them carries a CR, LF, or other control character that a
+// downstream proxy could misinterpret as the start of a second request
+// (request smuggling).
+type ErrInvalidMessageHead struct {
+	Part   string // "method", "host", "header name", or "header value"
+	Value  string
+	Reason string
+}
+
+func (e *ErrInvalidMessageHead) Error() string {
+	return fmt.Sprintf("requests: invalid %s %q: %s", e.Part, e.Value, e.Reason)
+}
+
+// obsFoldReplacer collapses RFC 7230 obsolete line folding (CRLF followed
+// by a space or tab) into a single space, so a folded value can pass
+// httpguts.ValidHeaderFieldValue.
+var obsFoldReplacer = strings.NewReplacer("\r\n\t", " ", "\r\n ", " ")
+
+// validateMethod rejects methods that aren't a single RFC 7230 token, so a
+// value like "GET /x HTTP/1.1\r\nHost: evil" can't smuggle extra request
+// lines through SetMethod.
+func validateMethod(method string) error {
+	if method == "" {
+		return &ErrInvalidMessageHead{Part: "method", Value: method, Reason: "must not be empty"}
+	}
+	if !httpguts.ValidHeaderFieldName(method) {
+		return &ErrInvalidMessageHead{Part: "method", Value: method, Reason: "must be a single HTTP token"}
+	}
+	return nil
+}
+
+// validateHost rejects Host values a proxy could misparse, mirroring the
+// check net/http's Request.Write does internally -- but run here, before
+// any I/O, so SetHost("evil\r\nX-Forwarded-Host: x") fails fast with a
+// descriptive error instead of surfacing deep inside the transport.
+func validateHost(host string) error {
+	if host != "" && !httpguts.ValidHostHeader(host) {
+		return &ErrInvalidMessageHead{Part: "host", Value: host, Reason: "contains characters not allowed in a Host header"}
+	}
+	return nil
+}
+
+// validateHeader rejects header names and values that aren't well-formed
+// per RFC 7230, so a CR/LF embedded in a header set via SetHeader/AddHeader
+// can't be used to inject a second header line or an entire second
+// request. allowObsoleteLineFolding permits the legacy "CRLF SP/HTAB"
+// continuation form (folded into a single space before re-validating)
+// instead of rejecting it outright; see Request.SetAllowObsoleteLineFolding.
+func validateHeader(header http.Header, allowObsoleteLineFolding bool) error {
+	for name, values := range header {
+		if !httpguts.ValidHeaderFieldName(name) {
+			return &ErrInvalidMessageHead{Part: "header name", Value: name, Reason: "must be a single HTTP token"}
+		}
+		for _, v := range values {
+			checked := v
+			if allowObsoleteLineFolding {
+				checked = obsFoldReplacer.Replace(checked)
+			}
+			if !httpguts.ValidHeaderFieldValue(checked) {
+				return &ErrInvalidMessageHead{Part: "header value", Value: v, Reason: fmt.Sprintf("invalid value for header %q", name)}
+			}
+		}
+	}
+	return nil
+}
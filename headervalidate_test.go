@@ -0,0 +1,148 @@
+package requests
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestValidateMethodRejectsEmptyAndSmuggledMethod(t *testing.T) {
+	cases := []string{"", "GET /x HTTP/1.1\r\nHost: evil", "GE T", "GET\r\n"}
+	for _, m := range cases {
+		if err := validateMethod(m); err == nil {
+			t.Errorf("validateMethod(%q) = nil, want error", m)
+		}
+	}
+	if err := validateMethod("PATCH"); err != nil {
+		t.Errorf("validateMethod(PATCH) = %v, want nil", err)
+	}
+}
+
+func TestValidateHostRejectsEmbeddedCRLF(t *testing.T) {
+	if err := validateHost("evil.example\r\nX-Forwarded-Host: x"); err == nil {
+		t.Error("validateHost with embedded CRLF = nil, want error")
+	}
+	if err := validateHost("example.com:8080"); err != nil {
+		t.Errorf("validateHost(example.com:8080) = %v, want nil", err)
+	}
+	if err := validateHost(""); err != nil {
+		t.Errorf("validateHost(\"\") = %v, want nil", err)
+	}
+}
+
+func TestValidateHeaderRejectsEmbeddedCRLFInNameAndValue(t *testing.T) {
+	bad := http.Header{"X-Evil\r\nX-Injected": {"1"}}
+	if err := validateHeader(bad, false); err == nil {
+		t.Error("validateHeader with CRLF in header name = nil, want error")
+	}
+
+	bad = http.Header{"X-Evil": {"line1\r\nX-Injected: line2"}}
+	if err := validateHeader(bad, false); err == nil {
+		t.Error("validateHeader with CRLF in header value = nil, want error")
+	}
+
+	good := http.Header{"X-Fine": {"normal value"}}
+	if err := validateHeader(good, false); err != nil {
+		t.Errorf("validateHeader(good) = %v, want nil", err)
+	}
+}
+
+func TestValidateHeaderAllowsObsoleteLineFoldingOnlyWhenEnabled(t *testing.T) {
+	folded := http.Header{"X-Folded": {"first\r\n second"}}
+	if err := validateHeader(folded, false); err == nil {
+		t.Error("validateHeader(folded, false) = nil, want error")
+	}
+	if err := validateHeader(folded, true); err != nil {
+		t.Errorf("validateHeader(folded, true) = %v, want nil", err)
+	}
+
+	// A bare CR/LF not followed by whitespace is still rejected even with
+	// the escape hatch enabled -- it isn't obs-fold, just an injection.
+	smuggled := http.Header{"X-Evil": {"line1\r\nX-Injected: line2"}}
+	if err := validateHeader(smuggled, true); err == nil {
+		t.Error("validateHeader(smuggled, true) = nil, want error")
+	}
+}
+
+func TestSetMethodRejectsSmugglingAttemptAtSendTime(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("handler should not be reached for an invalid method")
+	})
+
+	_, err = NewRequest().SetURLByStr(mock.URL()).
+		SetMethod("GET /x HTTP/1.1\r\nHost: evil").do()
+	if err == nil {
+		t.Fatal("do() with a smuggled method = nil error, want error")
+	}
+}
+
+func TestSetHeaderRejectsEmbeddedCRLFAtSendTime(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("handler should not be reached for an invalid header value")
+	})
+
+	_, err = NewRequest().SetURLByStr(mock.URL()).
+		SetHeader("X-Evil", "line1\r\nX-Injected: line2").Get("/")
+	if err == nil {
+		t.Fatal("Get() with a CRLF-smuggled header value = nil error, want error")
+	}
+}
+
+// FuzzRawRequestNeverEmitsControlCharacters feeds arbitrary strings into
+// the method, a header name/value pair, and the host override, and checks
+// that whenever RawRequest succeeds, none of the resulting request line or
+// header block contains a bare CR or LF -- i.e. no sequence of setter
+// calls can sneak a second request line past validation.
+func FuzzRawRequestNeverEmitsControlCharacters(f *testing.F) {
+	f.Add("GET", "X-Test", "value", "")
+	f.Add("GET /x HTTP/1.1\r\nHost: evil", "X-Test", "value", "")
+	f.Add("GET", "X-Evil\r\nX-Injected", "1", "")
+	f.Add("GET", "X-Test", "line1\r\nX-Injected: line2", "")
+	f.Add("GET", "X-Test", "value", "evil\r\nX-Forwarded-Host: x")
+
+	f.Fuzz(func(t *testing.T, method, headerName, headerValue, host string) {
+		r := NewClient().NewRequest().SetURLByStr("http://example.com/")
+		r.SetMethod(method)
+		if headerName != "" {
+			r.SetHeader(headerName, headerValue)
+		}
+		if host != "" {
+			r.SetHost(host)
+		}
+
+		req, err := r.RawRequest()
+		if err != nil {
+			return
+		}
+		if strings.ContainsAny(req.Method, "\r\n") {
+			t.Fatalf("RawRequest() produced a method containing CR/LF: %q", req.Method)
+		}
+		if strings.ContainsAny(req.Host, "\r\n") {
+			t.Fatalf("RawRequest() produced a Host containing CR/LF: %q", req.Host)
+		}
+		for name, values := range req.Header {
+			if strings.ContainsAny(name, "\r\n") {
+				t.Fatalf("RawRequest() produced a header name containing CR/LF: %q", name)
+			}
+			for _, v := range values {
+				if strings.ContainsAny(v, "\r\n") {
+					t.Fatalf("RawRequest() produced a header value containing CR/LF: %q", v)
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,60 @@
+package requests
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestSetHostOverridesWireHost(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	var gotHost string
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		gotHost = r.Host
+	})
+
+	_, err = NewRequest().SetURLByStr(mock.URL()).SetHost("virtual.example.com").Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotHost != "virtual.example.com" {
+		t.Errorf("r.Host = %q, want %q", gotHost, "virtual.example.com")
+	}
+}
+
+func TestSetHostChangesCacheHash(t *testing.T) {
+	req1, err := NewRequest().SetURLByStr("https://example.com/").SetHost("a.example.com").RawRequest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	req2, err := NewRequest().SetURLByStr("https://example.com/").SetHost("b.example.com").RawRequest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h1, err := RequestHash(req1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h2, err := RequestHash(req2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h1 == h2 {
+		t.Error("RequestHash ignored two different Host overrides")
+	}
+}
+
+func TestSetHostAppearsInMessage(t *testing.T) {
+	req := NewRequest().SetURLByStr("https://example.com/").SetHost("virtual.example.com")
+	if msg := req.MessageHead(); !strings.Contains(msg, "Host: virtual.example.com") {
+		t.Errorf("MessageHead() = %q, want it to mention Host: virtual.example.com", msg)
+	}
+}
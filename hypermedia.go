@@ -0,0 +1,212 @@
+package requests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+)
+
+// jsonAPIResource is one entry of a JSON:API "data" member, either the
+// envelope's top-level object or one element of its array form.
+type jsonAPIResource struct {
+	Type          string                     `json:"type"`
+	ID            string                     `json:"id"`
+	Attributes    json.RawMessage            `json:"attributes"`
+	Relationships map[string]json.RawMessage `json:"relationships"`
+}
+
+// JSONAPIMeta carries the parts of a JSON:API envelope that don't fit
+// into the flattened attributes Response.JSONAPI decodes into its out
+// parameter: each resource's relationships (one entry, in document
+// order, whether the envelope's "data" was a single object or an array)
+// and the envelope's top-level "links".
+type JSONAPIMeta struct {
+	Relationships []map[string]json.RawMessage
+	Links         map[string]string
+}
+
+// JSONAPI decodes a JSON:API response (https://jsonapi.org): the "data"
+// member's "attributes" are flattened into out, which must be a pointer
+// to a struct for a single-resource envelope ("data" is an object) or a
+// pointer to a slice for a collection envelope ("data" is an array).
+// Relationships and the envelope's top-level links, which don't fit into
+// out, are returned via JSONAPIMeta. Errors from a malformed envelope
+// name the offending member using a "$"-rooted JSON path, e.g.
+// "$.data[2].attributes".
+func (r *Response) JSONAPI(out interface{}) (*JSONAPIMeta, error) {
+	var envelope struct {
+		Data  json.RawMessage   `json:"data"`
+		Links map[string]string `json:"links"`
+	}
+	if err := json.Unmarshal(r.body, &envelope); err != nil {
+		return nil, fmt.Errorf(`requests: decoding JSON:API envelope at "$": %w`, err)
+	}
+	relationships, err := decodeJSONAPIData(envelope.Data, out)
+	if err != nil {
+		return nil, err
+	}
+	return &JSONAPIMeta{Relationships: relationships, Links: envelope.Links}, nil
+}
+
+func decodeJSONAPIData(data json.RawMessage, out interface{}) ([]map[string]json.RawMessage, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, fmt.Errorf(`requests: JSON:API envelope at "$.data" is missing`)
+	}
+
+	if trimmed[0] != '[' {
+		var res jsonAPIResource
+		if err := json.Unmarshal(data, &res); err != nil {
+			return nil, fmt.Errorf(`requests: decoding JSON:API envelope at "$.data": %w`, err)
+		}
+		if len(res.Attributes) > 0 {
+			if err := json.Unmarshal(res.Attributes, out); err != nil {
+				return nil, fmt.Errorf(`requests: decoding JSON:API envelope at "$.data.attributes": %w`, err)
+			}
+		}
+		return []map[string]json.RawMessage{res.Relationships}, nil
+	}
+
+	var resources []jsonAPIResource
+	if err := json.Unmarshal(data, &resources); err != nil {
+		return nil, fmt.Errorf(`requests: decoding JSON:API envelope at "$.data": %w`, err)
+	}
+
+	outVal := reflect.ValueOf(out)
+	if outVal.Kind() != reflect.Ptr || outVal.Elem().Kind() != reflect.Slice {
+		return nil, fmt.Errorf(`requests: JSON:API envelope at "$.data" is an array, out must be a pointer to a slice`)
+	}
+	elemType := outVal.Elem().Type().Elem()
+	sliceVal := reflect.MakeSlice(outVal.Elem().Type(), len(resources), len(resources))
+	relationships := make([]map[string]json.RawMessage, len(resources))
+	for i, res := range resources {
+		if len(res.Attributes) > 0 {
+			elemPtr := reflect.New(elemType)
+			if err := json.Unmarshal(res.Attributes, elemPtr.Interface()); err != nil {
+				return nil, fmt.Errorf(`requests: decoding JSON:API envelope at "$.data[%d].attributes": %w`, i, err)
+			}
+			sliceVal.Index(i).Set(elemPtr.Elem())
+		}
+		relationships[i] = res.Relationships
+	}
+	outVal.Elem().Set(sliceVal)
+
+	return relationships, nil
+}
+
+// halLink is one HAL _links entry, which the spec allows to be either a
+// bare link object or an array of them; only the first href is used.
+type halLink struct {
+	Href string `json:"href"`
+}
+
+func (l *halLink) UnmarshalJSON(data []byte) error {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var links []struct {
+			Href string `json:"href"`
+		}
+		if err := json.Unmarshal(data, &links); err != nil {
+			return err
+		}
+		if len(links) > 0 {
+			l.Href = links[0].Href
+		}
+		return nil
+	}
+	var link struct {
+		Href string `json:"href"`
+	}
+	if err := json.Unmarshal(data, &link); err != nil {
+		return err
+	}
+	l.Href = link.Href
+	return nil
+}
+
+// HALLinks decodes a HAL response's (https://stateless.group/hal_specification.html)
+// "_links" member into a map keyed by rel, with each href resolved into
+// an absolute URL against Location(), so relative hrefs work the same
+// way they would in a browser. A rel with no href is omitted. A
+// malformed "_links" member is logged, naming the offending rel with a
+// "$"-rooted JSON path, and HALLinks returns whatever rels it managed to
+// decode rather than failing the whole map.
+func (r *Response) HALLinks() map[string]*url.URL {
+	var envelope struct {
+		Links map[string]json.RawMessage `json:"_links"`
+	}
+	if err := json.Unmarshal(r.body, &envelope); err != nil {
+		if r.client != nil {
+			r.client.printErrorLevel(fmt.Errorf(`requests: decoding HAL envelope at "$._links": %w`, err), LogError)
+		}
+		return nil
+	}
+	out := make(map[string]*url.URL, len(envelope.Links))
+	for rel, raw := range envelope.Links {
+		var link halLink
+		if err := json.Unmarshal(raw, &link); err != nil {
+			if r.client != nil {
+				r.client.printErrorLevel(fmt.Errorf(`requests: decoding HAL envelope at "$._links.%s": %w`, rel, err), LogError)
+			}
+			continue
+		}
+		if link.Href == "" {
+			continue
+		}
+		u, err := url.Parse(link.Href)
+		if err != nil {
+			if r.client != nil {
+				r.client.printErrorLevel(fmt.Errorf(`requests: decoding HAL envelope at "$._links.%s.href": %w`, rel, err), LogError)
+			}
+			continue
+		}
+		if r.location != nil {
+			u = r.location.ResolveReference(u)
+		}
+		out[rel] = u
+	}
+	return out
+}
+
+// NextPageRequest builds a GET Request for this response's "next" page
+// link, checking HAL's _links.next and then JSON:API's top-level
+// links.next, and reports whether one was found. It composes with
+// HALLinks/JSONAPI's URL resolution, so callers can walk a paginated
+// collection with:
+//
+//	for {
+//	    resp, err := req.Do()
+//	    ...
+//	    req, ok = resp.NextPageRequest()
+//	    if !ok {
+//	        break
+//	    }
+//	}
+func (r *Response) NextPageRequest() (*Request, bool) {
+	if links := r.HALLinks(); links != nil {
+		if u, ok := links["next"]; ok {
+			return r.client.NewRequest().SetURL(u), true
+		}
+	}
+
+	var envelope struct {
+		Links map[string]string `json:"links"`
+	}
+	if err := json.Unmarshal(r.body, &envelope); err != nil {
+		return nil, false
+	}
+	href := envelope.Links["next"]
+	if href == "" {
+		return nil, false
+	}
+	u, err := url.Parse(href)
+	if err != nil {
+		return nil, false
+	}
+	if r.location != nil {
+		u = r.location.ResolveReference(u)
+	}
+	return r.client.NewRequest().SetURL(u), true
+}
@@ -0,0 +1,226 @@
+package requests
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestJSONAPISingleResource(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"data": {
+				"type": "articles",
+				"id": "1",
+				"attributes": {"title": "hello"},
+				"relationships": {"author": {"data": {"type": "people", "id": "9"}}}
+			},
+			"links": {"self": "/articles/1"}
+		}`))
+	})
+
+	resp, err := NewRequest().SetURLByStr(mock.URL()).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var article struct {
+		Title string `json:"title"`
+	}
+	meta, err := resp.JSONAPI(&article)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if article.Title != "hello" {
+		t.Errorf("Title = %q, want %q", article.Title, "hello")
+	}
+	if len(meta.Relationships) != 1 || len(meta.Relationships[0]) == 0 {
+		t.Errorf("Relationships = %v, want one non-empty entry", meta.Relationships)
+	}
+	if meta.Links["self"] != "/articles/1" {
+		t.Errorf(`Links["self"] = %q, want %q`, meta.Links["self"], "/articles/1")
+	}
+}
+
+func TestJSONAPICollection(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"data": [
+				{"type": "articles", "id": "1", "attributes": {"title": "a"}, "relationships": {"author": {"data": {"id": "1"}}}},
+				{"type": "articles", "id": "2", "attributes": {"title": "b"}}
+			],
+			"links": {"next": "/articles?page=2"}
+		}`))
+	})
+
+	resp, err := NewRequest().SetURLByStr(mock.URL()).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var articles []struct {
+		Title string `json:"title"`
+	}
+	meta, err := resp.JSONAPI(&articles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(articles) != 2 || articles[0].Title != "a" || articles[1].Title != "b" {
+		t.Errorf("articles = %+v, want [{a} {b}]", articles)
+	}
+	if len(meta.Relationships) != 2 || len(meta.Relationships[0]) == 0 || len(meta.Relationships[1]) != 0 {
+		t.Errorf("Relationships = %v, want [non-empty empty]", meta.Relationships)
+	}
+
+	next, ok := resp.NextPageRequest()
+	if !ok {
+		t.Fatal("expected a next-page request from links.next")
+	}
+	if u := next.GetURL(""); !strings.HasSuffix(u.String(), "/articles?page=2") {
+		t.Errorf("next page URL = %q, want suffix %q", u, "/articles?page=2")
+	}
+}
+
+func TestJSONAPIMissingData(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"links": {}}`))
+	})
+
+	resp, err := NewRequest().SetURLByStr(mock.URL()).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out struct{}
+	_, err = resp.JSONAPI(&out)
+	if err == nil {
+		t.Fatal("expected an error for a missing data member")
+	}
+	if !strings.Contains(err.Error(), "$.data") {
+		t.Errorf("error = %v, want it to name $.data", err)
+	}
+}
+
+func TestJSONAPICollectionIntoNonSlice(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data": [{"type": "articles", "id": "1"}]}`))
+	})
+
+	resp, err := NewRequest().SetURLByStr(mock.URL()).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out struct{}
+	_, err = resp.JSONAPI(&out)
+	if err == nil {
+		t.Fatal("expected an error when out isn't a pointer to a slice for an array data member")
+	}
+	if !strings.Contains(err.Error(), "$.data") {
+		t.Errorf("error = %v, want it to name $.data", err)
+	}
+}
+
+func TestHALLinksResolvesAgainstLocation(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+	mock.HandleFunc("/orders/1", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"_links": {
+				"self": {"href": "/orders/1"},
+				"next": [{"href": "/orders/2"}],
+				"empty": {"href": ""}
+			}
+		}`))
+	})
+
+	resp, err := NewRequest().SetURLByStr(mock.URL()).Get("/orders/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	links := resp.HALLinks()
+	if _, ok := links["empty"]; ok {
+		t.Error(`expected "empty" (blank href) to be omitted`)
+	}
+	self, ok := links["self"]
+	if !ok || !strings.HasSuffix(self.String(), "/orders/1") {
+		t.Errorf(`links["self"] = %v, want it to resolve to .../orders/1`, self)
+	}
+	next, ok := links["next"]
+	if !ok || !strings.HasSuffix(next.String(), "/orders/2") {
+		t.Errorf(`links["next"] (array form) = %v, want it to resolve to .../orders/2`, next)
+	}
+
+	nextReq, ok := resp.NextPageRequest()
+	if !ok {
+		t.Fatal("expected a next-page request from _links.next")
+	}
+	if u := nextReq.GetURL(""); !strings.HasSuffix(u.String(), "/orders/2") {
+		t.Errorf("next page URL = %q, want suffix %q", u, "/orders/2")
+	}
+}
+
+func TestHALLinksMissing(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"title": "no links here"}`))
+	})
+
+	resp, err := NewRequest().SetURLByStr(mock.URL()).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if links := resp.HALLinks(); len(links) != 0 {
+		t.Errorf("HALLinks() = %v, want empty", links)
+	}
+	if _, ok := resp.NextPageRequest(); ok {
+		t.Error("expected no next-page request when there are no links at all")
+	}
+}
@@ -0,0 +1,150 @@
+package requests
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+var uuidv4Pattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestSetIdempotencyKeySendsLiteralHeaderAndShowsInMessage(t *testing.T) {
+	req := NewRequest().SetIdempotencyKey("order-123").SetBodyString("x")
+	req.method = MethodPost
+	req.SetURLByStr("http://example.invalid/")
+
+	rawReq, err := req.RawRequest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := rawReq.Header.Get(HeaderIdempotencyKey); got != "order-123" {
+		t.Errorf("header = %q, want %q", got, "order-123")
+	}
+	if msg := req.Message(); !strings.Contains(msg, "Idempotency-Key: order-123") {
+		t.Errorf("Message() = %q, want it to include the idempotency key header", msg)
+	}
+}
+
+func TestWithAutoIdempotencyKeyGeneratesUUIDv4(t *testing.T) {
+	req := NewRequest().WithAutoIdempotencyKey().SetBodyString("x")
+	req.method = MethodPost
+	req.SetURLByStr("http://example.invalid/")
+
+	rawReq, err := req.RawRequest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := rawReq.Header.Get(HeaderIdempotencyKey)
+	if !uuidv4Pattern.MatchString(got) {
+		t.Errorf("header = %q, want a UUIDv4", got)
+	}
+}
+
+func TestAutoIdempotencyKeyStaysStableAcrossRetries(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	var calls int32
+	var keys []string
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get(HeaderIdempotencyKey))
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	resp, err := NewRequest().SetRetry(3).WithAutoIdempotencyKey().Post(mock.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode() != http.StatusOK {
+		t.Errorf("status = %d, want 200", resp.StatusCode())
+	}
+	if len(keys) != 3 {
+		t.Fatalf("got %d attempts, want 3", len(keys))
+	}
+	for _, k := range keys[1:] {
+		if k != keys[0] {
+			t.Errorf("keys = %v, want every attempt to reuse the same key", keys)
+			break
+		}
+	}
+}
+
+func TestAutoIdempotencyKeyDiffersAcrossSeparateCalls(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	var keys []string
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get(HeaderIdempotencyKey))
+		w.WriteHeader(http.StatusOK)
+	})
+
+	template := NewRequest().WithAutoIdempotencyKey()
+	if _, err := template.Post(mock.URL()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := template.Post(mock.URL()); err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 {
+		t.Fatalf("got %d calls, want 2", len(keys))
+	}
+	if keys[0] == keys[1] {
+		t.Errorf("keys = %v, want two separate calls to get two different auto-generated keys", keys)
+	}
+}
+
+func TestIdempotencyKeyParticipatesInCacheHash(t *testing.T) {
+	reqA := NewRequest().SetIdempotencyKey("key-a").SetBodyString("same body")
+	reqA.method = MethodPost
+	reqA.SetURLByStr("http://example.invalid/")
+	rawA, err := reqA.RawRequest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	hashA, err := RequestHash(rawA)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reqB := NewRequest().SetIdempotencyKey("key-b").SetBodyString("same body")
+	reqB.method = MethodPost
+	reqB.SetURLByStr("http://example.invalid/")
+	rawB, err := reqB.RawRequest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	hashB, err := RequestHash(rawB)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if hashA == hashB {
+		t.Errorf("hashes equal for different idempotency keys: %s", hashA)
+	}
+
+	reqC := NewRequest().SetIdempotencyKey("key-a").SetBodyString("same body")
+	reqC.method = MethodPost
+	reqC.SetURLByStr("http://example.invalid/")
+	rawC, err := reqC.RawRequest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	hashC, err := RequestHash(rawC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hashA != hashC {
+		t.Errorf("hashes differ for the same explicit idempotency key: %s vs %s", hashA, hashC)
+	}
+}
@@ -0,0 +1,99 @@
+package requests
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestResponseJSONDecodesBody(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"name":"gopher"}`))
+	})
+
+	resp, err := NewRequest().SetURLByStr(mock.URL()).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out struct {
+		Name string `json:"name"`
+	}
+	if err := resp.JSON(&out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Name != "gopher" {
+		t.Errorf("Name = %q, want gopher", out.Name)
+	}
+}
+
+func TestResponseJSONErrorIncludesStatusAndSnippet(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("<html><body>Internal Server Error</body></html>"))
+	})
+
+	resp, err := NewRequest().SetURLByStr(mock.URL()).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out struct{}
+	err = resp.JSON(&out)
+	if err == nil {
+		t.Fatal("want an error decoding an HTML error page as JSON")
+	}
+	msg := err.Error()
+	if !strings.Contains(msg, "500") {
+		t.Errorf("error = %q, want it to mention status 500", msg)
+	}
+	if !strings.Contains(msg, "Internal Server Error") {
+		t.Errorf("error = %q, want it to include a body snippet", msg)
+	}
+}
+
+func TestResponseJSONStrictRejectsUnknownFields(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"name":"gopher","extra":true}`))
+	})
+
+	resp, err := NewRequest().SetURLByStr(mock.URL()).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var strict struct {
+		Name string `json:"name"`
+	}
+	if err := resp.JSONStrict(&strict); err == nil {
+		t.Error("want JSONStrict to reject an unknown field")
+	}
+
+	var lenient struct {
+		Name string `json:"name"`
+	}
+	if err := resp.JSON(&lenient); err != nil {
+		t.Fatalf("want JSON to tolerate an unknown field, got %v", err)
+	}
+	if lenient.Name != "gopher" {
+		t.Errorf("Name = %q, want gopher", lenient.Name)
+	}
+}
@@ -0,0 +1,151 @@
+package requests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+)
+
+// JSONRPCError is the decoded "error" member of a JSON-RPC 2.0 response,
+// see https://www.jsonrpc.org/specification#error_object. It implements
+// error, so it can be returned directly from Response.JSONRPC.
+type JSONRPCError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *JSONRPCError) Error() string {
+	return fmt.Sprintf("requests: JSON-RPC error %d: %s", e.Code, e.Message)
+}
+
+// JSONRPCCall is one call of a SetJSONRPCBatch request. Notify mirrors
+// SetJSONRPCNotify: if true, this call's envelope gets no "id", so the
+// server sends no reply for it, and Response.JSONRPCBatch leaves the
+// matching slot of its result zero rather than waiting for one.
+type JSONRPCCall struct {
+	Method string
+	Params interface{}
+	Notify bool
+}
+
+type jsonrpcEnvelope struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  interface{}     `json:"params,omitempty"`
+	ID      json.RawMessage `json:"id,omitempty"`
+}
+
+type jsonrpcReply struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result"`
+	Error   *JSONRPCError   `json:"error"`
+}
+
+// nextJSONRPCID returns a new JSON-RPC id, as a JSON number, unique
+// within this client's lifetime.
+func (c *Client) nextJSONRPCID() json.RawMessage {
+	return []byte(fmt.Sprintf("%d", atomic.AddInt64(&c.jsonrpcSeq, 1)))
+}
+
+// SetJSONRPC builds a JSON-RPC 2.0 (https://www.jsonrpc.org/specification)
+// request calling method with params, assigning it an id unique within
+// this Request's client's lifetime. Response.JSONRPC checks the server's
+// reply against that id. Use SetJSONRPCNotify instead for a call that
+// doesn't want a reply, or SetJSONRPCBatch to send several calls at once.
+func (r *Request) SetJSONRPC(method string, params interface{}) *Request {
+	id := r.client.nextJSONRPCID()
+	r.jsonrpcID = id
+	return r.SetJSON(jsonrpcEnvelope{JSONRPC: "2.0", Method: method, Params: params, ID: id})
+}
+
+// SetJSONRPCNotify builds a JSON-RPC 2.0 notification calling method with
+// params: the same envelope as SetJSONRPC, but with no "id", so a
+// compliant server sends no reply. Don't call Response.JSONRPC against
+// the response to a notification; there's no id to check it against, and
+// usually no body either.
+func (r *Request) SetJSONRPCNotify(method string, params interface{}) *Request {
+	r.jsonrpcID = nil
+	return r.SetJSON(jsonrpcEnvelope{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+// SetJSONRPCBatch builds a JSON-RPC 2.0 batch request: calls is sent as a
+// single JSON array holding one envelope per call, each assigned its own
+// id unless its Notify is true. Response.JSONRPCBatch decodes the
+// matching array of replies, in the same order as calls.
+func (r *Request) SetJSONRPCBatch(calls []JSONRPCCall) *Request {
+	envelopes := make([]jsonrpcEnvelope, len(calls))
+	ids := make([]json.RawMessage, len(calls))
+	for i, call := range calls {
+		envelopes[i] = jsonrpcEnvelope{JSONRPC: "2.0", Method: call.Method, Params: call.Params}
+		if !call.Notify {
+			id := r.client.nextJSONRPCID()
+			envelopes[i].ID = id
+			ids[i] = id
+		}
+	}
+	r.jsonrpcBatchIDs = ids
+	return r.SetJSON(envelopes)
+}
+
+// JSONRPC decodes a JSON-RPC 2.0 response to a SetJSONRPC call: on
+// success, "result" is unmarshaled into resultPtr (which may be nil to
+// discard it); on failure, the "error" member is returned as a
+// *JSONRPCError. Either way, the reply's "id" is checked against the one
+// SetJSONRPC assigned this call, and a mismatch is returned as a plain
+// error without touching resultPtr.
+func (r *Response) JSONRPC(resultPtr interface{}) error {
+	var reply jsonrpcReply
+	if err := json.Unmarshal(r.body, &reply); err != nil {
+		return fmt.Errorf("requests: decoding JSON-RPC response: %w", err)
+	}
+	if !bytes.Equal(bytes.TrimSpace(reply.ID), bytes.TrimSpace(r.jsonrpcID)) {
+		return fmt.Errorf("requests: JSON-RPC response id %s does not match request id %s", reply.ID, r.jsonrpcID)
+	}
+	if reply.Error != nil {
+		return reply.Error
+	}
+	if resultPtr != nil && len(reply.Result) > 0 {
+		if err := json.Unmarshal(reply.Result, resultPtr); err != nil {
+			return fmt.Errorf("requests: decoding JSON-RPC result: %w", err)
+		}
+	}
+	return nil
+}
+
+// JSONRPCResult is one decoded reply of a SetJSONRPCBatch call: Result is
+// the raw "result" member, left nil for a call that got "error" instead
+// (in Err) or for a JSONRPCCall sent with Notify, which gets no reply at
+// all.
+type JSONRPCResult struct {
+	Result json.RawMessage
+	Err    *JSONRPCError
+}
+
+// JSONRPCBatch decodes a JSON-RPC 2.0 batch response to a
+// SetJSONRPCBatch call, matching each reply back to its call by id and
+// returning them in the same order as the original calls slice.
+func (r *Response) JSONRPCBatch() ([]JSONRPCResult, error) {
+	var replies []jsonrpcReply
+	if err := json.Unmarshal(r.body, &replies); err != nil {
+		return nil, fmt.Errorf("requests: decoding JSON-RPC batch response: %w", err)
+	}
+	byID := make(map[string]jsonrpcReply, len(replies))
+	for _, reply := range replies {
+		byID[string(reply.ID)] = reply
+	}
+	out := make([]JSONRPCResult, len(r.jsonrpcBatchIDs))
+	for i, id := range r.jsonrpcBatchIDs {
+		if id == nil {
+			continue
+		}
+		reply, ok := byID[string(id)]
+		if !ok {
+			return nil, fmt.Errorf("requests: JSON-RPC batch response is missing a reply for id %s", id)
+		}
+		out[i] = JSONRPCResult{Result: reply.Result, Err: reply.Error}
+	}
+	return out, nil
+}
@@ -0,0 +1,176 @@
+package requests
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func TestSetJSONRPCRoundTrip(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		var req jsonrpcEnvelope
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Error(err)
+			return
+		}
+		if req.Method != "sum" {
+			t.Errorf("method = %q, want sum", req.Method)
+		}
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":3}`, req.ID)
+	})
+
+	var result int
+	resp, err := NewRequest().SetURLByStr(mock.URL()).
+		SetJSONRPC("sum", []int{1, 2}).
+		Post("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := resp.JSONRPC(&result); err != nil {
+		t.Fatal(err)
+	}
+	if result != 3 {
+		t.Errorf("result = %d, want 3", result)
+	}
+}
+
+func TestJSONRPCReturnsTypedError(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		var req jsonrpcEnvelope
+		json.Unmarshal(body, &req)
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"error":{"code":-32601,"message":"method not found"}}`, req.ID)
+	})
+
+	resp, err := NewRequest().SetURLByStr(mock.URL()).
+		SetJSONRPC("nope", nil).
+		Post("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = resp.JSONRPC(nil)
+	rpcErr, ok := err.(*JSONRPCError)
+	if !ok {
+		t.Fatalf("err = %T(%v), want *JSONRPCError", err, err)
+	}
+	if rpcErr.Code != -32601 || rpcErr.Message != "method not found" {
+		t.Errorf("rpcErr = %+v, want code -32601 message %q", rpcErr, "method not found")
+	}
+}
+
+func TestJSONRPCRejectsMismatchedID(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"jsonrpc":"2.0","id":999999,"result":1}`)
+	})
+
+	resp, err := NewRequest().SetURLByStr(mock.URL()).
+		SetJSONRPC("sum", nil).
+		Post("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := resp.JSONRPC(nil); err == nil {
+		t.Fatal("want an error for a mismatched id")
+	}
+}
+
+func TestSetJSONRPCNotifyOmitsID(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	var gotBody string
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		gotBody = string(body)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	_, err = NewRequest().SetURLByStr(mock.URL()).
+		SetJSONRPCNotify("log", "hello").
+		Post("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sent map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(gotBody), &sent); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := sent["id"]; ok {
+		t.Errorf("notification body = %s, want no id member", gotBody)
+	}
+}
+
+func TestSetJSONRPCBatchDecodesMatchingReplies(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		var reqs []jsonrpcEnvelope
+		if err := json.Unmarshal(body, &reqs); err != nil {
+			t.Error(err)
+			return
+		}
+		if len(reqs) != 2 {
+			t.Errorf("batch size = %d, want 2", len(reqs))
+			return
+		}
+		fmt.Fprintf(w, `[{"jsonrpc":"2.0","id":%s,"result":"ok"},{"jsonrpc":"2.0","id":%s,"error":{"code":-32000,"message":"boom"}}]`,
+			reqs[0].ID, reqs[1].ID)
+	})
+
+	resp, err := NewRequest().SetURLByStr(mock.URL()).
+		SetJSONRPCBatch([]JSONRPCCall{
+			{Method: "first"},
+			{Method: "second"},
+		}).
+		Post("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := resp.JSONRPCBatch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("results = %d, want 2", len(results))
+	}
+	var first string
+	if err := json.Unmarshal(results[0].Result, &first); err != nil {
+		t.Fatal(err)
+	}
+	if first != "ok" {
+		t.Errorf("first = %q, want ok", first)
+	}
+	if results[1].Err == nil || results[1].Err.Code != -32000 {
+		t.Errorf("results[1].Err = %+v, want code -32000", results[1].Err)
+	}
+}
@@ -0,0 +1,113 @@
+package requests
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestSetJSONStream(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+
+	var (
+		gotBody        []byte
+		gotContentType string
+	)
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		gotBody, _ = ioutil.ReadAll(r.Body)
+		gotContentType = r.Header.Get(HeaderContentType)
+	})
+
+	_, err = NewRequest().SetURLByStr(mock.URL()).SetJSONStream(map[string]int{"n": 1}).Post("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if gotContentType != MimeJSON {
+		t.Errorf("Content-Type = %q, want %q", gotContentType, MimeJSON)
+	}
+	var got map[string]int
+	if err := json.Unmarshal(gotBody, &got); err != nil {
+		t.Fatalf("unmarshal body %q: %v", gotBody, err)
+	}
+	if got["n"] != 1 {
+		t.Errorf("body = %v, want {n:1}", got)
+	}
+}
+
+func TestSetJSONStreamEncodeErrorAbortsRequest(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+	})
+
+	// Functions can't be marshaled to JSON, so the encoder fails mid-write.
+	_, err = NewRequest().SetURLByStr(mock.URL()).SetJSONStream(map[string]interface{}{"f": func() {}}).Post("/")
+	if err == nil {
+		t.Fatal("expected an error from the aborted encode")
+	}
+}
+
+func TestSetNDJSON(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+
+	var (
+		gotLines       []string
+		gotContentType string
+	)
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		gotContentType = r.Header.Get(HeaderContentType)
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			gotLines = append(gotLines, scanner.Text())
+		}
+	})
+
+	ch := make(chan interface{})
+	go func() {
+		ch <- map[string]int{"n": 1}
+		ch <- map[string]int{"n": 2}
+		close(ch)
+	}()
+
+	_, err = NewRequest().SetURLByStr(mock.URL()).SetNDJSON(ch).Post("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if gotContentType != MimeNDJSON {
+		t.Errorf("Content-Type = %q, want %q", gotContentType, MimeNDJSON)
+	}
+	if len(gotLines) != 2 {
+		t.Fatalf("len(lines) = %d, want 2: %v", len(gotLines), gotLines)
+	}
+	for i, want := range []string{`{"n":1}`, `{"n":2}`} {
+		if strings.TrimSpace(gotLines[i]) != want {
+			t.Errorf("line %d = %q, want %q", i, gotLines[i], want)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package requests
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestSetMatrixParamEscapesReservedChars(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	var gotURI string
+	mock.HandleFunc("/.*", func(w http.ResponseWriter, r *http.Request) {
+		gotURI = r.RequestURI
+	})
+
+	_, err = NewRequest().SetURLByStr(mock.URL()).
+		SetPath("id", "42").
+		SetMatrixParam("42", "lang", "en;q=1,0").
+		Get("/{id}/sub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "/42;lang=en%3Bq%3D1%2C0/sub"
+	if gotURI != want {
+		t.Errorf("RequestURI = %q, want %q", gotURI, want)
+	}
+}
+
+func TestSetMatrixParamComposesWithPlaceholderAndMultipleKeys(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	var gotURI string
+	mock.HandleFunc("/.*", func(w http.ResponseWriter, r *http.Request) {
+		gotURI = r.RequestURI
+	})
+
+	_, err = NewRequest().SetURLByStr(mock.URL()).
+		SetPath("res", "resource").
+		SetMatrixParam("resource", "version", "2").
+		SetMatrixParam("resource", "lang", "en").
+		Get("/{res}/sub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "/resource;version=2;lang=en/sub"
+	if gotURI != want {
+		t.Errorf("RequestURI = %q, want %q", gotURI, want)
+	}
+}
+
+func TestSetMatrixParamEscapesSemicolonEqualsComma(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	var gotURI string
+	mock.HandleFunc("/.*", func(w http.ResponseWriter, r *http.Request) {
+		gotURI = r.RequestURI
+	})
+
+	_, err = NewRequest().SetURLByStr(mock.URL()).
+		SetMatrixParam("resource", "q", "a;b=c,d").
+		Get("/resource/sub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "/resource;q=a%3Bb%3Dc%2Cd/sub"
+	if gotURI != want {
+		t.Errorf("RequestURI = %q, want %q", gotURI, want)
+	}
+}
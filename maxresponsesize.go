@@ -0,0 +1,17 @@
+package requests
+
+// SetMaxResponseSize overrides Client.SetMaxResponseSize for this request.
+// n <= 0 disables the check for this request even if the client has one.
+func (r *Request) SetMaxResponseSize(n int64) *Request {
+	r.maxResponseSize = &n
+	return r
+}
+
+// effectiveMaxResponseSize resolves the per-request override, if any,
+// against the client's default. <= 0 means unlimited.
+func (r *Request) effectiveMaxResponseSize() int64 {
+	if r.maxResponseSize != nil {
+		return *r.maxResponseSize
+	}
+	return r.client.maxResponseSize
+}
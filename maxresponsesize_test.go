@@ -0,0 +1,80 @@
+package requests
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestSetMaxResponseSizeFailsOverLimit(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	body := bytes.Repeat([]byte("x"), 100)
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(body)
+	})
+
+	client := NewClient().SetMaxResponseSize(10)
+	resp, err := client.NewRequest().SetURLByStr(mock.URL()).Get("/")
+	if err == nil {
+		t.Fatal("want an error for a response over the limit")
+	}
+	var tooLarge *ErrResponseTooLarge
+	if !errors.As(err, &tooLarge) {
+		t.Fatalf("errors.As failed to extract *ErrResponseTooLarge from %v (%T)", err, err)
+	}
+	if tooLarge.Limit != 10 {
+		t.Errorf("Limit = %d, want 10", tooLarge.Limit)
+	}
+	if tooLarge.ContentLength != int64(len(body)) {
+		t.Errorf("ContentLength = %d, want %d", tooLarge.ContentLength, len(body))
+	}
+	if resp == nil {
+		t.Fatal("want a partial *Response for diagnostics")
+	}
+	if len(resp.Body()) != 10 {
+		t.Errorf("partial Body() len = %d, want 10", len(resp.Body()))
+	}
+}
+
+func TestSetMaxResponseSizeAllowsUnderLimit(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("short"))
+	})
+
+	client := NewClient().SetMaxResponseSize(1024)
+	resp, err := client.NewRequest().SetURLByStr(mock.URL()).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(resp.Body()) != "short" {
+		t.Errorf("Body() = %q, want %q", resp.Body(), "short")
+	}
+}
+
+func TestRequestSetMaxResponseSizeOverridesClient(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(bytes.Repeat([]byte("y"), 100))
+	})
+
+	client := NewClient().SetMaxResponseSize(10)
+	_, err = client.NewRequest().SetURLByStr(mock.URL()).SetMaxResponseSize(0).Get("/")
+	if err != nil {
+		t.Errorf("want the per-request override to disable the limit, got %v", err)
+	}
+}
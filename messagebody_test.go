@@ -0,0 +1,166 @@
+package requests
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// repeatingReader streams n bytes of a repeating pattern without ever
+// materializing them all at once, and counts how many times Read was
+// called so a test can tell whether something drained it.
+type repeatingReader struct {
+	remaining int64
+	reads     int64
+}
+
+func (r *repeatingReader) Read(p []byte) (int, error) {
+	atomic.AddInt64(&r.reads, 1)
+	if r.remaining <= 0 {
+		return 0, io.EOF
+	}
+	n := int64(len(p))
+	if n > r.remaining {
+		n = r.remaining
+	}
+	for i := int64(0); i < n; i++ {
+		p[i] = byte(i % 251)
+	}
+	r.remaining -= n
+	return int(n), nil
+}
+
+func TestMessageOmitsNonReplayableBody(t *testing.T) {
+	body := &repeatingReader{remaining: 100 * 1024 * 1024}
+	req := NewRequest().SetBody(body)
+	req.method = MethodPost
+	req.SetURLByStr("http://example.invalid/")
+
+	msg := req.Message()
+	if !strings.Contains(msg, "[body omitted: not replayable]") {
+		t.Errorf("Message() = %q, want it to note the body was omitted", msg)
+	}
+	if atomic.LoadInt64(&body.reads) != 0 {
+		t.Errorf("reads = %d, want 0: Message must never read from a non-replayable body", body.reads)
+	}
+}
+
+func TestMessageStreamsLargeUploadIntactWhileLogging(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	const size = 100 * 1024 * 1024
+	var gotSize int64
+	var gotSum string
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		h := sha256.New()
+		n, err := io.Copy(h, r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		gotSize = n
+		gotSum = hex.EncodeToString(h.Sum(nil))
+		w.WriteHeader(http.StatusOK)
+	})
+
+	wantSum := func() string {
+		h := sha256.New()
+		r := &repeatingReader{remaining: size}
+		io.Copy(h, r)
+		return hex.EncodeToString(h.Sum(nil))
+	}()
+
+	client := NewClient().SetLogger(ioutil.Discard).SetLogLevel(LogMessageAll)
+	body := &repeatingReader{remaining: size}
+	_, err = client.NewRequest().SetBody(body).Post(mock.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotSize != size {
+		t.Errorf("server received %d bytes, want %d", gotSize, size)
+	}
+	if gotSum != wantSum {
+		t.Errorf("server checksum = %s, want %s: upload was corrupted", gotSum, wantSum)
+	}
+}
+
+func TestMessageCapsReplayableBodyAtLogLimit(t *testing.T) {
+	payload := strings.Repeat("x", 1000)
+	client := NewClient().SetLogBodyLimit(10)
+	req := client.NewRequest().SetBodyString(payload)
+	req.method = MethodPost
+	req.SetURLByStr("http://example.invalid/")
+
+	msg := req.Message()
+	if !strings.Contains(msg, "xxxxxxxxxx\n[body truncated at 10 bytes]") {
+		t.Errorf("Message() = %q, want the body capped at 10 bytes with a truncation note", msg)
+	}
+}
+
+func TestMessageIncludesSmallReplayableBodyInFull(t *testing.T) {
+	req := NewRequest().SetBodyString("hello world")
+	req.method = MethodPost
+	req.SetURLByStr("http://example.invalid/")
+
+	msg := req.Message()
+	if !strings.Contains(msg, "hello world") {
+		t.Errorf("Message() = %q, want it to include the full small body", msg)
+	}
+}
+
+func TestMessageCallableMultipleTimesWithoutAffectingTheSend(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	var gotBody []byte
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		gotBody, _ = ioutil.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := NewRequest().SetBodyString("repeat me")
+	for i := 0; i < 3; i++ {
+		if msg := req.Message(); !strings.Contains(msg, "repeat me") {
+			t.Fatalf("Message() call %d = %q, want it to include the body", i, msg)
+		}
+	}
+	if _, err := req.Post(mock.URL()); err != nil {
+		t.Fatal(err)
+	}
+	if string(gotBody) != "repeat me" {
+		t.Errorf("server got body %q, want %q", gotBody, "repeat me")
+	}
+}
+
+func TestMessageDoesNotMutateLiveBodyForReplayableRequest(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	var gotBody []byte
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		gotBody, _ = ioutil.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	client := NewClient().SetLogger(ioutil.Discard).SetLogLevel(LogMessageAll)
+	_, err = client.NewRequest().SetBodyString("still intact").Post(mock.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(gotBody, []byte("still intact")) {
+		t.Errorf("server got body %q, want %q", gotBody, "still intact")
+	}
+}
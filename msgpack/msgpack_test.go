@@ -0,0 +1,96 @@
+package msgpack
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/wzshiming/requests"
+)
+
+type greeting struct {
+	Name string `msgpack:"name"`
+}
+
+func TestSetMsgPackAndMsgPackRoundTrip(t *testing.T) {
+	var gotContentType string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotContentType = r.Header.Get(requests.HeaderContentType)
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set(requests.HeaderContentType, requests.MimeMsgPack)
+		w.Write(body)
+	}))
+	defer srv.Close()
+
+	req, err := SetMsgPack(requests.NewRequest().SetURLByStr(srv.URL), greeting{Name: "gopher"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := req.Post("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotContentType != requests.MimeMsgPack {
+		t.Errorf("Content-Type = %q, want %q", gotContentType, requests.MimeMsgPack)
+	}
+
+	var out greeting
+	if err := MsgPack(resp, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Name != "gopher" {
+		t.Errorf("Name = %q, want gopher", out.Name)
+	}
+}
+
+func TestMsgPackDecodeFailureIsInformative(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("not valid msgpack"))
+	}))
+	defer srv.Close()
+
+	resp, err := requests.NewRequest().SetURLByStr(srv.URL).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out greeting
+	err = MsgPack(resp, &out)
+	if err == nil {
+		t.Fatal("want an error decoding a non-MessagePack body")
+	}
+	if got := err.Error(); got == "" {
+		t.Error("want a non-empty error message")
+	}
+}
+
+func TestResponseDecodeDispatchesToMsgPack(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(requests.HeaderContentType, requests.MimeMsgPack)
+		req, err := SetMsgPack(requests.NewRequest(), greeting{Name: "gopher"})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		rawReq, err := req.RawRequest()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		body, _ := ioutil.ReadAll(rawReq.Body)
+		w.Write(body)
+	}))
+	defer srv.Close()
+
+	resp, err := requests.NewRequest().SetURLByStr(srv.URL).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out greeting
+	if err := resp.Decode(&out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Name != "gopher" {
+		t.Errorf("Name = %q, want gopher", out.Name)
+	}
+}
@@ -0,0 +1,162 @@
+package requests
+
+import (
+	"bytes"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strings"
+	"testing"
+)
+
+func TestMultipartBoundaryDeterministic(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+
+	var bodies [][]byte
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, b)
+	})
+
+	for i := 0; i < 2; i++ {
+		_, err = NewRequest().SetURLByStr(mock.URL()).
+			SetForm("a", "1").
+			SetFile("f", "hello.txt", MimeTextPlain, bytes.NewReader([]byte("hello"))).
+			SetDeterministicMultipartBoundary().
+			Post("")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	if len(bodies) != 2 || !bytes.Equal(bodies[0], bodies[1]) {
+		t.Errorf("expected byte-identical multipart bodies, got:\n%s\n---\n%s", bodies[0], bodies[1])
+	}
+}
+
+func TestSetMultipartBoundaryInvalid(t *testing.T) {
+	r := NewRequest().SetMultipartBoundary("bad boundary ")
+	if r.multipartBoundary != "" {
+		t.Errorf("expected invalid boundary to be rejected, got %q", r.multipartBoundary)
+	}
+}
+
+func TestSetMultipartBoundaryCollision(t *testing.T) {
+	_, _, err := toMulti(paramPairs{&paramPair{Param: "a", Value: "contains-XYZ-boundary"}}, nil, nil, "XYZ")
+	if err == nil {
+		t.Error("expected collision error")
+	}
+}
+
+func TestSetMultipartBoundaryInvalidIsDeferredError(t *testing.T) {
+	mock, err := NewMock(func(err error) {})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {})
+
+	_, err = NewRequest().SetURLByStr(mock.URL()).
+		SetForm("a", "1").
+		SetMultipartBoundary("bad boundary ").
+		Post("")
+	if err == nil {
+		t.Error("expected an error from Post with an invalid multipart boundary")
+	}
+}
+
+func TestSetFilePartPassesCustomHeaders(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+
+	var gotContentID string
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, params, err := mime.ParseMediaType(r.Header.Get(HeaderContentType))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		mr := multipart.NewReader(r.Body, params["boundary"])
+		part, err := mr.NextPart()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		gotContentID = part.Header.Get("Content-ID")
+	})
+
+	headers := make(textproto.MIMEHeader)
+	headers.Set("Content-ID", "<part1>")
+	_, err = NewRequest().SetURLByStr(mock.URL()).
+		SetFilePart("f", "hello.txt", headers, bytes.NewReader([]byte("hello"))).
+		Post("")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if gotContentID != "<part1>" {
+		t.Errorf("Content-ID = %q, want <part1>", gotContentID)
+	}
+}
+
+func TestSetFileStripsCRLFFromFilename(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+
+	var gotInjected, gotFilename string
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, params, err := mime.ParseMediaType(r.Header.Get(HeaderContentType))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		mr := multipart.NewReader(r.Body, params["boundary"])
+		part, err := mr.NextPart()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		gotInjected = part.Header.Get("X-Injected")
+		_, dispParams, _ := mime.ParseMediaType(part.Header.Get("Content-Disposition"))
+		gotFilename = dispParams["filename"]
+	})
+
+	_, err = NewRequest().SetURLByStr(mock.URL()).
+		SetFile("f", "evil.txt\r\nX-Injected: pwned", MimeTextPlain, bytes.NewReader([]byte("hello"))).
+		Post("")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if gotInjected != "" {
+		t.Errorf("X-Injected header = %q, want no injected header", gotInjected)
+	}
+	if strings.ContainsAny(gotFilename, "\r\n") {
+		t.Errorf("filename = %q, want CR/LF stripped", gotFilename)
+	}
+}
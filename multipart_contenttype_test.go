@@ -0,0 +1,88 @@
+package requests
+
+import (
+	"bytes"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"strings"
+	"testing"
+)
+
+func TestToMultiPreservesContentType(t *testing.T) {
+	body, contentType, err := toMulti(nil, multiFiles{
+		{Param: "f", FileName: "hello.png", ContentType: "image/png", Reader: bytes.NewReader([]byte("fake-png-bytes"))},
+	}, nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mr := multipart.NewReader(body, params["boundary"])
+	part, err := mr.NextPart()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := part.Header.Get(HeaderContentType); got != "image/png" {
+		t.Errorf("part Content-Type = %q, want %q", got, "image/png")
+	}
+	data, err := ioutil.ReadAll(part)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "fake-png-bytes" {
+		t.Errorf("part body = %q, want %q", data, "fake-png-bytes")
+	}
+}
+
+func TestToMultiDefaultsContentTypeWhenEmpty(t *testing.T) {
+	body, contentType, err := toMulti(nil, multiFiles{
+		{Param: "f", FileName: "blob.bin", ContentType: "", Reader: bytes.NewReader([]byte("data"))},
+	}, nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mr := multipart.NewReader(body, params["boundary"])
+	part, err := mr.NextPart()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := part.Header.Get(HeaderContentType); got != "application/octet-stream" {
+		t.Errorf("part Content-Type = %q, want %q", got, "application/octet-stream")
+	}
+}
+
+func TestToMultiEscapesFileName(t *testing.T) {
+	names := []string{
+		`quote "and" backslash \.txt`,
+		"résumé 名前 🎉.png",
+	}
+	for _, name := range names {
+		body, contentType, err := toMulti(nil, multiFiles{
+			{Param: "f", FileName: name, ContentType: "text/plain", Reader: strings.NewReader("x")},
+		}, nil, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, params, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mr := multipart.NewReader(body, params["boundary"])
+		part, err := mr.NextPart()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if part.FileName() != name {
+			t.Errorf("FileName() = %q, want %q", part.FileName(), name)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package requests
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// SetQueryIfNotEmpty sets the query parameter param to value, like
+// SetQuery, but only if value is non-empty -- skipping it lets fluent
+// chains build optional filters without wrapping every SetQuery call in
+// an if. To deliberately send an empty value, use SetQuery.
+func (r *Request) SetQueryIfNotEmpty(param, value string) *Request {
+	if value == "" {
+		return r
+	}
+	return r.SetQuery(param, value)
+}
+
+// SetFormIfNotEmpty is SetQueryIfNotEmpty for form parameters: it sets
+// param to value via SetForm, skipping it if value is empty.
+func (r *Request) SetFormIfNotEmpty(param, value string) *Request {
+	if value == "" {
+		return r
+	}
+	return r.SetForm(param, value)
+}
+
+// SetQueryOmitZero sets the query parameter param to v formatted as a
+// string, skipping it entirely if v is the zero value for its type: an
+// empty string, 0, false, a nil pointer, or a zero time.Time. Supported
+// types are the scalars SetFormStruct also handles -- string, integer,
+// float, bool, time.Time, and pointers to any of those -- formatted with
+// strconv; anything else falls back to fmt.Sprint and is never treated as
+// zero. To deliberately send a zero value, use SetQuery.
+func (r *Request) SetQueryOmitZero(param string, v interface{}) *Request {
+	value, isZero := formatOmitZero(reflect.ValueOf(v))
+	if isZero {
+		return r
+	}
+	return r.SetQuery(param, value)
+}
+
+func formatOmitZero(fv reflect.Value) (value string, isZero bool) {
+	for fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			return "", true
+		}
+		fv = fv.Elem()
+	}
+	if !fv.IsValid() {
+		return "", true
+	}
+
+	if t, ok := fv.Interface().(time.Time); ok {
+		return t.Format(time.RFC3339), t.IsZero()
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		return fv.String(), fv.String() == ""
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(fv.Int(), 10), fv.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(fv.Uint(), 10), fv.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(fv.Float(), 'f', -1, 64), fv.Float() == 0
+	case reflect.Bool:
+		return strconv.FormatBool(fv.Bool()), !fv.Bool()
+	default:
+		return fmt.Sprint(fv.Interface()), false
+	}
+}
@@ -0,0 +1,81 @@
+package requests
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSetQueryIfNotEmptySkipsEmptyValueButSetQueryStillAllowsIt(t *testing.T) {
+	req := NewRequest().SetQueryIfNotEmpty("q", "")
+	if _, ok := req.queryParam.Search("q"); ok {
+		t.Errorf("SetQueryIfNotEmpty(\"q\", \"\") should not set the parameter")
+	}
+
+	req = NewRequest().SetQuery("q", "")
+	pp, ok := req.queryParam.Search("q")
+	if !ok || pp.Value != "" {
+		t.Errorf("SetQuery(\"q\", \"\") = (%v, %v), want (\"\", true)", pp, ok)
+	}
+
+	req = NewRequest().SetQueryIfNotEmpty("q", "v")
+	pp, ok = req.queryParam.Search("q")
+	if !ok || pp.Value != "v" {
+		t.Errorf("SetQueryIfNotEmpty(\"q\", \"v\") = (%v, %v), want (\"v\", true)", pp, ok)
+	}
+}
+
+func TestSetFormIfNotEmptySkipsEmptyValueButSetFormStillAllowsIt(t *testing.T) {
+	req := NewRequest().SetFormIfNotEmpty("f", "")
+	if _, ok := req.formParam.Search("f"); ok {
+		t.Errorf("SetFormIfNotEmpty(\"f\", \"\") should not set the parameter")
+	}
+
+	req = NewRequest().SetForm("f", "")
+	pp, ok := req.formParam.Search("f")
+	if !ok || pp.Value != "" {
+		t.Errorf("SetForm(\"f\", \"\") = (%v, %v), want (\"\", true)", pp, ok)
+	}
+
+	req = NewRequest().SetFormIfNotEmpty("f", "v")
+	pp, ok = req.formParam.Search("f")
+	if !ok || pp.Value != "v" {
+		t.Errorf("SetFormIfNotEmpty(\"f\", \"v\") = (%v, %v), want (\"v\", true)", pp, ok)
+	}
+}
+
+func TestSetQueryOmitZeroSkipsZeroValuesOfEachSupportedType(t *testing.T) {
+	var nilPtr *int
+	cases := []interface{}{
+		"", 0, int64(0), uint(0), 0.0, false, time.Time{}, nilPtr, nil,
+	}
+	for _, v := range cases {
+		req := NewRequest().SetQueryOmitZero("p", v)
+		if _, ok := req.queryParam.Search("p"); ok {
+			t.Errorf("SetQueryOmitZero(\"p\", %#v) should not set the parameter", v)
+		}
+	}
+}
+
+func TestSetQueryOmitZeroFormatsNonZeroValues(t *testing.T) {
+	n := 5
+	when := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	cases := []struct {
+		v    interface{}
+		want string
+	}{
+		{"x", "x"},
+		{42, "42"},
+		{uint(7), "7"},
+		{1.5, "1.5"},
+		{true, "true"},
+		{&n, "5"},
+		{when, when.Format(time.RFC3339)},
+	}
+	for _, c := range cases {
+		req := NewRequest().SetQueryOmitZero("p", c.v)
+		pp, ok := req.queryParam.Search("p")
+		if !ok || pp.Value != c.want {
+			t.Errorf("SetQueryOmitZero(\"p\", %#v) = (%v, %v), want (%q, true)", c.v, pp, ok, c.want)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package requests
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Option configures a one-shot request built by Client.GetX, PostX, PutX
+// or DeleteX. Each Option is a small adapter over an existing Request
+// setter, so the builder chain stays the single source of truth for how a
+// request is actually put together; Options just give a one-off call a
+// functional-options entry point instead of a template to build by hand.
+// Options are plain values holding no per-call state of their own, so the
+// same Option (e.g. a WithHeader for an API key) is safe to reuse across
+// many calls and goroutines.
+type Option func(*optionRequest)
+
+// optionRequest is the state an Option closes over: the Request being
+// built, plus anything -- like WithResult's target -- that needs to act
+// after the response comes back rather than while the request is built.
+type optionRequest struct {
+	req    *Request
+	result interface{}
+}
+
+// WithQuery sets a query parameter, like Request.SetQuery.
+func WithQuery(param, value string) Option {
+	return func(o *optionRequest) { o.req.SetQuery(param, value) }
+}
+
+// WithHeader sets a header field, like Request.SetHeader.
+func WithHeader(param, value string) Option {
+	return func(o *optionRequest) { o.req.SetHeader(param, value) }
+}
+
+// WithJSON sets the request body to the JSON encoding of v, like
+// Request.SetJSON.
+func WithJSON(v interface{}) Option {
+	return func(o *optionRequest) { o.req.SetJSON(v) }
+}
+
+// WithTimeout sets a per-request deadline d from now, like
+// Request.SetTimeout.
+func WithTimeout(d time.Duration) Option {
+	return func(o *optionRequest) { o.req.SetTimeout(d) }
+}
+
+// WithResult arranges for the response body to be JSON-decoded into out
+// once the request succeeds, so a one-shot call doesn't need a separate
+// json.Unmarshal(resp.Body(), out) line of its own. out must be a
+// non-nil pointer.
+func WithResult(out interface{}) Option {
+	return func(o *optionRequest) { o.result = out }
+}
+
+// doX builds a Request for method and url, applies opts, sends it and, if
+// a WithResult option was given, decodes the response body into its
+// target before returning.
+func (c *Client) doX(ctx context.Context, method, url string, opts []Option) (*Response, error) {
+	o := &optionRequest{req: c.NewRequest().SetContext(ctx).SetMethod(method).SetURLByStr(url)}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	resp, err := o.req.Do()
+	if err != nil {
+		return resp, err
+	}
+	if o.result != nil {
+		if err := json.Unmarshal(resp.Body(), o.result); err != nil {
+			return resp, err
+		}
+	}
+	return resp, nil
+}
+
+// GetX performs a one-shot GET request configured with opts, e.g.
+// c.GetX(ctx, "/users/1", WithQuery("verbose", "true"), WithResult(&user)).
+func (c *Client) GetX(ctx context.Context, url string, opts ...Option) (*Response, error) {
+	return c.doX(ctx, MethodGet, url, opts)
+}
+
+// PostX performs a one-shot POST request configured with opts.
+func (c *Client) PostX(ctx context.Context, url string, opts ...Option) (*Response, error) {
+	return c.doX(ctx, MethodPost, url, opts)
+}
+
+// PutX performs a one-shot PUT request configured with opts.
+func (c *Client) PutX(ctx context.Context, url string, opts ...Option) (*Response, error) {
+	return c.doX(ctx, MethodPut, url, opts)
+}
+
+// DeleteX performs a one-shot DELETE request configured with opts.
+func (c *Client) DeleteX(ctx context.Context, url string, opts ...Option) (*Response, error) {
+	return c.doX(ctx, MethodDelete, url, opts)
+}
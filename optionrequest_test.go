@@ -0,0 +1,163 @@
+package requests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type optionRequestTestUser struct {
+	Name string `json:"name"`
+}
+
+// recordedRequest is the part of an incoming *http.Request this file's
+// tests compare between the builder form and the GetX/PostX form.
+type recordedRequest struct {
+	method string
+	query  string
+	header string
+	body   string
+}
+
+func recordRequest(mock *Mock, path string) *recordedRequest {
+	rec := &recordedRequest{}
+	mock.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		rec.method = r.Method
+		rec.query = r.URL.RawQuery
+		rec.header = r.Header.Get("X-Api-Key")
+		body, _ := ioutil.ReadAll(r.Body)
+		rec.body = string(body)
+		w.Write([]byte(`{"name":"ada"}`))
+	})
+	return rec
+}
+
+func TestGetXMatchesEquivalentBuilderRequest(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	builderRec := recordRequest(mock, "/builder")
+	optionRec := recordRequest(mock, "/option")
+
+	client := NewClient()
+	if _, err := client.NewRequest().
+		SetQuery("verbose", "true").
+		SetHeader("X-Api-Key", "secret").
+		Get(mock.URL() + "/builder"); err != nil {
+		t.Fatal(err)
+	}
+
+	var user optionRequestTestUser
+	if _, err := client.GetX(context.Background(), mock.URL()+"/option",
+		WithQuery("verbose", "true"),
+		WithHeader("X-Api-Key", "secret"),
+		WithResult(&user),
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	if *optionRec != *builderRec {
+		t.Errorf("GetX request = %+v, want it to match the builder request %+v", optionRec, builderRec)
+	}
+	if user.Name != "ada" {
+		t.Errorf("WithResult decoded Name = %q, want %q", user.Name, "ada")
+	}
+}
+
+func TestPostXMatchesEquivalentBuilderRequest(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	builderRec := recordRequest(mock, "/builder")
+	optionRec := recordRequest(mock, "/option")
+
+	payload := optionRequestTestUser{Name: "lovelace"}
+
+	client := NewClient()
+	if _, err := client.NewRequest().SetJSON(payload).Post(mock.URL() + "/builder"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.PostX(context.Background(), mock.URL()+"/option", WithJSON(payload)); err != nil {
+		t.Fatal(err)
+	}
+
+	if *optionRec != *builderRec {
+		t.Errorf("PostX request = %+v, want it to match the builder request %+v", optionRec, builderRec)
+	}
+}
+
+func TestWithOptionsAreReusableAcrossCalls(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	var gotKeys []string
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		gotKeys = append(gotKeys, r.Header.Get("X-Api-Key"))
+	})
+
+	client := NewClient()
+	apiKey := WithHeader("X-Api-Key", "shared-secret")
+
+	if _, err := client.GetX(context.Background(), mock.URL()+"/", apiKey); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetX(context.Background(), mock.URL()+"/", apiKey); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(gotKeys) != 2 || gotKeys[0] != "shared-secret" || gotKeys[1] != "shared-secret" {
+		t.Errorf("gotKeys = %v, want the same header both times", gotKeys)
+	}
+}
+
+func TestWithTimeoutAppliesToOneShotRequest(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	client := NewClient()
+	_, err = client.GetX(context.Background(), mock.URL()+"/", WithTimeout(time.Millisecond))
+	if err == nil {
+		t.Fatal("expected a deadline-exceeded error, got nil")
+	}
+}
+
+func TestWithResultErrorPropagatesJSONDecodeFailure(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("not json"))
+	})
+
+	client := NewClient()
+	var out optionRequestTestUser
+	_, err = client.GetX(context.Background(), mock.URL()+"/", WithResult(&out))
+	if err == nil {
+		t.Fatal("expected a JSON decode error, got nil")
+	}
+	var jsonErr *json.SyntaxError
+	if !errors.As(err, &jsonErr) {
+		t.Errorf("err = %v, want a json.SyntaxError", err)
+	}
+}
@@ -0,0 +1,129 @@
+package requests
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestSetPathsBulk(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+
+	var gotPath string
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+	})
+
+	_, err = NewRequest().SetURLByStr(mock.URL() + "/{a}/{b}").
+		SetPaths(map[string]string{"a": "1", "b": "2"}).
+		Get("")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if gotPath != "/1/2" {
+		t.Errorf("path = %q, want /1/2", gotPath)
+	}
+}
+
+func TestSetPathsEscaped(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+
+	var gotPath string
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+	})
+
+	_, err = NewRequest().SetURLByStr(mock.URL() + "/{id}").
+		SetPathsEscaped(map[string]string{"id": "a/b"}).
+		Get("")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if gotPath != "/a/b" {
+		t.Errorf("path = %q, want /a/b (single escaped segment, decoded back by the server)", gotPath)
+	}
+}
+
+func TestSetPathEscapedHandlesSpacesAndUnicode(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+
+	var gotPath string
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+	})
+
+	_, err = NewRequest().SetURLByStr(mock.URL()+"/{id}").
+		SetPathEscaped("id", "hello world/café").
+		Get("")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if gotPath != "/hello world/café" {
+		t.Errorf("path = %q, want /hello world/café (single segment, decoded back by the server)", gotPath)
+	}
+}
+
+func TestSetPathStrictRejectsStructuralCharacters(t *testing.T) {
+	req := NewRequest().SetQuiet().SetURLByStr("http://example.invalid/{id}").
+		SetPathStrict("id", "a/b")
+	if req.deferredErr == nil {
+		t.Error("want a deferred error for a strict path value containing '/'")
+	}
+
+	_, err := NewRequest().SetQuiet().SetURLByStr("http://example.invalid/{id}").
+		SetPathStrict("id", "a?b").
+		Get("")
+	if err == nil {
+		t.Error("want Do to return an error for a strict path value containing '?'")
+	}
+}
+
+func TestSetPathStrictAllowsOrdinaryValues(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+
+	var gotPath string
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+	})
+
+	_, err = NewRequest().SetURLByStr(mock.URL()+"/{id}").
+		SetPathStrict("id", "hello world").
+		Get("")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if gotPath != "/hello world" {
+		t.Errorf("path = %q, want /hello world", gotPath)
+	}
+}
@@ -0,0 +1,98 @@
+package requests
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+type payloadPerson struct {
+	Name string `json:"name" xml:"name" form:"name"`
+	Age  int    `json:"age" xml:"age" form:"age"`
+}
+
+func TestSetPayloadChoosesEncoderFromContentType(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	var gotJSON payloadPerson
+	mock.HandleFunc("/json", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &gotJSON); err != nil {
+			t.Error(err)
+		}
+	})
+	var gotXML payloadPerson
+	mock.HandleFunc("/xml", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if err := xml.Unmarshal(body, &gotXML); err != nil {
+			t.Error(err)
+		}
+	})
+
+	template := NewRequest().SetURLByStr(mock.URL()).SetPayload(payloadPerson{Name: "Ada", Age: 30})
+
+	if _, err := template.Clone().Post("/json"); err != nil {
+		t.Fatal(err)
+	}
+	if gotJSON != (payloadPerson{Name: "Ada", Age: 30}) {
+		t.Errorf("json route got %+v", gotJSON)
+	}
+
+	if _, err := template.Clone().SetContentType(MimeXML).Post("/xml"); err != nil {
+		t.Fatal(err)
+	}
+	if gotXML.Name != "Ada" || gotXML.Age != 30 {
+		t.Errorf("xml route got %+v", gotXML)
+	}
+}
+
+func TestSetPayloadYAMLAndForm(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	var gotYAML string
+	mock.HandleFunc("/yaml", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		gotYAML = string(body)
+	})
+	var gotForm string
+	mock.HandleFunc("/form", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		gotForm = r.Form.Get("name") + "/" + r.Form.Get("age")
+	})
+
+	p := payloadPerson{Name: "Ada", Age: 30}
+	if _, err := NewRequest().SetURLByStr(mock.URL()).SetContentType(MimeYAML).SetPayload(p).Post("/yaml"); err != nil {
+		t.Fatal(err)
+	}
+	if gotYAML == "" {
+		t.Error("expected non-empty YAML body")
+	}
+
+	if _, err := NewRequest().SetURLByStr(mock.URL()).SetContentType(MimeURLEncoded).SetPayload(p).Post("/form"); err != nil {
+		t.Fatal(err)
+	}
+	if gotForm != "Ada/30" {
+		t.Errorf("form route got %q, want %q", gotForm, "Ada/30")
+	}
+}
+
+func TestSetPayloadUnsupportedContentType(t *testing.T) {
+	_, err := NewRequest().SetQuiet().SetContentType("application/vnd.custom").SetPayload(payloadPerson{}).RawRequest()
+	if err == nil {
+		t.Fatal("RawRequest() = nil error, want one naming the unsupported content type")
+	}
+}
@@ -0,0 +1,42 @@
+package pb
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/wzshiming/requests"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestSetProtobufAndProtobufRoundTrip(t *testing.T) {
+	var gotContentType string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotContentType = r.Header.Get(requests.HeaderContentType)
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set(requests.HeaderContentType, requests.MimeProtobuf)
+		w.Write(body)
+	}))
+	defer srv.Close()
+
+	req, err := SetProtobuf(requests.NewRequest().SetURLByStr(srv.URL), wrapperspb.String("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := req.Post("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotContentType != requests.MimeProtobuf {
+		t.Errorf("Content-Type = %q, want %q", gotContentType, requests.MimeProtobuf)
+	}
+
+	var out wrapperspb.StringValue
+	if err := Protobuf(resp, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Value != "hello" {
+		t.Errorf("Value = %q, want hello", out.Value)
+	}
+}
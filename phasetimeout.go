@@ -0,0 +1,146 @@
+package requests
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"sync/atomic"
+	"time"
+)
+
+type phaseTimeoutContextKeyType struct{}
+
+var phaseTimeoutContextKey = phaseTimeoutContextKeyType{}
+
+// phaseTimeouts holds the two independent phase deadlines
+// Request.SetHeaderTimeout and SetBodyIdleTimeout stash in the request's
+// context: time to first response byte, and time between body reads.
+// Zero means that phase isn't bounded.
+type phaseTimeouts struct {
+	header   time.Duration
+	bodyIdle time.Duration
+}
+
+// ErrHeaderTimeout is returned when a response's headers don't arrive
+// within Request.SetHeaderTimeout, distinct from a whole-request timeout
+// (SetTimeout/SetDeadline) or a body stall (ErrBodyIdleTimeout).
+type ErrHeaderTimeout struct {
+	Timeout time.Duration
+}
+
+func (e *ErrHeaderTimeout) Error() string {
+	return fmt.Sprintf("requests: no response headers within %s", e.Timeout)
+}
+
+// ErrBodyIdleTimeout is returned when no response body bytes arrive for
+// Request.SetBodyIdleTimeout, distinct from a header timeout
+// (ErrHeaderTimeout) or a whole-request timeout.
+type ErrBodyIdleTimeout struct {
+	Timeout time.Duration
+}
+
+func (e *ErrBodyIdleTimeout) Error() string {
+	return fmt.Sprintf("requests: no response body bytes for %s", e.Timeout)
+}
+
+// phaseTimeoutRoundTripper wraps an http.RoundTripper, enforcing the
+// *phaseTimeouts stashed in a request's context by Request.RawRequest.
+// The header phase is bounded with an httptrace hook that disarms the
+// timer on the first response byte; the body phase is bounded by
+// resetting a timer on every body Read, so only the time between reads --
+// not the total download -- counts against it.
+type phaseTimeoutRoundTripper struct {
+	next http.RoundTripper
+}
+
+func (t *phaseTimeoutRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	pt, _ := req.Context().Value(phaseTimeoutContextKey).(*phaseTimeouts)
+	if pt == nil {
+		return t.next.RoundTrip(req)
+	}
+
+	ctx, cancel := context.WithCancel(req.Context())
+
+	var headerTimedOut int32
+	var headerTimer *time.Timer
+	if pt.header > 0 {
+		headerTimer = time.AfterFunc(pt.header, func() {
+			atomic.StoreInt32(&headerTimedOut, 1)
+			cancel()
+		})
+		ctx = httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+			GotFirstResponseByte: func() { headerTimer.Stop() },
+		})
+	}
+
+	resp, err := t.next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		if atomic.LoadInt32(&headerTimedOut) == 1 {
+			return nil, &ErrHeaderTimeout{Timeout: pt.header}
+		}
+		return nil, err
+	}
+	if headerTimer != nil {
+		headerTimer.Stop()
+	}
+
+	body := &phaseTimeoutBody{ReadCloser: resp.Body, cancel: cancel}
+	if pt.bodyIdle > 0 {
+		var bodyTimedOut int32
+		body.d = pt.bodyIdle
+		body.timedOut = &bodyTimedOut
+		body.timer = time.AfterFunc(pt.bodyIdle, func() {
+			atomic.StoreInt32(&bodyTimedOut, 1)
+			cancel()
+		})
+	}
+	resp.Body = body
+	return resp, nil
+}
+
+// phaseTimeoutBody wraps a response body, resetting its idle timer (if
+// any) on every read so only gaps between reads count against it, and
+// releasing the RoundTrip-scoped context on Close.
+type phaseTimeoutBody struct {
+	io.ReadCloser
+	timer    *time.Timer // nil if no body idle timeout was set
+	d        time.Duration
+	timedOut *int32
+	cancel   context.CancelFunc
+}
+
+func (b *phaseTimeoutBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if b.timer != nil && n > 0 {
+		b.timer.Reset(b.d)
+	}
+	if err != nil && b.timedOut != nil && atomic.LoadInt32(b.timedOut) == 1 {
+		return n, &ErrBodyIdleTimeout{Timeout: b.d}
+	}
+	return n, err
+}
+
+func (b *phaseTimeoutBody) Close() error {
+	if b.timer != nil {
+		b.timer.Stop()
+	}
+	b.cancel()
+	return b.ReadCloser.Close()
+}
+
+// ensurePhaseTimeoutTransport wraps the client's transport with a
+// phaseTimeoutRoundTripper the first time any request sets a header or
+// body-idle timeout. Idempotent, like ensureDebugTransport.
+func (c *Client) ensurePhaseTimeoutTransport() error {
+	if _, ok := c.cli.Transport.(*phaseTimeoutRoundTripper); ok {
+		return nil
+	}
+	if _, err := c.getTransport(); err != nil {
+		return err
+	}
+	c.cli.Transport = &phaseTimeoutRoundTripper{next: c.cli.Transport}
+	return nil
+}
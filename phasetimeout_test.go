@@ -0,0 +1,104 @@
+package requests
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestSetHeaderTimeoutFiresOnSlowHeaders(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		w.Write([]byte("late"))
+	})
+
+	_, err = NewClient().NewRequest().SetQuiet().SetURLByStr(mock.URL()).SetHeaderTimeout(20 * time.Millisecond).Get("/")
+	if err == nil {
+		t.Fatal("expected an error for slow headers")
+	}
+	var headerErr *ErrHeaderTimeout
+	if !errors.As(err, &headerErr) {
+		t.Errorf("err = %#v, want one wrapping *ErrHeaderTimeout", err)
+	}
+}
+
+func TestSetHeaderTimeoutPassesWithFastHeaders(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("fast"))
+	})
+
+	resp, err := NewClient().NewRequest().SetURLByStr(mock.URL()).SetHeaderTimeout(500 * time.Millisecond).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(resp.Body()) != "fast" {
+		t.Errorf("Body() = %q, want %q", resp.Body(), "fast")
+	}
+}
+
+func TestSetBodyIdleTimeoutFiresOnStalledBody(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		flusher := w.(http.Flusher)
+		w.Write([]byte("chunk1"))
+		flusher.Flush()
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte("chunk2"))
+	})
+
+	_, err = NewClient().NewRequest().SetQuiet().SetURLByStr(mock.URL()).SetBodyIdleTimeout(30 * time.Millisecond).Get("/")
+	if err == nil {
+		t.Fatal("expected an error for a stalled body")
+	}
+	var bodyErr *ErrBodyIdleTimeout
+	if !errors.As(err, &bodyErr) {
+		t.Errorf("err = %#v, want one wrapping *ErrBodyIdleTimeout", err)
+	}
+}
+
+func TestSetBodyIdleTimeoutPassesWithSteadyBody(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		flusher := w.(http.Flusher)
+		for i := 0; i < 3; i++ {
+			w.Write([]byte("x"))
+			flusher.Flush()
+			time.Sleep(5 * time.Millisecond)
+		}
+	})
+
+	resp, err := NewClient().NewRequest().SetURLByStr(mock.URL()).SetBodyIdleTimeout(200 * time.Millisecond).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(resp.Body()) != "xxx" {
+		t.Errorf("Body() = %q, want %q", resp.Body(), "xxx")
+	}
+}
@@ -0,0 +1,102 @@
+package requests
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	presignExpiresParam   = "expires"
+	presignSignatureParam = "signature"
+)
+
+// ErrPresignedURLInvalidSignature is returned by VerifyPresignedURL when
+// the URL's signature parameter doesn't match an HMAC-SHA256 of the rest
+// of the URL under the given secret, whether because it's missing,
+// tampered with, or signed with a different secret.
+type ErrPresignedURLInvalidSignature struct {
+	URL *url.URL
+}
+
+func (e *ErrPresignedURLInvalidSignature) Error() string {
+	return fmt.Sprintf("requests: presigned URL signature invalid or missing: %s", e.URL)
+}
+
+// ErrPresignedURLExpired is returned by VerifyPresignedURL when now is
+// past the URL's expires parameter plus the allowed clock-skew
+// tolerance.
+type ErrPresignedURLExpired struct {
+	Expires time.Time
+	Now     time.Time
+}
+
+func (e *ErrPresignedURLExpired) Error() string {
+	return fmt.Sprintf("requests: presigned URL expired at %s, now is %s", e.Expires.Format(time.RFC3339), e.Now.Format(time.RFC3339))
+}
+
+// PresignURL returns this request's fully-resolved URL (path parameters
+// substituted, query encoded, the same way RawRequest builds one) with
+// "expires" and "signature" query parameters appended, so the result
+// can be handed to a browser or other client as a time-limited GET link
+// without it holding secret. The signature is an HMAC-SHA256, hex
+// encoded, over the URL with the signature parameter itself excluded;
+// verify it server-side with VerifyPresignedURL using the same secret.
+func (r *Request) PresignURL(secret []byte, ttl time.Duration) (string, error) {
+	u, err := r.processURL()
+	if err != nil {
+		return "", err
+	}
+
+	q := u.Query()
+	q.Set(presignExpiresParam, strconv.FormatInt(time.Now().Add(ttl).Unix(), 10))
+	u.RawQuery = q.Encode()
+
+	q.Set(presignSignatureParam, presignSignature(u, secret))
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// presignSignature computes the HMAC-SHA256 (hex encoded) of u under
+// secret, with u's signature parameter, if any, excluded from what's
+// signed.
+func presignSignature(u *url.URL, secret []byte) string {
+	canonical := *u
+	q := canonical.Query()
+	q.Del(presignSignatureParam)
+	canonical.RawQuery = q.Encode()
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(canonical.String()))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyPresignedURL checks a URL produced by Request.PresignURL: that
+// its signature parameter matches an HMAC-SHA256 of the rest of the URL
+// under secret, compared in constant time, and that its expires
+// parameter has not passed as of now, allowing skew of clock drift
+// between the signer and this check (0 to require an exact check
+// against now).
+func VerifyPresignedURL(u *url.URL, secret []byte, now time.Time, skew time.Duration) error {
+	q := u.Query()
+
+	got := q.Get(presignSignatureParam)
+	want := presignSignature(u, secret)
+	if got == "" || !hmac.Equal([]byte(got), []byte(want)) {
+		return &ErrPresignedURLInvalidSignature{URL: u}
+	}
+
+	expires, err := strconv.ParseInt(q.Get(presignExpiresParam), 10, 64)
+	if err != nil {
+		return fmt.Errorf("requests: presigned URL has a missing or invalid %q parameter: %w", presignExpiresParam, err)
+	}
+	expiresAt := time.Unix(expires, 0)
+	if now.After(expiresAt.Add(skew)) {
+		return &ErrPresignedURLExpired{Expires: expiresAt, Now: now}
+	}
+	return nil
+}
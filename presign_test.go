@@ -0,0 +1,97 @@
+package requests
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestPresignURLRoundTrip(t *testing.T) {
+	secret := []byte("s3cr3t")
+	raw, err := NewRequest().SetURLByStr("https://example.com/files/report.pdf?download=1").PresignURL(secret, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Query().Get("signature") == "" {
+		t.Fatal("expected a signature parameter")
+	}
+	if u.Query().Get("download") != "1" {
+		t.Errorf("download = %q, want %q (existing query params preserved)", u.Query().Get("download"), "1")
+	}
+
+	if err := VerifyPresignedURL(u, secret, time.Now(), 0); err != nil {
+		t.Errorf("VerifyPresignedURL() = %v, want nil", err)
+	}
+}
+
+func TestVerifyPresignedURLWrongSecret(t *testing.T) {
+	raw, err := NewRequest().SetURLByStr("https://example.com/report").PresignURL([]byte("correct"), time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, _ := url.Parse(raw)
+
+	err = VerifyPresignedURL(u, []byte("wrong"), time.Now(), 0)
+	if _, ok := err.(*ErrPresignedURLInvalidSignature); !ok {
+		t.Fatalf("err = %v (%T), want *ErrPresignedURLInvalidSignature", err, err)
+	}
+}
+
+func TestVerifyPresignedURLTamperedQuery(t *testing.T) {
+	secret := []byte("s3cr3t")
+	raw, err := NewRequest().SetURLByStr("https://example.com/report?id=1").PresignURL(secret, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, _ := url.Parse(raw)
+
+	q := u.Query()
+	q.Set("id", "2")
+	u.RawQuery = q.Encode()
+
+	err = VerifyPresignedURL(u, secret, time.Now(), 0)
+	if _, ok := err.(*ErrPresignedURLInvalidSignature); !ok {
+		t.Fatalf("err = %v (%T), want *ErrPresignedURLInvalidSignature", err, err)
+	}
+}
+
+func TestVerifyPresignedURLMissingSignature(t *testing.T) {
+	u, err := url.Parse("https://example.com/report?expires=9999999999")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = VerifyPresignedURL(u, []byte("s3cr3t"), time.Now(), 0)
+	if _, ok := err.(*ErrPresignedURLInvalidSignature); !ok {
+		t.Fatalf("err = %v (%T), want *ErrPresignedURLInvalidSignature", err, err)
+	}
+}
+
+func TestVerifyPresignedURLExpiryBoundary(t *testing.T) {
+	secret := []byte("s3cr3t")
+	raw, err := NewRequest().SetURLByStr("https://example.com/report").PresignURL(secret, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, _ := url.Parse(raw)
+
+	// Just before expiry: fine.
+	if err := VerifyPresignedURL(u, secret, time.Now().Add(59*time.Second), 0); err != nil {
+		t.Errorf("VerifyPresignedURL() just before expiry = %v, want nil", err)
+	}
+
+	// Just after expiry: rejected.
+	err = VerifyPresignedURL(u, secret, time.Now().Add(61*time.Second), 0)
+	if _, ok := err.(*ErrPresignedURLExpired); !ok {
+		t.Fatalf("err = %v (%T), want *ErrPresignedURLExpired", err, err)
+	}
+
+	// Just after expiry, but within the allowed clock-skew tolerance: fine.
+	if err := VerifyPresignedURL(u, secret, time.Now().Add(61*time.Second), 5*time.Second); err != nil {
+		t.Errorf("VerifyPresignedURL() within skew tolerance = %v, want nil", err)
+	}
+}
@@ -0,0 +1,187 @@
+package requests
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ClientProfile is a serializable snapshot of a Client's configurable
+// surface, suitable for persisting named environments (prod, staging,
+// local) and re-applying them later. TLS material is referenced by file
+// path rather than embedded, and header/query values may use "${VAR}"
+// placeholders expanded from the environment by ApplyProfile so secrets
+// never need to be stored in the profile itself.
+type ClientProfile struct {
+	BaseURL  string            `json:"base_url,omitempty" yaml:"base_url,omitempty"`
+	Headers  map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Queries  map[string]string `json:"queries,omitempty" yaml:"queries,omitempty"`
+	Timeout  time.Duration     `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	ProxyURL string            `json:"proxy_url,omitempty" yaml:"proxy_url,omitempty"`
+	LogLevel logLevel          `json:"log_level,omitempty" yaml:"log_level,omitempty"`
+
+	TLSCertFile string `json:"tls_cert_file,omitempty" yaml:"tls_cert_file,omitempty"`
+	TLSKeyFile  string `json:"tls_key_file,omitempty" yaml:"tls_key_file,omitempty"`
+	TLSCAFile   string `json:"tls_ca_file,omitempty" yaml:"tls_ca_file,omitempty"`
+	SkipVerify  bool   `json:"skip_verify,omitempty" yaml:"skip_verify,omitempty"`
+}
+
+// ValidationError collects every invalid field found while applying a
+// ClientProfile, instead of stopping at the first one.
+type ValidationError struct {
+	Fields map[string]error
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Fields))
+	for field, err := range e.Fields {
+		parts = append(parts, fmt.Sprintf("%s: %v", field, err))
+	}
+	return "requests: invalid profile: " + strings.Join(parts, "; ")
+}
+
+// Profile captures the current configuration of c as a ClientProfile.
+func (c *Client) Profile() ClientProfile {
+	p := ClientProfile{
+		Timeout:  c.cli.Timeout,
+		LogLevel: c.logLevel,
+		Headers:  c.defaultHeaders,
+		Queries:  c.defaultQueries,
+	}
+	if c.baseURL != nil {
+		p.BaseURL = c.baseURL.String()
+	}
+	if c.proxy != nil {
+		p.ProxyURL = c.proxy.String()
+	}
+	if transport, err := c.getTransport(); err == nil && transport.TLSClientConfig != nil {
+		p.SkipVerify = transport.TLSClientConfig.InsecureSkipVerify
+	}
+	return p
+}
+
+// ApplyProfile configures c from p, expanding "${VAR}" placeholders in
+// header, query and proxy values from the environment. It returns a
+// *ValidationError naming every invalid field rather than stopping at the
+// first one.
+func (c *Client) ApplyProfile(p ClientProfile) error {
+	errs := map[string]error{}
+
+	if p.BaseURL != "" {
+		u, err := url.Parse(os.ExpandEnv(p.BaseURL))
+		if err != nil {
+			errs["base_url"] = err
+		} else {
+			c.SetBaseURL(u)
+		}
+	}
+
+	if len(p.Headers) > 0 {
+		headers := make(map[string]string, len(p.Headers))
+		for k, v := range p.Headers {
+			headers[k] = os.ExpandEnv(v)
+		}
+		c.SetDefaultHeaders(headers)
+	}
+
+	if len(p.Queries) > 0 {
+		queries := make(map[string]string, len(p.Queries))
+		for k, v := range p.Queries {
+			queries[k] = os.ExpandEnv(v)
+		}
+		c.SetDefaultQueries(queries)
+	}
+
+	if p.Timeout > 0 {
+		c.SetTimeout(p.Timeout)
+	}
+
+	if p.ProxyURL != "" {
+		u, err := url.Parse(os.ExpandEnv(p.ProxyURL))
+		if err != nil {
+			errs["proxy_url"] = err
+		} else {
+			c.SetProxyURL(u)
+		}
+	}
+
+	if p.LogLevel != LogIgnore {
+		c.SetLogLevel(p.LogLevel)
+	}
+
+	c.SetSkipVerify(p.SkipVerify)
+
+	if p.TLSCertFile != "" || p.TLSKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(p.TLSCertFile, p.TLSKeyFile)
+		if err != nil {
+			errs["tls_cert_file"] = err
+		} else {
+			err := c.reconfigureTLSConfig(func(config *tls.Config) {
+				config.Certificates = append(config.Certificates, cert)
+			})
+			if err != nil {
+				errs["tls_cert_file"] = err
+			}
+		}
+	}
+
+	if p.TLSCAFile != "" {
+		pem, err := ioutil.ReadFile(p.TLSCAFile)
+		if err != nil {
+			errs["tls_ca_file"] = err
+		} else {
+			var appendErr error
+			err := c.reconfigureTLSConfig(func(config *tls.Config) {
+				if config.RootCAs == nil {
+					config.RootCAs = x509.NewCertPool()
+				}
+				if !config.RootCAs.AppendCertsFromPEM(pem) {
+					appendErr = fmt.Errorf("no certificates found in %s", p.TLSCAFile)
+				}
+			})
+			if err != nil {
+				errs["tls_ca_file"] = err
+			} else if appendErr != nil {
+				errs["tls_ca_file"] = appendErr
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return &ValidationError{Fields: errs}
+	}
+	return nil
+}
+
+// MarshalJSON returns the JSON encoding of p.
+func (p ClientProfile) MarshalJSON() ([]byte, error) {
+	type plain ClientProfile
+	return json.Marshal(plain(p))
+}
+
+// ToYAML returns the YAML encoding of p.
+func (p ClientProfile) ToYAML() ([]byte, error) {
+	return yaml.Marshal(p)
+}
+
+// ProfileFromYAML parses a YAML-encoded ClientProfile.
+func ProfileFromYAML(data []byte) (ClientProfile, error) {
+	var p ClientProfile
+	err := yaml.Unmarshal(data, &p)
+	return p, err
+}
+
+// ProfileFromJSON parses a JSON-encoded ClientProfile.
+func ProfileFromJSON(data []byte) (ClientProfile, error) {
+	var p ClientProfile
+	err := json.Unmarshal(data, &p)
+	return p, err
+}
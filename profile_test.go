@@ -0,0 +1,60 @@
+package requests
+
+import (
+	"net/http"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestProfileRoundTrip(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+
+	var gotToken string
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		gotToken = r.Header.Get("Authorization")
+	})
+
+	os.Setenv("TEST_PROFILE_TOKEN", "secret-token")
+	defer os.Unsetenv("TEST_PROFILE_TOKEN")
+
+	p := ClientProfile{
+		BaseURL:  mock.URL(),
+		Headers:  map[string]string{"Authorization": "Bearer ${TEST_PROFILE_TOKEN}"},
+		Timeout:  5 * time.Second,
+		LogLevel: LogInfo,
+	}
+
+	data, err := p.ToYAML()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	p2, err := ProfileFromYAML(data)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	cli := NewClient()
+	if err := cli.ApplyProfile(p2); err != nil {
+		t.Error(err)
+		return
+	}
+
+	_, err = cli.NewRequest().Get("")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if gotToken != "Bearer secret-token" {
+		t.Errorf("unexpected Authorization header: %q", gotToken)
+	}
+}
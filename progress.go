@@ -0,0 +1,94 @@
+package requests
+
+import (
+	"io"
+	"time"
+)
+
+// DownloadProgressFunc reports response body read progress: read is the
+// number of bytes read so far, total is the value of Content-Length, or
+// -1 when the server didn't send one.
+type DownloadProgressFunc func(read, total int64)
+
+// downloadProgressByteInterval and downloadProgressTimeInterval bound how
+// often a DownloadProgressFunc is invoked while a body is being read: at
+// most once per this many bytes, or this often, whichever comes first.
+const (
+	downloadProgressByteInterval = 64 * 1024
+	downloadProgressTimeInterval = 100 * time.Millisecond
+)
+
+// SetDownloadProgress installs fn to be called periodically while the
+// response body is read, in both buffered and streamed
+// (SetDoNotParseResponse) modes. It's invoked synchronously from the read
+// path, so a slow fn delays that read by its own running time and no
+// more; it never runs concurrently with itself.
+func (r *Request) SetDownloadProgress(fn func(read, total int64)) *Request {
+	r.downloadProgress = fn
+	return r
+}
+
+// progressReader wraps a response body reader, calling onProgress at most
+// once per downloadProgressByteInterval bytes or downloadProgressTimeInterval,
+// whichever comes first, plus once more on EOF so the last call always
+// reports the exact final size.
+type progressReader struct {
+	r          io.Reader
+	total      int64
+	onProgress func(read, total int64)
+
+	read           int64
+	reportedRead   int64
+	lastReportedAt time.Time
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.read += int64(n)
+
+	if p.read > p.reportedRead {
+		if p.read-p.reportedRead >= downloadProgressByteInterval ||
+			time.Since(p.lastReportedAt) >= downloadProgressTimeInterval ||
+			err != nil {
+			p.onProgress(p.read, p.total)
+			p.reportedRead = p.read
+			p.lastReportedAt = time.Now()
+		}
+	}
+	return n, err
+}
+
+// UploadProgressFunc reports request body upload progress: written is the
+// number of bytes read off the body (and handed to the transport) so
+// far, total is the request's Content-Length, or -1 when it's unknown
+// (e.g. chunked transfer encoding).
+type UploadProgressFunc func(written, total int64)
+
+// SetUploadProgress installs fn to be called periodically as the request
+// body is read for sending, at the same cadence as SetDownloadProgress.
+// It's rewrapped around the body on every attempt, including retries and
+// the 417 Expectation Failed fallback, so written resets to 0 at
+// the start of each one; it never wraps the GetBody source itself, so
+// replays stay unaffected.
+func (r *Request) SetUploadProgress(fn func(written, total int64)) *Request {
+	r.uploadProgress = fn
+	return r
+}
+
+// progressReadCloser adds upload progress reporting to an io.ReadCloser,
+// via the same periodic logic as progressReader.
+type progressReadCloser struct {
+	*progressReader
+	rc io.ReadCloser
+}
+
+func newProgressReadCloser(rc io.ReadCloser, total int64, onProgress func(written, total int64)) *progressReadCloser {
+	return &progressReadCloser{
+		progressReader: &progressReader{r: rc, total: total, onProgress: onProgress},
+		rc:             rc,
+	}
+}
+
+func (p *progressReadCloser) Close() error {
+	return p.rc.Close()
+}
@@ -0,0 +1,129 @@
+package requests
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestSetDownloadProgressReportsMonotonicallyToExactSize(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	const size = 1 << 20 // 1 MB
+	const chunk = 64 * 1024
+	body := make([]byte, chunk)
+
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderContentLength, "1048576")
+		w.WriteHeader(http.StatusOK)
+		flusher, _ := w.(http.Flusher)
+		for written := 0; written < size; written += chunk {
+			w.Write(body)
+			if flusher != nil {
+				flusher.Flush()
+			}
+			time.Sleep(time.Millisecond)
+		}
+	})
+
+	var reads []int64
+	var totals []int64
+	resp, err := NewRequest().SetURLByStr(mock.URL()).
+		SetDownloadProgress(func(read, total int64) {
+			reads = append(reads, read)
+			totals = append(totals, total)
+		}).
+		Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Size() != size {
+		t.Fatalf("Size() = %d, want %d", resp.Size(), size)
+	}
+
+	if len(reads) == 0 {
+		t.Fatal("want at least one progress callback")
+	}
+	for i, total := range totals {
+		if total != size {
+			t.Errorf("totals[%d] = %d, want %d", i, total, size)
+		}
+	}
+	for i := 1; i < len(reads); i++ {
+		if reads[i] < reads[i-1] {
+			t.Errorf("reads[%d] = %d < reads[%d] = %d, want monotonically increasing", i, reads[i], i-1, reads[i-1])
+		}
+	}
+	if last := reads[len(reads)-1]; last != size {
+		t.Errorf("last reported read = %d, want exactly %d", last, size)
+	}
+}
+
+func TestSetDownloadProgressReportsMinusOneTotalWhenContentLengthUnknown(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.(http.Flusher).Flush()
+		w.Write([]byte("chunked body, no content-length"))
+	})
+
+	var gotTotal int64 = -99
+	_, err = NewRequest().SetURLByStr(mock.URL()).
+		SetDownloadProgress(func(read, total int64) {
+			gotTotal = total
+		}).
+		Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotTotal != -1 {
+		t.Errorf("total = %d, want -1 for an unknown Content-Length", gotTotal)
+	}
+}
+
+func TestSetDownloadProgressWorksInStreamedMode(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	const want = "streamed progress body"
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(want))
+	})
+
+	var last int64
+	resp, err := NewRequest().SetURLByStr(mock.URL()).
+		SetDoNotParseResponse(true).
+		SetDownloadProgress(func(read, total int64) {
+			last = read
+		}).
+		Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Close()
+
+	buf := make([]byte, 0, len(want))
+	tmp := make([]byte, 4)
+	for {
+		n, err := resp.BodyStream().Read(tmp)
+		buf = append(buf, tmp[:n]...)
+		if err != nil {
+			break
+		}
+	}
+	if string(buf) != want {
+		t.Fatalf("body = %q, want %q", buf, want)
+	}
+	if last != int64(len(want)) {
+		t.Errorf("last reported read = %d, want %d", last, len(want))
+	}
+}
@@ -0,0 +1,78 @@
+package requests
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestSetQueriesBulk(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+
+	var got url.Values
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		got = r.Form
+	})
+
+	_, err = NewRequest().SetURLByStr(mock.URL()).
+		SetQueries(map[string]string{"a": "1"}).
+		AddQueries(map[string][]string{"b": {"2", "3"}}).
+		SetQueryValues(url.Values{"c": {"4"}}).
+		Get("/")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	sort.Strings(got["b"])
+	want := url.Values{"a": {"1"}, "b": {"2", "3"}, "c": {"4"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestSetQueryString(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+
+	var got url.Values
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		got = r.Form
+	})
+
+	_, err = NewRequest().SetURLByStr(mock.URL()).SetQueryString("?a=1&b=2&b=3").Get("/")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	sort.Strings(got["b"])
+	want := url.Values{"a": {"1"}, "b": {"2", "3"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestSetQueryStringInvalid(t *testing.T) {
+	req := NewRequest().SetQuiet().SetQueryString("a=%zz")
+	if len(req.queryParam) != 0 {
+		t.Errorf("queryParam = %v, want unchanged (empty) after a malformed query string", req.queryParam)
+	}
+}
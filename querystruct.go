@@ -0,0 +1,180 @@
+package requests
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultQuerySeparator joins the path of nested struct field names used
+// when flattening, e.g. "filter.status" for a Status field of a struct
+// nested under a Filter field name.
+const defaultQuerySeparator = "."
+
+// SetQueryStruct encodes the public fields of v (a struct or pointer to
+// struct) into query parameters, using a `query:"name"` tag for the
+// parameter name (falling back to the lowercased field name) and an
+// `,omitempty` tag option to skip zero values. Supported field types are
+// string, integer, bool, float, time.Time (RFC 3339), slices (encoded as
+// repeated parameters) and pointers; nested structs are flattened using
+// SetQueryStructSeparator's separator (a dot by default). An error for an
+// unsupported field type is deferred and returned by the same error path
+// Do() returns, rather than being dropped.
+func (r *Request) SetQueryStruct(v interface{}) *Request {
+	sep := r.querySeparator
+	if sep == "" {
+		sep = defaultQuerySeparator
+	}
+	if err := encodeQueryStruct(r, reflect.ValueOf(v), "", sep); err != nil && r.deferredErr == nil {
+		r.deferredErr = err
+	}
+	return r
+}
+
+// SetQueryStructSeparator sets the separator SetQueryStruct uses to join
+// nested struct field names. The default is ".".
+func (r *Request) SetQueryStructSeparator(sep string) *Request {
+	r.querySeparator = sep
+	return r
+}
+
+func encodeQueryStruct(r *Request, v reflect.Value, prefix, sep string) error {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("requests: SetQueryStruct requires a struct, got %s", v.Kind())
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" { // unexported
+			continue
+		}
+
+		name, omitempty, skip := queryFieldNameAndOptions(field)
+		if skip {
+			continue
+		}
+		if prefix != "" {
+			name = prefix + sep + name
+		}
+
+		fv := v.Field(i)
+		if err := encodeQueryField(r, name, fv, omitempty, sep); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func queryFieldNameAndOptions(field reflect.StructField) (name string, omitempty, skip bool) {
+	tag := field.Tag.Get("query")
+	if tag == "-" {
+		return "", false, true
+	}
+	parts := strings.Split(tag, ",")
+	name = parts[0]
+	if name == "" {
+		name = strings.ToLower(field.Name)
+	}
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" {
+			omitempty = true
+		}
+	}
+	return name, omitempty, false
+}
+
+func encodeQueryField(r *Request, name string, fv reflect.Value, omitempty bool, sep string) error {
+	for fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			return nil
+		}
+		fv = fv.Elem()
+	}
+
+	if t, ok := fv.Interface().(time.Time); ok {
+		if omitempty && t.IsZero() {
+			return nil
+		}
+		r.queryParam.AddReplace(name, t.Format(time.RFC3339))
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		if omitempty && fv.String() == "" {
+			return nil
+		}
+		r.queryParam.AddReplace(name, fv.String())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if omitempty && fv.Int() == 0 {
+			return nil
+		}
+		r.queryParam.AddReplace(name, strconv.FormatInt(fv.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if omitempty && fv.Uint() == 0 {
+			return nil
+		}
+		r.queryParam.AddReplace(name, strconv.FormatUint(fv.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		if omitempty && fv.Float() == 0 {
+			return nil
+		}
+		r.queryParam.AddReplace(name, strconv.FormatFloat(fv.Float(), 'f', -1, 64))
+	case reflect.Bool:
+		if omitempty && !fv.Bool() {
+			return nil
+		}
+		r.queryParam.AddReplace(name, strconv.FormatBool(fv.Bool()))
+	case reflect.Slice, reflect.Array:
+		if omitempty && fv.Len() == 0 {
+			return nil
+		}
+		for i := 0; i < fv.Len(); i++ {
+			if err := encodeQuerySliceElem(r, name, fv.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		return encodeQueryStruct(r, fv, name, sep)
+	default:
+		return fmt.Errorf("requests: SetQueryStruct: unsupported field %q of type %s", name, fv.Type())
+	}
+	return nil
+}
+
+func encodeQuerySliceElem(r *Request, name string, ev reflect.Value) error {
+	for ev.Kind() == reflect.Ptr {
+		if ev.IsNil() {
+			return nil
+		}
+		ev = ev.Elem()
+	}
+	if t, ok := ev.Interface().(time.Time); ok {
+		r.queryParam.Add(name, t.Format(time.RFC3339))
+		return nil
+	}
+	switch ev.Kind() {
+	case reflect.String:
+		r.queryParam.Add(name, ev.String())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		r.queryParam.Add(name, strconv.FormatInt(ev.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		r.queryParam.Add(name, strconv.FormatUint(ev.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		r.queryParam.Add(name, strconv.FormatFloat(ev.Float(), 'f', -1, 64))
+	case reflect.Bool:
+		r.queryParam.Add(name, strconv.FormatBool(ev.Bool()))
+	default:
+		return fmt.Errorf("requests: SetQueryStruct: unsupported slice element of field %q, type %s", name, ev.Type())
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package requests
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+type listOptions struct {
+	Page    int       `query:"page"`
+	PerPage int       `query:"per_page,omitempty"`
+	Tags    []string  `query:"tag"`
+	Since   time.Time `query:"since,omitempty"`
+}
+
+func TestSetQueryStruct(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+
+	var got url.Values
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		got = r.Form
+	})
+
+	since := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	_, err = NewRequest().SetURLByStr(mock.URL()).
+		SetQueryStruct(listOptions{Page: 2, Tags: []string{"a", "b"}, Since: since}).
+		Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Get("page") != "2" {
+		t.Errorf("page = %q", got.Get("page"))
+	}
+	if got.Has("per_page") {
+		t.Errorf("expected per_page to be omitted, got %q", got.Get("per_page"))
+	}
+	if len(got["tag"]) != 2 || got["tag"][0] != "a" || got["tag"][1] != "b" {
+		t.Errorf("tag = %v", got["tag"])
+	}
+	if got.Get("since") != since.Format(time.RFC3339) {
+		t.Errorf("since = %q", got.Get("since"))
+	}
+}
+
+type badQueryStruct struct {
+	Ch chan int `query:"ch"`
+}
+
+func TestSetQueryStructUnsupportedType(t *testing.T) {
+	_, err := NewRequest().SetQueryStruct(badQueryStruct{}).RawRequest()
+	if err == nil {
+		t.Fatal("expected an error for unsupported field type")
+	}
+}
@@ -0,0 +1,86 @@
+package requests
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func rangeHandler(full []byte) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rng := r.Header.Get(HeaderRange)
+		if rng == "" {
+			w.Write(full)
+			return
+		}
+		var start, end int
+		fmt.Sscanf(rng, "bytes=%d-%d", &start, &end)
+		w.Header().Set(HeaderContentRange, fmt.Sprintf("bytes %d-%d/%d", start, end, len(full)))
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write(full[start : end+1])
+	}
+}
+
+func TestSetRangeRequestsPartialContent(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	full := []byte("0123456789")
+	mock.HandleFunc("/", rangeHandler(full))
+
+	resp, err := NewRequest().SetURLByStr(mock.URL()).SetRange(2, 5).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.IsPartialContent() {
+		t.Errorf("StatusCode = %d, want 206", resp.StatusCode())
+	}
+	if got, want := string(resp.Body()), "2345"; got != want {
+		t.Errorf("Body = %q, want %q", got, want)
+	}
+
+	start, end, total, ok := resp.ContentRange()
+	if !ok {
+		t.Fatal("ContentRange() ok = false")
+	}
+	if start != 2 || end != 5 || total != int64(len(full)) {
+		t.Errorf("ContentRange() = %d, %d, %d, want 2, 5, %d", start, end, total, len(full))
+	}
+}
+
+func TestSetRangesMultiRangeHeader(t *testing.T) {
+	req := NewRequest().SetRanges([]Range{{Start: 0, End: 99}, {Start: 200, End: -1}})
+	p, ok := req.headerParam.Search(HeaderRange)
+	if !ok || p.Value != "bytes=0-99,200-" {
+		t.Errorf("Range = %v, %v, want %q", p, ok, "bytes=0-99,200-")
+	}
+}
+
+func TestContentRangeMissingHeader(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+
+	resp, err := NewRequest().SetURLByStr(mock.URL()).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.IsPartialContent() {
+		t.Error("IsPartialContent() = true for a 200 response")
+	}
+	if _, _, _, ok := resp.ContentRange(); ok {
+		t.Error("ContentRange() ok = true without a Content-Range header")
+	}
+}
@@ -0,0 +1,78 @@
+package requests
+
+import (
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func TestRawRequestCanBeSentByAPlainHTTPClient(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	var gotMethod, gotHeader string
+	var gotBody []byte
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		gotMethod = r.Method
+		gotHeader = r.Header.Get("X-Custom")
+		gotBody, _ = ioutil.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := NewRequest().SetURLByStr(mock.URL()).
+		SetHeader("X-Custom", "hello").
+		SetBodyString("payload")
+	req.method = MethodPost
+
+	rawReq, err := req.RawRequest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := http.DefaultClient.Do(rawReq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if gotMethod != MethodPost {
+		t.Errorf("method = %q, want %q", gotMethod, MethodPost)
+	}
+	if gotHeader != "hello" {
+		t.Errorf("X-Custom header = %q, want hello", gotHeader)
+	}
+	if string(gotBody) != "payload" {
+		t.Errorf("body = %q, want payload", gotBody)
+	}
+}
+
+func TestRawRequestOnACloneLeavesTheTemplateReusable(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	var calls int
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusOK)
+	})
+
+	template := NewRequest().SetURLByStr(mock.URL())
+	if _, err := template.Clone().RawRequest(); err != nil {
+		t.Fatal(err)
+	}
+	if template.rawRequest != nil {
+		t.Error("template.rawRequest should stay nil after RawRequest was called on a Clone")
+	}
+
+	if _, err := template.Get("/"); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+}
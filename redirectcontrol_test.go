@@ -0,0 +1,100 @@
+package requests
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestSetFollowRedirectFalseReturnsLocationInsteadOfFollowing(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	var followedRedirect bool
+	mock.HandleFunc("/start", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/target", http.StatusFound)
+	})
+	mock.HandleFunc("/target", func(w http.ResponseWriter, r *http.Request) {
+		followedRedirect = true
+		w.WriteHeader(http.StatusOK)
+	})
+
+	resp, err := NewRequest().SetFollowRedirect(false).Get(mock.URL() + "/start")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if followedRedirect {
+		t.Error("the redirect was followed, want it left to the caller")
+	}
+	if resp.StatusCode() != http.StatusFound {
+		t.Errorf("status = %d, want 302", resp.StatusCode())
+	}
+	loc := resp.Location()
+	if loc == nil || loc.Path != "/target" {
+		t.Errorf("Location() = %v, want path /target", loc)
+	}
+}
+
+func TestSetFollowRedirectTrueOverridesClientNoRedirect(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/start", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/target", http.StatusFound)
+	})
+	mock.HandleFunc("/target", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	client := NewClient().NoRedirect()
+	resp, err := client.NewRequest().SetFollowRedirect(true).Get(mock.URL() + "/start")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode() != http.StatusTeapot {
+		t.Errorf("status = %d, want 418 -- the redirect should have been followed", resp.StatusCode())
+	}
+
+	// The client's own NoRedirect policy is untouched for other requests.
+	resp2, err := client.NewRequest().Get(mock.URL() + "/start")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp2.StatusCode() != http.StatusFound {
+		t.Errorf("status = %d, want 302 -- NoRedirect should still apply without the override", resp2.StatusCode())
+	}
+}
+
+func TestSetMaxRedirectsOverridesClientDefault(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/a", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/b", http.StatusFound)
+	})
+	mock.HandleFunc("/b", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/c", http.StatusFound)
+	})
+	mock.HandleFunc("/c", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	_, err = NewRequest().SetMaxRedirects(1).Get(mock.URL() + "/a")
+	if err == nil {
+		t.Fatal("expected an error after exceeding the 1-redirect cap")
+	}
+
+	// Unaffected requests still get the higher client-wide default.
+	resp, err := NewRequest().Get(mock.URL() + "/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode() != http.StatusOK {
+		t.Errorf("status = %d, want 200", resp.StatusCode())
+	}
+}
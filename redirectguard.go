@@ -0,0 +1,110 @@
+package requests
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// defaultMaxRedirects matches the cap net/http enforces internally when no
+// CheckRedirect is set, kept here so installing our own checkRedirect as
+// the default doesn't change behavior for clients that never call
+// SetMaxRedirects.
+const defaultMaxRedirects = 10
+
+// ErrRedirectLoop is returned by the Client's redirect policy when a
+// (method, URL) pair repeats within a single redirect chain, e.g. a
+// server bouncing a request between two URLs indefinitely. The default
+// net/http redirect cap would still allow several rounds of this before
+// giving up; this aborts as soon as the repeat is detected.
+type ErrRedirectLoop struct {
+	Method string
+	URL    string
+}
+
+func (e *ErrRedirectLoop) Error() string {
+	return fmt.Sprintf("requests: redirect loop detected: %s %s repeats earlier in this redirect chain", e.Method, e.URL)
+}
+
+// ErrCookieHeaderTooLarge is returned by the Client's redirect policy when
+// the cookies the jar would attach to the next hop exceed
+// Client.SetMaxCookieHeaderBytes, e.g. a server piling on a new Set-Cookie
+// at every redirect.
+type ErrCookieHeaderTooLarge struct {
+	Bytes int
+	Limit int
+}
+
+func (e *ErrCookieHeaderTooLarge) Error() string {
+	return fmt.Sprintf("requests: cookie header for the next redirect would be %d bytes, exceeding the %d byte limit", e.Bytes, e.Limit)
+}
+
+// SetMaxRedirects caps how many redirects a single request may follow
+// before it's aborted, as the built-in net/http cap would, but via the
+// Client's own redirect policy so it composes with the loop and cookie
+// checks below. 0 (the default) keeps the usual 10-redirect cap.
+func (c *Client) SetMaxRedirects(n int) *Client {
+	c.maxRedirects = n
+	c.invalidateConfigSnapshot()
+	return c
+}
+
+// SetMaxCookieHeaderBytes caps the total size of the Cookie header the
+// jar would attach to the next redirect hop, aborting the chain with
+// *ErrCookieHeaderTooLarge when exceeded, instead of letting the request
+// keep growing for every hop up to the redirect cap. 0 (the default)
+// means no cap. Has no effect without a cookie jar (see SetCookieJar /
+// WithCookieJar).
+func (c *Client) SetMaxCookieHeaderBytes(n int) *Client {
+	c.maxCookieHeaderBytes = n
+	return c
+}
+
+// checkRedirect is installed as the Client's http.Client.CheckRedirect by
+// default, so loop detection and the cookie cap are always active unless
+// a caller overrides them via SetCheckRedirect or NoRedirect.
+func (c *Client) checkRedirect(req *http.Request, via []*http.Request) error {
+	return c.checkRedirectMax(req, via, c.maxRedirects)
+}
+
+// checkRedirectMax is checkRedirect with the redirect cap passed in
+// explicitly, rather than read from c.maxRedirects, so Request.SetMaxRedirects
+// can override it for a single execution without touching the shared
+// Client. maxOverride of 0 means defaultMaxRedirects, same as
+// Client.SetMaxRedirects's zero value.
+func (c *Client) checkRedirectMax(req *http.Request, via []*http.Request, maxOverride int) error {
+	max := maxOverride
+	if max == 0 {
+		max = defaultMaxRedirects
+	}
+	if len(via) >= max {
+		return fmt.Errorf("requests: stopped after %d redirects", max)
+	}
+
+	for _, prev := range via {
+		if prev.Method == req.Method && prev.URL.String() == req.URL.String() {
+			return &ErrRedirectLoop{Method: req.Method, URL: req.URL.String()}
+		}
+	}
+
+	if c.maxCookieHeaderBytes > 0 && c.cli.Jar != nil {
+		size := 0
+		for _, ck := range c.cli.Jar.Cookies(req.URL) {
+			size += len(ck.String())
+		}
+		if size > c.maxCookieHeaderBytes {
+			return &ErrCookieHeaderTooLarge{Bytes: size, Limit: c.maxCookieHeaderBytes}
+		}
+	}
+
+	if history, ok := req.Context().Value(redirectHistoryContextKey).(*[]*url.URL); ok {
+		urls := make([]*url.URL, 0, len(via)+1)
+		for _, prev := range via {
+			urls = append(urls, prev.URL)
+		}
+		*history = append(urls, req.URL)
+	}
+
+	c.emitRedirectFollowed(req)
+	return nil
+}
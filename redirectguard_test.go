@@ -0,0 +1,118 @@
+package requests
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestRedirectLoopDetected(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+
+	var hops int
+	mock.HandleFunc("^/a$", func(w http.ResponseWriter, r *http.Request) {
+		hops++
+		http.Redirect(w, r, mock.URL()+"/b", http.StatusFound)
+	})
+	mock.HandleFunc("^/b$", func(w http.ResponseWriter, r *http.Request) {
+		hops++
+		http.Redirect(w, r, mock.URL()+"/a", http.StatusFound)
+	})
+
+	_, err = NewRequest().SetURLByStr(mock.URL() + "/a").Get("")
+	if err == nil {
+		t.Fatal("expected a redirect-loop error")
+	}
+	if urlErr, ok := err.(interface{ Unwrap() error }); ok {
+		err = urlErr.Unwrap()
+	}
+	if _, ok := err.(*ErrRedirectLoop); !ok {
+		t.Fatalf("expected *ErrRedirectLoop, got %T: %v", err, err)
+	}
+	// a, b: the chain is aborted before a third request is ever sent back
+	// to a, well short of the default 10-redirect cap.
+	if hops != 2 {
+		t.Errorf("hops = %d, want 2", hops)
+	}
+}
+
+// hopCookieHandler redirects to the next hop in an ever-growing chain of
+// distinct URLs, each setting a new cookie, so the (method, URL) pairs
+// never repeat and redirect-loop detection doesn't mask the cookie-size
+// check below.
+func hopCookieHandler(mock *Mock, maxHops int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		n, _ := strconv.Atoi(r.URL.Query().Get("n"))
+		http.SetCookie(w, &http.Cookie{Name: fmt.Sprintf("c%d", n), Value: strings.Repeat("x", 20)})
+		if n >= maxHops {
+			w.Write([]byte("done"))
+			return
+		}
+		http.Redirect(w, r, fmt.Sprintf("%s/hop?n=%d", mock.URL(), n+1), http.StatusFound)
+	}
+}
+
+func TestMaxCookieHeaderBytesExceeded(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+	mock.HandleFunc("^/hop", hopCookieHandler(mock, 8))
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cli := NewClient().SetCookieJar(jar).SetMaxCookieHeaderBytes(100)
+
+	_, err = cli.NewRequest().Get(mock.URL() + "/hop?n=0")
+	if err == nil {
+		t.Fatal("expected a cookie-header-too-large error")
+	}
+	if urlErr, ok := err.(interface{ Unwrap() error }); ok {
+		err = urlErr.Unwrap()
+	}
+	if _, ok := err.(*ErrCookieHeaderTooLarge); !ok {
+		t.Fatalf("expected *ErrCookieHeaderTooLarge, got %T: %v", err, err)
+	}
+}
+
+func TestMaxCookieHeaderBytesUnlimitedByDefault(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+	mock.HandleFunc("^/hop", hopCookieHandler(mock, 5))
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cli := NewClient().SetCookieJar(jar)
+
+	resp, err := cli.NewRequest().Get(mock.URL() + "/hop?n=0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(resp.Body()) != "done" {
+		t.Errorf("body = %q, want %q", resp.Body(), "done")
+	}
+}
@@ -0,0 +1,46 @@
+package requests
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// redirectHistoryContextKeyType is the context key carrying the
+// *[]*url.URL that checkRedirectMax appends each hop's URL into, the same
+// pattern as wireSizeContextKey and connLabelContextKey.
+type redirectHistoryContextKeyType struct{}
+
+var redirectHistoryContextKey = redirectHistoryContextKeyType{}
+
+// RedirectHistory returns every URL the request visited, in order,
+// starting with the one originally requested and ending with the one
+// that produced this response -- so len(RedirectHistory()) == 1 when no
+// redirect was followed, and the last entry always equals FinalURL().
+func (r *Response) RedirectHistory() []*url.URL {
+	return r.redirectHistory
+}
+
+// FinalURL returns the URL this response was actually fetched from,
+// i.e. the original URL after following any redirects. Unlike Location,
+// which reads a Location response header, FinalURL reflects where the
+// request ended up, and equals the requested URL when nothing redirected.
+func (r *Response) FinalURL() *url.URL {
+	return r.finalURL
+}
+
+// setRedirectFields populates a Response's redirect history and final URL
+// from the request that produced it. checkRedirectMax only records a
+// history when a redirect was actually followed, so a direct, unredirected
+// fetch falls back to a single-entry history of the URL it fetched.
+func setRedirectFields(response *Response, req *Request, resp *http.Response) {
+	history := req.redirectHistory
+	finalURL := req.baseURL
+	if resp != nil && resp.Request != nil && resp.Request.URL != nil {
+		finalURL = resp.Request.URL
+	}
+	if len(history) == 0 {
+		history = []*url.URL{finalURL}
+	}
+	response.redirectHistory = history
+	response.finalURL = finalURL
+}
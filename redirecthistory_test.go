@@ -0,0 +1,101 @@
+package requests
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestResponseRedirectHistoryAndFinalURLChainedRedirects(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	mock.HandleFunc("^/start$", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/middle", http.StatusFound)
+	})
+	mock.HandleFunc("^/middle$", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/end", http.StatusFound)
+	})
+	mock.HandleFunc("^/end$", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("arrived"))
+	})
+
+	resp, err := NewRequest().SetURLByStr(mock.URL()).Get("/start")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(resp.Body()) != "arrived" {
+		t.Fatalf("Body() = %q, want arrived", resp.Body())
+	}
+
+	history := resp.RedirectHistory()
+	if len(history) != 3 {
+		t.Fatalf("RedirectHistory() has %d entries, want 3: %v", len(history), history)
+	}
+	wantPaths := []string{"/start", "/middle", "/end"}
+	for i, want := range wantPaths {
+		if history[i].Path != want {
+			t.Errorf("RedirectHistory()[%d].Path = %q, want %q", i, history[i].Path, want)
+		}
+	}
+
+	if resp.FinalURL().Path != "/end" {
+		t.Errorf("FinalURL().Path = %q, want /end", resp.FinalURL().Path)
+	}
+	if resp.FinalURL().Path == resp.Location().String() {
+		t.Errorf("FinalURL and Location unexpectedly matched; FinalURL should reflect the fetched URL, not a Location header")
+	}
+}
+
+func TestResponseRedirectHistoryIsSingleEntryWithoutRedirect(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("direct hit"))
+	})
+
+	resp, err := NewRequest().SetURLByStr(mock.URL()).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	history := resp.RedirectHistory()
+	if len(history) != 1 {
+		t.Fatalf("RedirectHistory() has %d entries, want 1: %v", len(history), history)
+	}
+	if history[0].String() != resp.FinalURL().String() {
+		t.Errorf("history[0] = %v, want it to equal FinalURL() = %v", history[0], resp.FinalURL())
+	}
+}
+
+func TestResponseRedirectHistoryCookiesStillReachJar(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("^/start$", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc123"})
+		http.Redirect(w, r, "/end", http.StatusFound)
+	})
+	mock.HandleFunc("^/end$", func(w http.ResponseWriter, r *http.Request) {
+		c, err := r.Cookie("session")
+		if err != nil || c.Value != "abc123" {
+			t.Errorf("final hop cookie = %v, %v, want session=abc123", c, err)
+		}
+		w.Write([]byte("ok"))
+	})
+
+	client := NewClient().WithCookieJar()
+	resp, err := client.NewRequest().SetURLByStr(mock.URL()).Get("/start")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.RedirectHistory()) != 2 {
+		t.Errorf("RedirectHistory() has %d entries, want 2", len(resp.RedirectHistory()))
+	}
+}
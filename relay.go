@@ -0,0 +1,99 @@
+package requests
+
+import (
+	"io"
+	"net/http"
+)
+
+// hopByHop reports whether name is Connection, Transfer-Encoding or
+// Keep-Alive, the hop-by-hop headers that Relay and RelayRequest strip.
+// Other hop-by-hop headers (TE, Trailer, Upgrade, Proxy-Authenticate,
+// Proxy-Authorization, or headers listed in Connection) are forwarded.
+func hopByHop(name string) bool {
+	switch http.CanonicalHeaderKey(name) {
+	case HeaderConnection, HeaderTransferEncoding, HeaderKeepAlive:
+		return true
+	default:
+		return false
+	}
+}
+
+func copyHeaderExceptHopByHop(dst, src http.Header) {
+	for name, values := range src {
+		if hopByHop(name) {
+			continue
+		}
+		dst[name] = values
+	}
+}
+
+// Relay writes resp to w: its status code, its headers (minus hop-by-hop
+// ones), and its already-buffered body. Use this for a response that was
+// fetched normally; for a large or chunked upstream response that should
+// never be buffered in memory, fetch it with Client.RelayRequest instead.
+func Relay(w http.ResponseWriter, resp *Response) error {
+	copyHeaderExceptHopByHop(w.Header(), resp.Header())
+	w.WriteHeader(resp.StatusCode())
+	_, err := w.Write(resp.Body())
+	return err
+}
+
+// RelayRequest builds an outgoing request from the incoming r -- method,
+// URL, headers (minus hop-by-hop ones) and body -- lets rewrite adjust it
+// (most importantly, pointing it at the upstream URL, since r's own URL
+// usually carries no scheme or host), then streams the upstream response
+// straight to w without ever buffering its body. w's status code and
+// headers (again minus hop-by-hop ones) are set from the upstream
+// response before the body is copied. The outgoing request uses r's
+// context, so the upstream fetch is cancelled as soon as the downstream
+// client disconnects. rewrite may be nil.
+func (c *Client) RelayRequest(w http.ResponseWriter, r *http.Request, rewrite func(*Request)) error {
+	req := c.NewRequest().
+		SetContext(r.Context()).
+		SetMethod(r.Method).
+		SetURL(r.URL).
+		SetBody(r.Body)
+	for name, values := range r.Header {
+		if hopByHop(name) {
+			continue
+		}
+		req.AddHeaders(name, values)
+	}
+	if rewrite != nil {
+		rewrite(req)
+	}
+
+	raw, err := req.RawRequest()
+	if err != nil {
+		return err
+	}
+	resp, err := c.Process(raw)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	copyHeaderExceptHopByHop(w.Header(), resp.Header)
+	w.WriteHeader(resp.StatusCode)
+
+	dst := io.Writer(w)
+	if flusher, ok := w.(http.Flusher); ok {
+		dst = flushWriter{w: w, flusher: flusher}
+	}
+	_, err = io.Copy(dst, resp.Body)
+	return err
+}
+
+// flushWriter flushes w after every write, so a streamed relay reaches
+// the downstream client as each chunk arrives instead of waiting for
+// http.ResponseWriter's own internal buffer to fill.
+type flushWriter struct {
+	w       io.Writer
+	flusher http.Flusher
+}
+
+func (f flushWriter) Write(p []byte) (int, error) {
+	n, err := f.w.Write(p)
+	f.flusher.Flush()
+	return n, err
+}
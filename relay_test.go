@@ -0,0 +1,128 @@
+package requests
+
+import (
+	"bytes"
+	"crypto/rand"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRelayCopiesStatusHeadersAndBodyButStripsHopByHop(t *testing.T) {
+	upstream, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer upstream.Close()
+	upstream.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Upstream", "yes")
+		w.Header().Set(HeaderConnection, "close")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("upstream body"))
+	})
+
+	resp, err := NewRequest().SetURLByStr(upstream.URL()).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	if err := Relay(rec, resp); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusCreated {
+		t.Errorf("Code = %d, want %d", rec.Code, http.StatusCreated)
+	}
+	if rec.Body.String() != "upstream body" {
+		t.Errorf("Body = %q, want %q", rec.Body.String(), "upstream body")
+	}
+	if rec.Header().Get("X-Upstream") != "yes" {
+		t.Errorf("X-Upstream header not relayed")
+	}
+	if rec.Header().Get(HeaderConnection) != "" {
+		t.Errorf("Connection header should have been stripped, got %q", rec.Header().Get(HeaderConnection))
+	}
+}
+
+func TestRelayRequestStreamsLargeUpstreamResponseUnbuffered(t *testing.T) {
+	want := make([]byte, 5<<20)
+	if _, err := rand.Read(want); err != nil {
+		t.Fatal(err)
+	}
+
+	upstream, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer upstream.Close()
+	upstream.HandleFunc("/big", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderTransferEncoding, "chunked")
+		w.Header().Set(HeaderKeepAlive, "timeout=5")
+		w.WriteHeader(http.StatusOK)
+		w.Write(want)
+	})
+
+	client := NewClient()
+	downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := client.RelayRequest(w, r, func(req *Request) {
+			req.SetURLByStr(upstream.URL() + "/big")
+		}); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer downstream.Close()
+
+	resp, err := NewRequest().SetURLByStr(downstream.URL).Get("/anything")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode() != http.StatusOK {
+		t.Fatalf("StatusCode() = %d, want %d", resp.StatusCode(), http.StatusOK)
+	}
+	if !bytes.Equal(resp.Body(), want) {
+		t.Errorf("relayed body does not match the upstream body (got %d bytes, want %d)", len(resp.Body()), len(want))
+	}
+	if resp.Header().Get(HeaderKeepAlive) != "" {
+		t.Errorf("Keep-Alive header should have been stripped, got %q", resp.Header().Get(HeaderKeepAlive))
+	}
+}
+
+func TestRelayRequestForwardsMethodAndRequestBody(t *testing.T) {
+	upstream, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer upstream.Close()
+	var gotMethod, gotBody string
+	upstream.HandleFunc("/echo", func(w http.ResponseWriter, r *http.Request) {
+		gotMethod = r.Method
+		buf := make([]byte, 64)
+		n, _ := r.Body.Read(buf)
+		gotBody = string(buf[:n])
+		w.Write([]byte("ack"))
+	})
+
+	client := NewClient()
+	downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := client.RelayRequest(w, r, func(req *Request) {
+			req.SetURLByStr(upstream.URL() + "/echo")
+		}); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer downstream.Close()
+
+	resp, err := NewRequest().SetURLByStr(downstream.URL).SetBodyString("ping").Post("/in")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(resp.Body()) != "ack" {
+		t.Errorf("Body() = %q, want %q", resp.Body(), "ack")
+	}
+	if gotMethod != MethodPost {
+		t.Errorf("upstream saw method %q, want %q", gotMethod, MethodPost)
+	}
+	if gotBody != "ping" {
+		t.Errorf("upstream saw body %q, want %q", gotBody, "ping")
+	}
+}
@@ -11,33 +11,130 @@ import (
 	"mime"
 	"net/http"
 	"net/http/httputil"
+	"net/textproto"
 	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"golang.org/x/net/html/charset"
 	"golang.org/x/text/encoding"
 	"golang.org/x/text/transform"
+	"gopkg.in/yaml.v2"
 )
 
 // Request type is used to compose and send individual request from client
 type Request struct {
-	baseURL         *url.URL
-	method          string
-	headerParam     paramPairs
-	queryParam      paramPairs
-	pathParam       paramPairs
-	formParam       paramPairs
-	multiFiles      multiFiles
-	body            io.Reader
-	sendAt          time.Time
-	rawRequest      *http.Request
-	client          *Client
-	ctx             context.Context
-	discardResponse bool
-	noCache         bool
-	charset         string
-	transformer     transform.Transformer
+	baseURL            *url.URL
+	method             string
+	headerParam        paramPairs
+	queryParam         paramPairs
+	pathParam          paramPairs
+	formParam          paramPairs
+	multiFiles         multiFiles
+	body               io.Reader
+	sendAt             time.Time
+	rawRequest         *http.Request
+	client             *Client
+	ctx                context.Context
+	ctxCancel          context.CancelFunc
+	discardResponse    bool
+	doNotParseResponse bool
+	maxResponseSize    *int64
+	downloadProgress   DownloadProgressFunc
+	uploadProgress     UploadProgressFunc
+	redirectHistory    []*url.URL
+	noCache            bool
+	traceEnabled       bool
+	traceState         *traceState
+	charset            string
+	transformer        transform.Transformer
+	logLevel           *logLevel
+	expectTypes        []string
+
+	skipResponseValidation bool
+
+	awsSigV4 *awsSigV4Config
+
+	signers []func(*http.Request, []byte) error
+
+	allowObsoleteLineFolding bool
+
+	multipartBoundary     string
+	multipartBoundaryAuto bool
+
+	querySeparator string
+	deferredErr    error
+
+	compressEncoding string
+
+	wireSize *WireSize
+
+	cookies []*http.Cookie
+
+	fileFromPaths []*fileFromPath
+
+	bodyProvider func() (io.ReadCloser, error)
+
+	// bodyPreview, when non-nil, reads up to a limit's worth of the
+	// request body for Message without disturbing it -- set by
+	// RawRequest once it knows the body is one it can safely re-read:
+	// see bodyPreviewer and the SetBodyProvider case in RawRequest.
+	bodyPreview func(limit int64) ([]byte, error)
+
+	fragment string
+
+	// deferredReplay marks a Request rebuilt by Client.requestFromDeferred,
+	// so Client.do knows not to feed its failures back into the deferred
+	// queue a second time.
+	deferredReplay bool
+
+	payload interface{}
+
+	host string
+
+	debugWriter io.Writer
+
+	matrixParams []*matrixParam
+
+	headerTimeout   time.Duration
+	bodyIdleTimeout time.Duration
+
+	retryCount       int
+	retryBackoffBase time.Duration
+	retryBackoffMax  time.Duration
+	retryCondition   func(*Response, error) bool
+
+	followRedirect *bool
+	maxRedirects   *int
+
+	proxyURL *url.URL
+
+	trailerParam paramPairs
+
+	idempotencyKey     string
+	autoIdempotencyKey bool
+
+	contentLength *int64
+	chunked       bool
+
+	jsonrpcID       json.RawMessage
+	jsonrpcBatchIDs []json.RawMessage
+
+	resultTarget interface{}
+	errorTarget  interface{}
+
+	errorOnStatus *bool
+}
+
+// fileFromPath is a file queued by SetFileFromPath and opened lazily in
+// RawRequest, once it's clear the request is actually being built.
+type fileFromPath struct {
+	Param string
+	Path  string
 }
 
 func newRequest(c *Client) *Request {
@@ -55,6 +152,27 @@ func (r *Request) Clone() *Request {
 		bu := *n.baseURL
 		n.baseURL = &bu
 	}
+	// headerParam/queryParam/pathParam/formParam/multiFiles are slices, so
+	// the struct copy above shares their backing arrays with r. Without
+	// this, paramPairs' in-place append-and-shift (see paramPairs.add) can
+	// let one clone silently overwrite entries another clone -- or r
+	// itself -- still expects to see.
+	n.headerParam = r.headerParam.Clone()
+	n.queryParam = r.queryParam.Clone()
+	n.pathParam = r.pathParam.Clone()
+	n.formParam = r.formParam.Clone()
+	n.trailerParam = r.trailerParam.Clone()
+	if r.multiFiles != nil {
+		n.multiFiles = make(multiFiles, len(r.multiFiles))
+		copy(n.multiFiles, r.multiFiles)
+	}
+	if r.ctxCancel != nil {
+		// r's deadline context is owned by r: n must not be able to cancel
+		// it out from under r (or any other clone) once n is sent and its
+		// own cancel runs. Derive a child context instead, so n gets its
+		// own cancel scoped to just n.
+		n.ctx, n.ctxCancel = context.WithCancel(r.ctx)
+	}
 	return n
 }
 
@@ -63,12 +181,26 @@ func (r *Request) SetCharset(transformer transform.Transformer) *Request {
 	return r
 }
 
+// SetCharsetWithStr looks cs up via charset.Lookup and, if found, sets it as
+// the outgoing charset the same way SetCharset does: query values, form
+// fields, path segments and multipart fields are transcoded to it through
+// fill(), and it's set as the charset parameter on the Content-Type emitted.
+// An unknown name is a deferred error, returned by RawRequest/Do rather than
+// here, consistent with the rest of the builder. cs naming a charset that
+// doesn't need transcoding, e.g. "utf-8", is a no-op.
 func (r *Request) SetCharsetWithStr(cs string) *Request {
-	if e, charset := charset.Lookup(cs); e != nil && e != encoding.Nop {
-		r.charset = charset
-		return r.SetCharset(e.NewEncoder())
+	e, charset := charset.Lookup(cs)
+	if e == nil {
+		if r.deferredErr == nil {
+			r.deferredErr = fmt.Errorf("requests: unknown charset %q", cs)
+		}
+		return r
 	}
-	return r
+	if e == encoding.Nop {
+		return r
+	}
+	r.charset = charset
+	return r.SetCharset(e.NewEncoder())
 }
 
 // AddCookies adds cookie to the client.
@@ -102,6 +234,278 @@ func (r *Request) SetURL(u *url.URL) *Request {
 	return r
 }
 
+// SetFragment sets the URL fragment ("#...") used when this request's
+// final URL is built, overriding any fragment already present on the
+// URL passed to SetURL/SetURLByStr/SetBaseURL. Fragments are never sent
+// on the wire, but are kept for Request.String()/Message() logging and
+// for Response.Location() comparisons.
+func (r *Request) SetFragment(f string) *Request {
+	r.fragment = f
+	return r
+}
+
+// SetHost overrides the Host header sent with this request, independent
+// of the URL's host used to actually dial the connection -- useful for
+// hitting a load balancer or a specific backend by IP while still
+// presenting the virtual host it routes on. Plain SetHeader("Host", ...)
+// has no effect for this because net/http takes the wire Host from
+// http.Request.Host, not its Header map; SetHost assigns that field in
+// RawRequest instead. It shows up in Message() output and changes the
+// cache hash like any other header would.
+func (r *Request) SetHost(host string) *Request {
+	r.host = host
+	return r
+}
+
+// SetDebugWriter streams a direction-annotated, timestamped dump of this
+// request and its response -- head and body, for every redirect hop -- to
+// w, independent of the client's log level. It wraps the client's
+// transport the first time any request uses it, so like
+// Client.SetWireSizeAccounting, call it before other transport
+// configuration (SetDialContext, SetProxyFunc, etc.) -- those fail with
+// ErrNotTransport once something else has taken the transport slot. TLS
+// is dumped post-decryption at the HTTP layer, the only place the
+// plaintext is available for an https:// request. w may be shared across
+// concurrent requests; writes to it are serialized so their dumps never
+// interleave.
+func (r *Request) SetDebugWriter(w io.Writer) *Request {
+	r.debugWriter = w
+	return r
+}
+
+// SetMatrixParam attaches a matrix parameter (RFC 3986 ";key=value") to
+// the named path segment, e.g. SetMatrixParam("resource", "version", "2")
+// turns "/resource/sub" into "/resource;version=2/sub". It's applied
+// after {placeholder} substitution, so segment is matched against the
+// already-filled path, and key and value are percent-escaped so a ';',
+// '=' or ',' in them can't be mistaken for matrix syntax. Repeated calls
+// for the same segment accumulate in call order.
+func (r *Request) SetMatrixParam(segment, key, value string) *Request {
+	r.matrixParams = append(r.matrixParams, &matrixParam{Segment: segment, Key: key, Value: value})
+	return r
+}
+
+// Range is one byte range for Request.SetRanges, as sent in a Range
+// header: bytes Start-End inclusive, with End<0 meaning open-ended
+// ("Start-", through the end of the resource).
+type Range struct {
+	Start int64
+	End   int64
+}
+
+// SetRange sets the Range header to request a single slice of the
+// resource's bytes, start through end inclusive. end<0 requests an
+// open-ended range ("bytes=start-") through the end of the resource.
+func (r *Request) SetRange(start, end int64) *Request {
+	return r.SetRanges([]Range{{Start: start, End: end}})
+}
+
+// SetRanges sets the Range header to request multiple byte ranges in one
+// request, producing a single "bytes=a-b,c-d" value per RFC 7233 section
+// 3.1. A server that honors it replies 206 Partial Content with a
+// multipart/byteranges body; see Response.IsPartialContent and
+// Response.ContentRange.
+func (r *Request) SetRanges(ranges []Range) *Request {
+	parts := make([]string, 0, len(ranges))
+	for _, rg := range ranges {
+		if rg.End < 0 {
+			parts = append(parts, fmt.Sprintf("%d-", rg.Start))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d-%d", rg.Start, rg.End))
+		}
+	}
+	r.headerParam.AddReplace(HeaderRange, "bytes="+strings.Join(parts, ","))
+	return r
+}
+
+// SetIfNoneMatch sets the If-None-Match header for revalidating a cached
+// response against its ETag, quoting etag per RFC 7232 section 2.3 if it
+// isn't already. A server that still considers it current answers with
+// 304 Not Modified; see Response.IsNotModified.
+func (r *Request) SetIfNoneMatch(etag string) *Request {
+	r.headerParam.AddReplace(HeaderIfNoneMatch, quoteETag(etag))
+	return r
+}
+
+// SetIfMatch sets the If-Match header, quoting etag as SetIfNoneMatch
+// does, so a write only applies if the resource's current ETag still
+// matches what was last read.
+func (r *Request) SetIfMatch(etag string) *Request {
+	r.headerParam.AddReplace(HeaderIfMatch, quoteETag(etag))
+	return r
+}
+
+// SetIfModifiedSince sets the If-Modified-Since header, formatted per RFC
+// 7232 section 2.2 (http.TimeFormat, in UTC).
+func (r *Request) SetIfModifiedSince(t time.Time) *Request {
+	r.headerParam.AddReplace(HeaderIfModifiedSince, t.UTC().Format(http.TimeFormat))
+	return r
+}
+
+// SetIfUnmodifiedSince sets the If-Unmodified-Since header, formatted as
+// SetIfModifiedSince does.
+func (r *Request) SetIfUnmodifiedSince(t time.Time) *Request {
+	r.headerParam.AddReplace(HeaderIfUnmodifiedSince, t.UTC().Format(http.TimeFormat))
+	return r
+}
+
+// SetHeaderTimeout bounds only the phase up to response headers -- time
+// to first byte -- rather than the whole request including the body
+// download. It composes with SetTimeout/SetDeadline, which still bound
+// the request as a whole; a header timeout firing returns
+// *ErrHeaderTimeout instead of the generic deadline-exceeded error, so
+// callers can tell a slow server apart from a slow download. It wraps the
+// client's transport the first time any request uses it, so like
+// Client.SetWireSizeAccounting, call it before other transport
+// configuration.
+func (r *Request) SetHeaderTimeout(d time.Duration) *Request {
+	r.headerTimeout = d
+	return r
+}
+
+// SetBodyIdleTimeout aborts the response body download if no bytes arrive
+// for d, independent of how long the download takes overall -- each
+// received chunk resets the clock. A timeout firing returns
+// *ErrBodyIdleTimeout. It wraps the client's transport the first time any
+// request uses it, same as SetHeaderTimeout.
+func (r *Request) SetBodyIdleTimeout(d time.Duration) *Request {
+	r.bodyIdleTimeout = d
+	return r
+}
+
+// SetRetry sets how many additional times a failed request is resent, as
+// decided by SetRetryCondition (or its default). A retry only happens if
+// the request body, if any, is rewindable -- see SetBodyProvider and the
+// *bytes.Reader/*strings.Reader/*os.File bodies RawRequest can rewind on
+// its own -- and stops immediately if the request's context is done.
+// count defaults to 0, meaning no retries.
+func (r *Request) SetRetry(count int) *Request {
+	r.retryCount = count
+	return r
+}
+
+// SetRetryBackoff sets the jittered exponential backoff waited between
+// retries: the nth retry waits a random duration in [0, base*2^(n-1)],
+// capped at max. The default, when SetRetry is used without this, is a
+// base of 100ms and a max of 10s.
+func (r *Request) SetRetryBackoff(base, max time.Duration) *Request {
+	r.retryBackoffBase = base
+	r.retryBackoffMax = max
+	return r
+}
+
+// SetRetryCondition overrides the function that decides whether a failed
+// attempt should be retried. resp is nil if the attempt failed before a
+// response was received (e.g. a network error), in which case err is
+// non-nil; otherwise err is nil and resp is the response to judge. The
+// default, used when this is never called, is defaultRetryCondition:
+// retry on a non-nil err, 429 Too Many Requests, or any 5xx status.
+func (r *Request) SetRetryCondition(cond func(*Response, error) bool) *Request {
+	r.retryCondition = cond
+	return r
+}
+
+// defaultRetryCondition is the retry condition used when SetRetry is
+// called without SetRetryCondition: it retries network errors and 429 or
+// 5xx responses.
+func defaultRetryCondition(resp *Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	code := resp.StatusCode()
+	return code == http.StatusTooManyRequests || code >= 500
+}
+
+// SetIdempotencyKey sets the Idempotency-Key header to key, a caller-
+// supplied value identifying this logical request so a server can dedupe
+// it if a retry (or a client-side resend after a timed-out response)
+// delivers it twice. Unlike WithAutoIdempotencyKey, key is fixed by the
+// caller, so it's up to them to keep it stable across whatever they
+// consider the same logical write and unique across different ones.
+func (r *Request) SetIdempotencyKey(key string) *Request {
+	r.idempotencyKey = key
+	r.autoIdempotencyKey = false
+	return r
+}
+
+// WithAutoIdempotencyKey has RawRequest generate a random UUIDv4 for the
+// Idempotency-Key header the first time it builds this request, then
+// reuse that same value for every SetRetry attempt of the same
+// execution -- RawRequest only ever builds a request once and every
+// retry resends the result, so the key generated for attempt 1 is the
+// one attempt 2, 3, ... carry too. Cloning the request (as every Do/Get/
+// Post/etc. call does before sending) resets this, so each logical call
+// gets its own key.
+func (r *Request) WithAutoIdempotencyKey() *Request {
+	r.autoIdempotencyKey = true
+	r.idempotencyKey = ""
+	return r
+}
+
+// SetContentLength overrides the Content-Length RawRequest sets on the
+// built request, taking precedence over whatever length, if any, it
+// would otherwise have detected for the body. Use it when a body's
+// reported length needs to differ from what fill() would compute, e.g. a
+// multipart body assembled by hand. SetChunked(true) still wins over this
+// if both are set, since the caller asked for chunked explicitly.
+func (r *Request) SetContentLength(n int64) *Request {
+	r.contentLength = &n
+	return r
+}
+
+// SetChunked forces (chunked=true) or stops forcing (chunked=false)
+// Transfer-Encoding: chunked on the built request, even for a body whose
+// length fill() knows, e.g. a bytes.Reader or *os.File -- some servers
+// want this regardless of whether the length happens to be known.
+func (r *Request) SetChunked(chunked bool) *Request {
+	r.chunked = chunked
+	return r
+}
+
+// SetFollowRedirect overrides, for this request only, whether redirects
+// are followed -- regardless of whether the client was built with
+// NoRedirect or a custom SetCheckRedirect. false makes a 3xx response
+// come back as a normal *Response, Location() populated, instead of
+// being followed or turned into an error. It's implemented with a
+// shallow copy of the client's http.Client carrying its own
+// CheckRedirect, so it never races a redirect policy shared with other
+// in-flight requests.
+func (r *Request) SetFollowRedirect(follow bool) *Request {
+	r.followRedirect = &follow
+	return r
+}
+
+// SetMaxRedirects overrides, for this request only, how many redirects
+// Client.SetMaxRedirects allows. Like SetFollowRedirect, it replaces the
+// client's redirect policy for this execution with a copy scoped to it.
+func (r *Request) SetMaxRedirects(n int) *Request {
+	r.maxRedirects = &n
+	return r
+}
+
+// SetProxyURL routes just this request through u, overriding the
+// client's own proxy (Client.SetProxyURL/SetProxyFunc) for this one
+// execution, for both plain HTTP and HTTPS (CONNECT) targets. It's
+// implemented with a clone of the client's transport carrying its own
+// Proxy func, so the override never races a proxy shared with other
+// in-flight requests. A nil u (the default) falls back to the client's
+// proxy.
+func (r *Request) SetProxyURL(u *url.URL) *Request {
+	r.proxyURL = u
+	return r
+}
+
+// SetProxyURLByStr is SetProxyURL, parsing rawurl first; a parse error is
+// reported via printError and leaves any previously set proxy in place.
+func (r *Request) SetProxyURLByStr(rawurl string) *Request {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		r.printError(err)
+		return r
+	}
+	return r.SetProxyURL(u)
+}
+
 // SetURLByStr sets URL in the client instance.
 func (r *Request) SetURLByStr(rawurl string) *Request {
 	r.SetURL(r.GetURL(rawurl))
@@ -118,17 +522,32 @@ func (r *Request) GetURL(rawurl string) *url.URL {
 	var err error
 	if r.baseURL == nil {
 		nu, err = url.Parse(rawurl)
+	} else if r.client.urlJoinMode == JoinPathAppend {
+		nu, err = joinPathAppend(r.baseURL, rawurl)
 	} else {
 		nu, err = r.baseURL.Parse(rawurl)
+		if err == nil {
+			if ref, refErr := url.Parse(rawurl); refErr == nil && joinDropsBasePath(r.baseURL.Path, ref.Path) {
+				r.printError(fmt.Errorf("requests: joining %q onto base path %q dropped the base path (resolved to %q); use Client.SetURLJoinMode(JoinPathAppend) to always preserve it", rawurl, r.baseURL.Path, nu.Path))
+			}
+		}
 	}
 	if err != nil {
-		r.client.printError(err)
+		r.deferError(err)
 	}
 	return nu
 }
 
-// SetContext sets context.Context for current Request.
+// SetContext sets context.Context for current Request. It replaces
+// whatever context SetTimeout/SetDeadline or an earlier SetContext put in
+// place; if that context owned a deadline cancel, it's released first so
+// it doesn't leak now that nothing will cancel it when the request
+// finishes.
 func (r *Request) SetContext(ctx context.Context) *Request {
+	if r.ctxCancel != nil {
+		r.ctxCancel()
+		r.ctxCancel = nil
+	}
 	r.ctx = ctx
 	return r
 }
@@ -143,7 +562,10 @@ func (r *Request) SetDeadline(d time.Time) *Request {
 	if r.ctx == nil {
 		r.ctx = context.TODO()
 	}
-	r.ctx, _ = context.WithDeadline(r.ctx, d)
+	if r.ctxCancel != nil {
+		r.ctxCancel()
+	}
+	r.ctx, r.ctxCancel = context.WithDeadline(r.ctx, d)
 	return r
 }
 
@@ -153,10 +575,31 @@ func (r *Request) withContext() {
 	}
 }
 
+// cancelContext releases the timer goroutine behind a deadline set via
+// SetTimeout/SetDeadline, if any. Client.do calls this once the response
+// body has been fully read (or the attempt has failed), so the context
+// doesn't just leak until the deadline it guarded against ever arrives.
+func (r *Request) cancelContext() {
+	if r.ctxCancel != nil {
+		r.ctxCancel()
+	}
+}
+
 func (r *Request) isCancelled() bool {
 	return r.ctx != nil && r.ctx.Err() != nil
 }
 
+// hasDeadline reports whether this request's context already carries a
+// deadline, e.g. from SetTimeout/SetDeadline, so Client.SetAdaptiveTimeout
+// knows not to override an explicit per-request timeout.
+func (r *Request) hasDeadline() bool {
+	if r.ctx == nil {
+		return false
+	}
+	_, ok := r.ctx.Deadline()
+	return ok
+}
+
 // SetHeader sets header field and its value in the current request.
 func (r *Request) SetHeader(param, value string) *Request {
 	//	param = textproto.CanonicalMIMEHeaderKey(param)
@@ -187,6 +630,99 @@ func (r *Request) AddHeaderIfNot(param, value string) *Request {
 	return r
 }
 
+// SetTrailer declares an HTTP trailer field and sets its value, to be
+// sent after the request body instead of with the initial headers --
+// for checksums or statuses only known once the body has been written.
+// Setting any trailer forces the request onto chunked transfer encoding,
+// per net/http. Like SetHeader, repeating the call replaces, not adds.
+func (r *Request) SetTrailer(param, value string) *Request {
+	r.trailerParam.AddReplace(param, value)
+	return r
+}
+
+// DeclareTrailer declares trailer field names with no value yet, for
+// servers that expect a field to be announced via the Trailer mechanism
+// even when it ends up empty. Call SetTrailer instead when the value is
+// already known.
+func (r *Request) DeclareTrailer(names ...string) *Request {
+	for _, name := range names {
+		r.trailerParam.AddNoRepeat(name, "")
+	}
+	return r
+}
+
+// SetHeaders sets multiple header fields at once, as SetHeader would for
+// each entry. Keys are canonicalized with textproto.CanonicalMIMEHeaderKey
+// and applied in sorted order so Message() output and RequestHash stay
+// stable across runs.
+func (r *Request) SetHeaders(headers map[string]string) *Request {
+	for _, param := range sortedMapKeys(headers) {
+		r.headerParam.AddReplace(textproto.CanonicalMIMEHeaderKey(param), headers[param])
+	}
+	return r
+}
+
+// AddHeaderMap adds multiple header fields at once, as AddHeader would for
+// each entry. Keys are canonicalized with textproto.CanonicalMIMEHeaderKey
+// and applied in sorted order so Message() output and RequestHash stay
+// stable across runs.
+func (r *Request) AddHeaderMap(headers map[string]string) *Request {
+	for _, param := range sortedMapKeys(headers) {
+		r.headerParam.Add(textproto.CanonicalMIMEHeaderKey(param), headers[param])
+	}
+	return r
+}
+
+// SetHeaderValues sets header fields from an http.Header, e.g. to copy
+// headers straight off an incoming request. Keys are applied in sorted
+// order so Message() output and RequestHash stay stable across runs.
+func (r *Request) SetHeaderValues(headers http.Header) *Request {
+	keys := make([]string, 0, len(headers))
+	for k := range headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, param := range keys {
+		values := headers[param]
+		if len(values) == 0 {
+			continue
+		}
+		param = textproto.CanonicalMIMEHeaderKey(param)
+		r.headerParam.AddReplace(param, values[0])
+		for _, v := range values[1:] {
+			r.headerParam.Add(param, v)
+		}
+	}
+	return r
+}
+
+// SetCookie adds a cookie to the current request. It accumulates rather
+// than replacing, so it composes with the client's cookie jar (if any):
+// both end up on the Cookie header via http.Request.AddCookie during
+// RawRequest, instead of one clobbering the other.
+func (r *Request) SetCookie(cookie *http.Cookie) *Request {
+	r.cookies = append(r.cookies, cookie)
+	return r
+}
+
+// SetCookies adds multiple cookies to the current request, as SetCookie
+// would for each entry. The Cookies helper turns a raw "a=1; b=2" string,
+// a single *http.Cookie or http.Cookie into the []*http.Cookie it expects,
+// e.g. req.SetCookies(requests.Cookies("a=1; b=2")).
+func (r *Request) SetCookies(cookies []*http.Cookie) *Request {
+	r.cookies = append(r.cookies, cookies...)
+	return r
+}
+
+func sortedMapKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // SetPath sets path parameter and its value in the current request.
 func (r *Request) SetPath(param, value string) *Request {
 	r.pathParam.AddReplace(param, value)
@@ -199,6 +735,63 @@ func (r *Request) AddPathIfNot(param, value string) *Request {
 	return r
 }
 
+// SetPaths sets multiple path parameters at once, as SetPath would for
+// each entry, applied in sorted order so Message() output and RequestHash
+// stay stable across runs. Values are substituted verbatim: a value
+// containing "/" or "%" will change the number of path segments or must
+// already be percent-encoded. Use SetPathsEscaped to run url.PathEscape
+// on every value first.
+func (r *Request) SetPaths(paths map[string]string) *Request {
+	for _, param := range sortedMapKeys(paths) {
+		r.pathParam.AddReplace(param, paths[param])
+	}
+	return r
+}
+
+// SetPathsEscaped is SetPaths, but runs url.PathEscape on every value
+// first, so a value like "a/b" substitutes as the single escaped segment
+// "a%2Fb" instead of silently splitting the path.
+func (r *Request) SetPathsEscaped(paths map[string]string) *Request {
+	for _, param := range sortedMapKeys(paths) {
+		r.pathParam.AddReplace(param, url.PathEscape(paths[param]))
+	}
+	return r
+}
+
+// SetPathEscaped is SetPath, but runs url.PathEscape on value first, so a
+// value like "a/b" substitutes as the single escaped segment "a%2Fb"
+// instead of silently splitting the path. The escaping happens here, so
+// toQuery and everything else downstream of toPath sees an already-correct
+// URL.
+func (r *Request) SetPathEscaped(param, value string) *Request {
+	r.pathParam.AddReplace(param, url.PathEscape(value))
+	return r
+}
+
+// SetPathStrict is SetPath, but rejects a value containing '/', '?' or '#':
+// substituted raw, any of those would change the URL's structure instead of
+// naming one path segment. The rejection is a deferred error, returned by
+// RawRequest/Do rather than here. Use SetPathEscaped instead to escape such
+// a value rather than reject it.
+func (r *Request) SetPathStrict(param, value string) *Request {
+	if strings.ContainsAny(value, "/?#") {
+		r.deferError(fmt.Errorf("requests: SetPathStrict: value for path parameter %q contains '/', '?' or '#': %q", param, value))
+		return r
+	}
+	r.pathParam.AddReplace(param, value)
+	return r
+}
+
+// AddPathsIfNot adds multiple path parameters at once, as AddPathIfNot
+// would for each entry, applied in sorted order so Message() output and
+// RequestHash stay stable across runs.
+func (r *Request) AddPathsIfNot(paths map[string]string) *Request {
+	for _, param := range sortedMapKeys(paths) {
+		r.pathParam.AddNoRepeat(param, paths[param])
+	}
+	return r
+}
+
 // SetQuery sets query parameter and its value in the current request.
 func (r *Request) SetQuery(param, value string) *Request {
 	r.queryParam.AddReplace(param, value)
@@ -225,6 +818,53 @@ func (r *Request) AddQueryIfNot(param, value string) *Request {
 	return r
 }
 
+// SetQueries sets multiple query parameters at once, as SetQuery would for
+// each entry, applied in sorted order so Message() output and RequestHash
+// stay stable across runs.
+func (r *Request) SetQueries(queries map[string]string) *Request {
+	for _, param := range sortedMapKeys(queries) {
+		r.queryParam.AddReplace(param, queries[param])
+	}
+	return r
+}
+
+// AddQueries adds multiple, possibly multi-valued query parameters at
+// once, as AddQuerys would for each entry, applied in sorted order so
+// Message() output and RequestHash stay stable across runs.
+func (r *Request) AddQueries(queries map[string][]string) *Request {
+	keys := make([]string, 0, len(queries))
+	for k := range queries {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, param := range keys {
+		r.AddQuerys(param, queries[param])
+	}
+	return r
+}
+
+// SetQueryValues sets query parameters from a url.Values, e.g. one parsed
+// from another URL, preserving repeated values for the same key. Applied
+// in sorted order so Message() output and RequestHash stay stable across
+// runs. It composes with query strings already absorbed from SetURL/
+// SetBaseURL, which are likewise stored in queryParam.
+func (r *Request) SetQueryValues(values url.Values) *Request {
+	return r.AddQueries(values)
+}
+
+// SetQueryString parses qs, a raw "a=1&b=2" query string (with or without
+// a leading "?"), and absorbs its parameters the same way SetQueryValues
+// does, preserving repeated keys. A malformed qs is reported via
+// printError and leaves the request's query parameters unchanged.
+func (r *Request) SetQueryString(qs string) *Request {
+	values, err := url.ParseQuery(strings.TrimPrefix(qs, "?"))
+	if err != nil {
+		r.printError(err)
+		return r
+	}
+	return r.SetQueryValues(values)
+}
+
 // SetForm sets multiple form parameters with multi-value
 func (r *Request) SetForm(param, value string) *Request {
 	r.formParam.AddReplace(param, value)
@@ -251,6 +891,39 @@ func (r *Request) AddFormIfNot(param, value string) *Request {
 	return r
 }
 
+// SetForms sets multiple form parameters at once, as SetForm would for each
+// entry, applied in sorted order so Message() output and RequestHash stay
+// stable across runs. Combined with SetFile, these end up as multipart
+// fields; otherwise they are urlencoded.
+func (r *Request) SetForms(forms map[string]string) *Request {
+	for _, param := range sortedMapKeys(forms) {
+		r.formParam.AddReplace(param, forms[param])
+	}
+	return r
+}
+
+// AddFormValues adds multiple, possibly multi-valued form parameters at
+// once, as AddForms would for each entry, applied in sorted order so
+// Message() output and RequestHash stay stable across runs.
+func (r *Request) AddFormValues(values url.Values) *Request {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, param := range keys {
+		r.AddForms(param, values[param])
+	}
+	return r
+}
+
+// SetFormValues sets form parameters from a url.Values, e.g. one parsed
+// from another URL, preserving repeated values for the same key. Applied in
+// sorted order so Message() output and RequestHash stay stable across runs.
+func (r *Request) SetFormValues(values url.Values) *Request {
+	return r.AddFormValues(values)
+}
+
 // SetFile sets custom data using io.Reader for multipart upload.
 func (r *Request) SetFile(param, fileName, contentType string, reader io.Reader) *Request {
 	r.multiFiles = append(r.multiFiles, &multiFile{
@@ -262,11 +935,67 @@ func (r *Request) SetFile(param, fileName, contentType string, reader io.Reader)
 	return r
 }
 
+// SetFilePart is like SetFile but lets the caller set the part's MIME
+// header directly, e.g. to add a Content-ID or Content-Transfer-Encoding,
+// or to override the computed Content-Disposition/Content-Type. Entries
+// in headers take precedence over SetFile's computed defaults; headers
+// may be nil, in which case SetFilePart behaves like SetFile.
+func (r *Request) SetFilePart(param, fileName string, headers textproto.MIMEHeader, reader io.Reader) *Request {
+	r.multiFiles = append(r.multiFiles, &multiFile{
+		Param:    param,
+		FileName: fileName,
+		Header:   headers,
+		Reader:   reader,
+	})
+	return r
+}
+
+// SetFileFromPath queues the file at path for multipart upload under the
+// given form field. Unlike SetFile, the file itself is opened lazily in
+// RawRequest and closed again once its content has been fully read onto
+// the request body, so callers don't need to open or close it themselves.
+// The filename sent on the wire is filepath.Base(path); the content type
+// is sniffed from the file's extension via mime.TypeByExtension, falling
+// back to "application/octet-stream" when it can't be determined.
+func (r *Request) SetFileFromPath(param, path string) *Request {
+	r.fileFromPaths = append(r.fileFromPaths, &fileFromPath{
+		Param: param,
+		Path:  path,
+	})
+	return r
+}
+
+// SetMultipartBoundary fixes the boundary used for a multipart/form-data
+// body instead of the random one multipart.Writer would otherwise choose,
+// so byte-identical logical requests produce byte-identical bodies (useful
+// for RequestHash-based caching and golden tests). b must be a legal RFC
+// 2046 boundary (1-70 bchars, not ending in a space); an invalid one is a
+// deferred error, returned by RawRequest/Do rather than here, and leaves
+// the request's boundary unchanged.
+func (r *Request) SetMultipartBoundary(b string) *Request {
+	if !validMultipartBoundary(b) {
+		r.deferError(fmt.Errorf("requests: invalid multipart boundary %q", b))
+		return r
+	}
+	r.multipartBoundary = b
+	r.multipartBoundaryAuto = false
+	return r
+}
+
+// SetDeterministicMultipartBoundary derives the multipart boundary from a
+// hash of the request's form field names/values and file field names/file
+// names, instead of letting multipart.Writer pick one at random.
+func (r *Request) SetDeterministicMultipartBoundary() *Request {
+	r.multipartBoundaryAuto = true
+	r.multipartBoundary = ""
+	return r
+}
+
 // SetJSON sets data encoded by JSON to the request body.
 func (r *Request) SetJSON(i interface{}) *Request {
 	data, err := json.Marshal(i)
 	if err != nil {
-		r.client.printError(err)
+		r.deferError(err)
 		return r
 	}
 	r.body = bytes.NewReader(data)
@@ -278,7 +1007,7 @@ func (r *Request) SetJSON(i interface{}) *Request {
 func (r *Request) SetXML(i interface{}) *Request {
 	data, err := xml.Marshal(i)
 	if err != nil {
-		r.client.printError(err)
+		r.deferError(err)
 		return r
 	}
 	r.body = bytes.NewReader(data)
@@ -286,12 +1015,208 @@ func (r *Request) SetXML(i interface{}) *Request {
 	return r
 }
 
+// SetYAML sets data encoded by YAML to the request body. A marshal error
+// is a deferred error, returned by RawRequest/Do rather than here, same
+// as SetJSON and SetXML.
+func (r *Request) SetYAML(i interface{}) *Request {
+	data, err := yaml.Marshal(i)
+	if err != nil {
+		r.deferError(err)
+		return r
+	}
+	r.body = bytes.NewReader(data)
+	r.AddHeaderIfNot(HeaderContentType, MimeYAML)
+	return r
+}
+
+// SetJSONStream sets the request body to i's JSON encoding, written via
+// json.Encoder over an io.Pipe as the request is sent instead of
+// marshaling the whole value into memory up front like SetJSON does. An
+// encoding error closes the pipe with that error, which net/http surfaces
+// as the error Do() returns.
+func (r *Request) SetJSONStream(i interface{}) *Request {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(json.NewEncoder(pw).Encode(i))
+	}()
+	r.body = pr
+	r.AddHeaderIfNot(HeaderContentType, MimeJSON)
+	return r
+}
+
+// SetNDJSON sets the request body to stream newline-delimited JSON, one
+// value per line, as values are received from ch, until ch closes. Like
+// SetJSONStream, encoding happens on a separate goroutine feeding an
+// io.Pipe; an encoding error closes the pipe with that error, which
+// net/http surfaces as the error Do() returns.
+func (r *Request) SetNDJSON(ch <-chan interface{}) *Request {
+	pr, pw := io.Pipe()
+	go func() {
+		enc := json.NewEncoder(pw)
+		var err error
+		for v := range ch {
+			if err = enc.Encode(v); err != nil {
+				break
+			}
+		}
+		pw.CloseWithError(err)
+	}()
+	r.body = pr
+	r.AddHeaderIfNot(HeaderContentType, MimeNDJSON)
+	return r
+}
+
+// SetPayload defers encoding v until the request is actually built,
+// choosing the encoder from this request's Content-Type header at that
+// point -- its own (set earlier via SetContentType, or absorbed from a
+// client default header) or, absent one, application/json. Recognized
+// media types are JSON, XML, YAML, and application/x-www-form-urlencoded
+// (encoded via struct "form" tags, see structToFormValues); anything else
+// fails RawRequest with an error naming the unsupported type. Because the
+// encoder is chosen on every call rather than once in SetPayload, a
+// cloned template can switch formats between executions just by changing
+// its Content-Type header.
+func (r *Request) SetPayload(v interface{}) *Request {
+	r.payload = v
+	return r
+}
+
+// encodePayload resolves SetPayload's deferred value into a body reader.
+func (r *Request) encodePayload() (io.Reader, error) {
+	mediatype := ""
+	if p, ok := r.headerParam.Search(HeaderContentType); ok {
+		mediatype, _, _ = mime.ParseMediaType(p.Value)
+	}
+
+	switch mediatype {
+	case "", "application/json":
+		data, err := json.Marshal(r.payload)
+		if err != nil {
+			return nil, err
+		}
+		r.AddHeaderIfNot(HeaderContentType, MimeJSON)
+		return bytes.NewReader(data), nil
+	case "application/xml", "text/xml":
+		data, err := xml.Marshal(r.payload)
+		if err != nil {
+			return nil, err
+		}
+		return bytes.NewReader(data), nil
+	case "application/yaml", "application/x-yaml", "text/yaml":
+		data, err := yaml.Marshal(r.payload)
+		if err != nil {
+			return nil, err
+		}
+		return bytes.NewReader(data), nil
+	case "application/x-www-form-urlencoded":
+		values, err := structToFormValues(r.payload)
+		if err != nil {
+			return nil, err
+		}
+		return strings.NewReader(values.Encode()), nil
+	default:
+		return nil, fmt.Errorf("requests: SetPayload: unsupported content type %q", mediatype)
+	}
+}
+
 // SetBody sets request body for the request.
 func (r *Request) SetBody(body io.Reader) *Request {
 	r.body = body
 	return r
 }
 
+// SetBodyString sets a plain string as the request body, defaulting
+// Content-Type to text/plain. Unlike SetBody, the body is backed by a
+// strings.Reader, so RawRequest can report its length as Content-Length
+// instead of falling back to chunked transfer encoding. Passing "" still
+// sets an explicit, empty body, so RawRequest's form-encoding fallback
+// (used when no body was ever set) is not triggered.
+func (r *Request) SetBodyString(s string) *Request {
+	r.body = strings.NewReader(s)
+	r.AddHeaderIfNot(HeaderContentType, MimeTextPlain)
+	return r
+}
+
+// SetBodyBytes sets a byte slice as the request body, defaulting
+// Content-Type to application/octet-stream. Unlike SetBody, the body is
+// backed by a bytes.Reader, so RawRequest can report its length as
+// Content-Length instead of falling back to chunked transfer encoding.
+func (r *Request) SetBodyBytes(b []byte) *Request {
+	r.body = bytes.NewReader(b)
+	r.AddHeaderIfNot(HeaderContentType, MimeOctetStream)
+	return r
+}
+
+// SetBodyProvider sets the request body to the io.ReadCloser provider
+// returns, calling it once to build the initial body and, via
+// http.Request.GetBody, again for every attempt the standard library
+// needs to replay: a 307/308 redirect, or a retried connection. Use this
+// over SetBody when the source needs to be rebuilt rather than merely
+// rewound, e.g. reopening a file or re-signing a payload per attempt.
+func (r *Request) SetBodyProvider(provider func() (io.ReadCloser, error)) *Request {
+	r.bodyProvider = provider
+	return r
+}
+
+// rewindableBodyGetter returns an http.Request.GetBody function that
+// lets the standard library replay body on a 307/308 redirect or a
+// retried connection, for the body types RawRequest knows how to safely
+// re-read from the start: *bytes.Reader and *strings.Reader (both
+// already detected by http.NewRequest too, but that detection is
+// defeated once a transformer wraps the body below) and *os.File (which
+// http.NewRequest never detects on its own). tr, if non-nil, is
+// re-applied on every replay so it matches what was actually sent. Any
+// other body type returns nil, leaving GetBody unset as before.
+func rewindableBodyGetter(body io.Reader, tr transform.Transformer) func() (io.ReadCloser, error) {
+	switch body.(type) {
+	case *bytes.Reader, *strings.Reader, *os.File:
+	default:
+		return nil
+	}
+	seeker := body.(io.Seeker)
+	return func() (io.ReadCloser, error) {
+		if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		rc := body
+		if tr != nil {
+			rc = transform.NewReader(body, tr)
+		}
+		return ioutil.NopCloser(rc), nil
+	}
+}
+
+// bodyPreviewer returns a func that reads up to limit bytes of body for
+// Request.Message's dump, leaving body exactly where it found it: it
+// saves the current offset, seeks to the start, reads the prefix, then
+// seeks back. That save-and-restore is the key difference from
+// rewindableBodyGetter's GetBody, which is safe to call only once the
+// live Body it shares state with has already been fully read (a retry
+// or redirect) -- calling it early, to build a preview before the real
+// send, would leave that shared reader sitting wherever the preview
+// stopped. It recognizes the same body types rewindableBodyGetter does:
+// *bytes.Reader, *strings.Reader and *os.File. Any other type returns
+// nil, leaving the body reported as not replayable.
+func bodyPreviewer(body io.Reader) func(limit int64) ([]byte, error) {
+	switch body.(type) {
+	case *bytes.Reader, *strings.Reader, *os.File:
+	default:
+		return nil
+	}
+	seeker := body.(io.ReadSeeker)
+	return func(limit int64) ([]byte, error) {
+		pos, err := seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, err
+		}
+		defer seeker.Seek(pos, io.SeekStart)
+		if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		return ioutil.ReadAll(io.LimitReader(seeker, limit))
+	}
+}
+
 // SetContentType sets content type header in the HTTP request.
 func (r *Request) SetContentType(contentType string) *Request {
 	r.SetHeader(HeaderContentType, contentType)
@@ -304,12 +1229,137 @@ func (r *Request) SetBasicAuth(username, password string) *Request {
 	return r
 }
 
+// SetBasicAuthUTF8 is SetBasicAuth, but goes through BasicAuthHeader
+// instead of the plain byte concatenation SetBasicAuth uses: a username
+// containing ':' is rejected rather than corrupting the credentials, and
+// username/password are normalized to NFC, per RFC 7617, for a server
+// that advertises charset="UTF-8" on its WWW-Authenticate challenge. The
+// rejection is a deferred error, returned by RawRequest/Do rather than
+// here. Use SetBasicAuth instead for a server that expects the older,
+// un-normalized byte-for-byte encoding.
+func (r *Request) SetBasicAuthUTF8(username, password string) *Request {
+	auth, err := BasicAuthHeader(username, password)
+	if err != nil {
+		r.deferError(err)
+		return r
+	}
+	r.SetHeader(HeaderAuthorization, "Basic "+auth)
+	return r
+}
+
 // SetAuthToken sets bearer auth token header in the HTTP request.
 func (r *Request) SetAuthToken(token string) *Request {
 	r.SetHeader(HeaderAuthorization, "Bearer "+token)
 	return r
 }
 
+// SetAccept sets the Accept header from an ordered list of preferred media
+// types, assigning descending q-values so the first type is most preferred.
+func (r *Request) SetAccept(types ...string) *Request {
+	if len(types) == 0 {
+		return r
+	}
+	parts := make([]string, len(types))
+	for i, mt := range types {
+		if i == 0 {
+			parts[i] = mt
+			continue
+		}
+		q := 1 - float64(i)*0.1
+		if q < 0.1 {
+			q = 0.1
+		}
+		parts[i] = fmt.Sprintf("%s;q=%.1f", mt, q)
+	}
+	r.SetHeader(HeaderAccept, strings.Join(parts, ", "))
+	return r
+}
+
+// AddAccept appends mediaType to the Accept header with an explicit
+// q-value, on top of whatever SetAccept or an earlier AddAccept already
+// put there, e.g. to declare a lower-priority fallback type. q must be
+// within [0, 1], formatted with up to three decimals per RFC 7231
+// section 5.3.1's qvalue grammar; an out-of-range q is reported via
+// printError and leaves the header unchanged.
+func (r *Request) AddAccept(mediaType string, q float64) *Request {
+	if q < 0 || q > 1 {
+		r.printError(fmt.Errorf("requests: AddAccept: q must be between 0 and 1, got %v", q))
+		return r
+	}
+	part := mediaType + ";q=" + formatQValue(q)
+	if p, ok := r.headerParam.Search(HeaderAccept); ok && p.Value != "" {
+		part = p.Value + ", " + part
+	}
+	r.SetHeader(HeaderAccept, part)
+	return r
+}
+
+// formatQValue formats q, already validated to be within [0, 1], as an
+// RFC 7231 qvalue: up to three decimal places, with trailing zeros (and a
+// trailing decimal point) trimmed.
+func formatQValue(q float64) string {
+	s := strconv.FormatFloat(q, 'f', 3, 64)
+	s = strings.TrimRight(s, "0")
+	s = strings.TrimRight(s, ".")
+	if s == "" {
+		s = "0"
+	}
+	return s
+}
+
+// SetAcceptJSON sets the Accept header to "application/json", the bare
+// media type without the charset suffix the Mime* constants carry, which
+// Accept has no use for.
+func (r *Request) SetAcceptJSON() *Request {
+	return r.SetAccept("application/json")
+}
+
+// SetAcceptXML sets the Accept header to "application/xml", the bare
+// media type without the charset suffix the Mime* constants carry, which
+// Accept has no use for.
+func (r *Request) SetAcceptXML() *Request {
+	return r.SetAccept("application/xml")
+}
+
+// ExpectContentType makes Do() fail with *ErrUnexpectedContentType when the
+// response's media type (ignoring parameters) is not one of types, which may
+// use "*" for the type or subtype, e.g. "application/*".
+func (r *Request) ExpectContentType(types ...string) *Request {
+	r.expectTypes = types
+	return r
+}
+
+// SetResult arranges for Do (and Get/Post/etc) to decode a 2xx response
+// body into v, a pointer, via Response.Decode -- so the content type
+// dispatch covers JSON, XML, YAML and any codec subpackage registered
+// with RegisterBodyDecoder the same way it would for a manual
+// resp.Decode(v) call. The populated v is also available afterwards from
+// Response.Result(). A decode failure comes back as *ErrDecodeResult,
+// which still carries the *Response so callers can read StatusCode().
+func (r *Request) SetResult(v interface{}) *Request {
+	r.resultTarget = v
+	return r
+}
+
+// SetError is like SetResult, but decodes into v when the response
+// status is 4xx or 5xx instead of 2xx. The populated v is also available
+// afterwards from Response.Error().
+func (r *Request) SetError(v interface{}) *Request {
+	r.errorTarget = v
+	return r
+}
+
+// SetSigner registers a signing hook RawRequest invokes with the fully
+// built *http.Request and its buffered body bytes, after path, query,
+// form/multipart and AWS SigV4 (if configured via SetAWSSigV4) assembly,
+// and before the cache key is computed, so a header the signer adds is
+// covered by cache lookups too. Multiple signers run in registration
+// order; any error aborts Do() with a wrapped error.
+func (r *Request) SetSigner(signer func(*http.Request, []byte) error) *Request {
+	r.signers = append(r.signers, signer)
+	return r
+}
+
 // SetUserAgent sets user agent header in the HTTP request.
 func (r *Request) SetUserAgent(ua string) *Request {
 	r.SetHeader(HeaderUserAgent, ua)
@@ -322,12 +1372,37 @@ func (r *Request) SetDiscardResponse(discard bool) *Request {
 	return r
 }
 
+// SetDoNotParseResponse leaves the response body unread and the
+// connection open instead of buffering it into Response.Body, so a
+// large download doesn't sit entirely in memory. Read the body from
+// Response.BodyStream/RawBody and call Response.Close when done with it
+// to let the connection be reused; methods that need the buffered body
+// (JSON, XML, YAML, Decode, WriteFile, Message) fail with
+// ErrBodyNotBuffered instead.
+func (r *Request) SetDoNotParseResponse(doNotParse bool) *Request {
+	r.doNotParseResponse = doNotParse
+	return r
+}
+
 // SetMethod sets method in the HTTP request.
 func (r *Request) SetMethod(method string) *Request {
 	r.method = strings.ToUpper(method)
 	return r
 }
 
+// SetAllowObsoleteLineFolding opts a request out of RawRequest's strict
+// header value validation for the legacy RFC 7230 line-folding form
+// (CRLF followed by a space or tab, used historically to wrap a long
+// header value across lines). It's off by default: folded values are
+// rejected like any other embedded CR/LF, since most servers and
+// intermediaries no longer support obs-fold and some treat it as a
+// smuggling vector. Enable it only if a server you don't control requires
+// sending a pre-folded value as-is.
+func (r *Request) SetAllowObsoleteLineFolding(allow bool) *Request {
+	r.allowObsoleteLineFolding = allow
+	return r
+}
+
 // Head does HEAD HTTP request.
 func (r *Request) Head(url string) (*Response, error) {
 	return r.Clone().SetMethod(MethodHead).SetURLByStr(url).do()
@@ -368,19 +1443,181 @@ func (r *Request) Patch(url string) (*Response, error) {
 	return r.Clone().SetMethod(MethodPatch).SetURLByStr(url).do()
 }
 
+// depthHeaderValue renders a WebDAV Depth value: "0" and "1" as given,
+// and any negative depth as "infinity" per RFC 4918 section 10.2.
+func depthHeaderValue(depth int) string {
+	if depth < 0 {
+		return "infinity"
+	}
+	return strconv.Itoa(depth)
+}
+
+// Propfind does a WebDAV PROPFIND HTTP request (RFC 4918 section 9.1),
+// setting the Depth header. Pass a negative depth for "infinity".
+func (r *Request) Propfind(url string, depth int) (*Response, error) {
+	return r.Clone().SetMethod(MethodPropfind).SetHeader(HeaderDepth, depthHeaderValue(depth)).SetURLByStr(url).do()
+}
+
+// Proppatch does a WebDAV PROPPATCH HTTP request (RFC 4918 section 9.2).
+func (r *Request) Proppatch(url string) (*Response, error) {
+	return r.Clone().SetMethod(MethodProppatch).SetURLByStr(url).do()
+}
+
+// Mkcol does a WebDAV MKCOL HTTP request (RFC 4918 section 9.3),
+// creating a collection (directory) at url.
+func (r *Request) Mkcol(url string) (*Response, error) {
+	return r.Clone().SetMethod(MethodMkcol).SetURLByStr(url).do()
+}
+
+// Copy does a WebDAV COPY HTTP request (RFC 4918 section 9.8), setting
+// the Destination header to destination.
+func (r *Request) Copy(url, destination string) (*Response, error) {
+	return r.Clone().SetMethod(MethodCopy).SetHeader(HeaderDestination, destination).SetURLByStr(url).do()
+}
+
+// Move does a WebDAV MOVE HTTP request (RFC 4918 section 9.9), setting
+// the Destination header to destination.
+func (r *Request) Move(url, destination string) (*Response, error) {
+	return r.Clone().SetMethod(MethodMove).SetHeader(HeaderDestination, destination).SetURLByStr(url).do()
+}
+
+// Lock does a WebDAV LOCK HTTP request (RFC 4918 section 9.10).
+func (r *Request) Lock(url string) (*Response, error) {
+	return r.Clone().SetMethod(MethodLock).SetURLByStr(url).do()
+}
+
+// Unlock does a WebDAV UNLOCK HTTP request (RFC 4918 section 9.11),
+// setting the Lock-Token header to the opaque lock token returned by an
+// earlier Lock call.
+func (r *Request) Unlock(url, lockToken string) (*Response, error) {
+	return r.Clone().SetMethod(MethodUnlock).SetHeader(HeaderLockToken, lockToken).SetURLByStr(url).do()
+}
+
+// Report does a DAV REPORT HTTP request (RFC 3253 section 3.6), commonly
+// used by CalDAV/CardDAV servers such as Nextcloud for versioned or
+// filtered queries a plain PROPFIND can't express.
+func (r *Request) Report(url string) (*Response, error) {
+	return r.Clone().SetMethod(MethodReport).SetURLByStr(url).do()
+}
+
 // NoCache Clear the cache for this request
 func (r *Request) NoCache() *Request {
 	r.noCache = true
 	return r
 }
 
+// SetLogLevel overrides the client's log level for this request only. The
+// override is carried through Clone, so it applies to every retry/execution
+// of a cloned template.
+func (r *Request) SetLogLevel(l logLevel) *Request {
+	r.logLevel = &l
+	return r
+}
+
+// SetQuiet suppresses all logging for this request, equivalent to
+// SetLogLevel(LogIgnore).
+func (r *Request) SetQuiet() *Request {
+	return r.SetLogLevel(LogIgnore)
+}
+
+// effectiveLogLevel returns the log level that applies to this request:
+// its own override if set, otherwise the client's level.
+func (r *Request) effectiveLogLevel() logLevel {
+	if r.logLevel != nil {
+		return *r.logLevel
+	}
+	return r.client.logLevel
+}
+
+// printError logs err at this request's effective log level.
+func (r *Request) printError(err error) {
+	r.client.printErrorLevel(err, r.effectiveLogLevel())
+}
+
+// deferError logs err like printError, and also records it as the
+// request's deferredErr if nothing has claimed that slot yet, so
+// RawRequest/Do returns it up front instead of going on to build a
+// nonsense request or hit the network.
+func (r *Request) deferError(err error) {
+	r.printError(err)
+	if r.deferredErr == nil {
+		r.deferredErr = err
+	}
+}
+
 // Do performs the HTTP request
 func (r *Request) Do() (*Response, error) {
 	return r.Clone().do()
 }
 
+// DoContext performs the HTTP request like Do, but attaches ctx for just
+// this one execution: it clones the request first, so the clone's
+// SetContext(ctx) -- and whatever cancel that releases, per SetContext's
+// doc comment -- never touches r (or any other clone of it). If r's
+// template already carries a context, e.g. from SetContext or
+// SetTimeout, ctx replaces it outright for this call rather than being
+// merged with it; cancelling ctx propagates to the underlying
+// http.Request and comes back wrapped in the returned error, same as any
+// other context cancellation during a Do.
+func (r *Request) DoContext(ctx context.Context) (*Response, error) {
+	return r.Clone().SetContext(ctx).do()
+}
+
+// GetCtx does a GET HTTP request with ctx attached for this execution,
+// like DoContext.
+func (r *Request) GetCtx(ctx context.Context, url string) (*Response, error) {
+	return r.Clone().SetContext(ctx).SetMethod(MethodGet).SetURLByStr(url).do()
+}
+
+// PostCtx does a POST HTTP request with ctx attached for this execution,
+// like DoContext.
+func (r *Request) PostCtx(ctx context.Context, url string) (*Response, error) {
+	return r.Clone().SetContext(ctx).SetMethod(MethodPost).SetURLByStr(url).do()
+}
+
+// PutCtx does a PUT HTTP request with ctx attached for this execution,
+// like DoContext.
+func (r *Request) PutCtx(ctx context.Context, url string) (*Response, error) {
+	return r.Clone().SetContext(ctx).SetMethod(MethodPut).SetURLByStr(url).do()
+}
+
+// DeleteCtx does a DELETE HTTP request with ctx attached for this
+// execution, like DoContext.
+func (r *Request) DeleteCtx(ctx context.Context, url string) (*Response, error) {
+	return r.Clone().SetContext(ctx).SetMethod(MethodDelete).SetURLByStr(url).do()
+}
+
 func (r *Request) do() (*Response, error) {
-	return r.client.do(r)
+	resp, err := r.client.do(r)
+	if err != nil {
+		return resp, err
+	}
+	if len(r.expectTypes) > 0 {
+		mediatype, _, err := mime.ParseMediaType(resp.ContentType())
+		if err != nil || !matchMediaType(mediatype, r.expectTypes) {
+			return resp, &ErrUnexpectedContentType{
+				ContentType: mediatype,
+				Allowed:     r.expectTypes,
+				Response:    resp,
+			}
+		}
+	}
+	if !r.skipResponseValidation && !r.discardResponse {
+		for _, v := range r.client.responseValidators {
+			if err := v(r, resp); err != nil {
+				return resp, err
+			}
+		}
+	}
+	if r.resultTarget != nil || r.errorTarget != nil {
+		if err := resp.decodeResultOrError(r.resultTarget, r.errorTarget); err != nil {
+			return resp, err
+		}
+	}
+	if r.effectiveErrorOnStatus() && (resp.IsClientError() || resp.IsServerError()) {
+		return resp, NewStatusError(resp)
+	}
+	return resp, nil
 }
 
 func (r *Request) processURL() (*url.URL, error) {
@@ -388,17 +1625,25 @@ func (r *Request) processURL() (*url.URL, error) {
 	if u == nil {
 		u = &url.URL{}
 	}
+	fragment := r.fragment
+	if fragment == "" {
+		fragment = u.Fragment
+	}
+
 	q := []string{}
 	// fill path
+	path := u.Path
 	if len(r.pathParam) != 0 {
-		path, err := toPath(u.Path, r.pathParam, r.transformer)
+		p, err := toPath(path, r.pathParam, r.transformer)
 		if err != nil {
 			return nil, err
 		}
-		q = append(q, path)
-	} else {
-		q = append(q, u.Path)
+		path = p
 	}
+	if len(r.matrixParams) != 0 {
+		path = applyMatrixParams(path, r.matrixParams)
+	}
+	q = append(q, path)
 
 	// fill query
 	if len(r.queryParam) != 0 {
@@ -408,21 +1653,119 @@ func (r *Request) processURL() (*url.URL, error) {
 		}
 		q = append(q, rq)
 	}
-	return u.Parse(strings.Join(q, "?"))
+
+	// u.Parse (URL.ResolveReference) drops the base's fragment when the
+	// relative reference being resolved has none of its own, since a
+	// fragment isn't part of relative-reference merging per RFC 3986 --
+	// so it has to be re-applied explicitly rather than carried through.
+	resolved, err := u.Parse(strings.Join(q, "?"))
+	if err != nil {
+		return nil, err
+	}
+	resolved.Fragment = fragment
+	return resolved, nil
 }
 
+// RawRequest builds this Request into a ready-to-send *http.Request,
+// running every Set*/Add* call's effect through fill() once and attaching
+// the context set via SetContext/SetTimeout/SetDeadline. The result is
+// memoized: calling RawRequest again, or sending r through Do, returns the
+// same *http.Request rather than building a second one, which is also what
+// lets a retried or redirected send resend identical bytes. Call
+// r.Clone().RawRequest() instead if r itself needs to stay a reusable
+// template.
+//
+// The returned request's body is a one-shot io.Reader unless it's backed
+// by bytes, a string or a file, or was set with SetBodyProvider -- those
+// cases also get a working GetBody, so the body can be read more than
+// once (e.g. by a library that signs the request before handing it to an
+// http.Client). Anything else drains on the first read, same as any other
+// *http.Request.
 func (r *Request) RawRequest() (*http.Request, error) {
 	if r.rawRequest != nil {
 		return r.rawRequest, nil
 	}
+	if r.deferredErr != nil {
+		return nil, r.deferredErr
+	}
+	if err := validateMethod(r.method); err != nil {
+		return nil, err
+	}
+	if err := validateHost(r.host); err != nil {
+		return nil, err
+	}
+
 	u, err := r.processURL()
 	if err != nil {
 		return nil, err
 	}
 	r.baseURL = u
+
+	if len(r.fileFromPaths) != 0 {
+		opened := make([]*os.File, 0, len(r.fileFromPaths))
+		defer func() {
+			for _, f := range opened {
+				f.Close()
+			}
+		}()
+		for _, f := range r.fileFromPaths {
+			fh, err := os.Open(f.Path)
+			if err != nil {
+				return nil, err
+			}
+			opened = append(opened, fh)
+			contentType := mime.TypeByExtension(filepath.Ext(f.Path))
+			if contentType == "" {
+				contentType = "application/octet-stream"
+			}
+			r.multiFiles = append(r.multiFiles, &multiFile{
+				Param:       f.Param,
+				FileName:    filepath.Base(f.Path),
+				ContentType: contentType,
+				Reader:      fh,
+			})
+		}
+		r.fileFromPaths = nil
+	}
+
+	if r.payload != nil {
+		body, err := r.encodePayload()
+		if err != nil {
+			return nil, err
+		}
+		r.body = body
+	}
+
+	var getBody func() (io.ReadCloser, error)
+	fileContentLength := int64(-1)
+	if r.bodyProvider != nil {
+		body, err := r.bodyProvider()
+		if err != nil {
+			return nil, err
+		}
+		r.body = body
+		getBody = r.bodyProvider
+		// r.bodyProvider builds a brand new ReadCloser on every call, so
+		// unlike rewindableBodyGetter's GetBody below, calling it early
+		// for a Message preview doesn't touch the one already in flight.
+		provider := r.bodyProvider
+		r.bodyPreview = func(limit int64) ([]byte, error) {
+			rc, err := provider()
+			if err != nil {
+				return nil, err
+			}
+			defer rc.Close()
+			return ioutil.ReadAll(io.LimitReader(rc, limit))
+		}
+	}
+
 	if r.body == nil {
 		if len(r.multiFiles) != 0 { // fill multpair
-			body, contentType, err := toMulti(r.formParam, r.multiFiles, r.transformer)
+			boundary := r.multipartBoundary
+			if r.multipartBoundaryAuto {
+				boundary = deriveMultipartBoundary(r.formParam, r.multiFiles)
+			}
+			body, contentType, err := toMulti(r.formParam, r.multiFiles, r.transformer, boundary)
 			if err != nil {
 				return nil, err
 			}
@@ -437,6 +1780,28 @@ func (r *Request) RawRequest() (*http.Request, error) {
 			r.body = body
 		}
 	} else {
+		if getBody == nil && r.compressEncoding == "" {
+			if r.bodyPreview == nil {
+				r.bodyPreview = bodyPreviewer(r.body)
+			}
+			if g := rewindableBodyGetter(r.body, r.transformer); g != nil {
+				getBody = g
+				if f, ok := r.body.(*os.File); ok {
+					// Stat it before wrapping: http.NewRequest detects
+					// *os.File's length itself, but only if the body it
+					// sees is still a bare *os.File, and the NopCloser
+					// wrap just below (needed to keep the fd open across
+					// retries) would otherwise hide that from it.
+					if fi, err := f.Stat(); err == nil {
+						fileContentLength = fi.Size()
+					}
+					// Keep the fd open across retries: http.NewRequest
+					// would otherwise hand it to the Transport as-is,
+					// which closes it after the first attempt.
+					r.body = ioutil.NopCloser(f)
+				}
+			}
+		}
 		if r.transformer != nil {
 			r.body = transform.NewReader(r.body, r.transformer)
 		}
@@ -452,17 +1817,68 @@ func (r *Request) RawRequest() (*http.Request, error) {
 		}
 	}
 
+	if r.body != nil && r.compressEncoding != "" {
+		compressed, err := compressBodyStream(r.compressEncoding, r.body)
+		if err != nil {
+			return nil, err
+		}
+		r.body = compressed
+		r.AddHeaderIfNot(HeaderContentEncoding, r.compressEncoding)
+	}
+
 	req, err := http.NewRequest(r.method, r.baseURL.String(), r.body)
 	if err != nil {
 		return nil, err
 	}
+	if getBody != nil && r.compressEncoding == "" {
+		req.GetBody = getBody
+	}
+	if fileContentLength >= 0 && req.ContentLength == 0 {
+		req.ContentLength = fileContentLength
+	}
+	if r.contentLength != nil {
+		req.ContentLength = *r.contentLength
+	}
+	if r.chunked {
+		req.ContentLength = -1
+	}
+	if r.host != "" {
+		req.Host = r.host
+	}
 
 	// fill header
 	r.AddHeaderIfNot(HeaderUserAgent, DefaultUserAgentValue)
+	if r.idempotencyKey == "" && r.autoIdempotencyKey {
+		key, err := newUUIDv4()
+		if err != nil {
+			return nil, err
+		}
+		r.idempotencyKey = key
+	}
+	if r.idempotencyKey != "" {
+		r.AddHeaderIfNot(HeaderIdempotencyKey, r.idempotencyKey)
+	}
 	header, err := toHeader(req.Header, r.headerParam, r.transformer)
 	if err != nil {
 		return nil, err
 	}
+	if err := validateHeader(header, r.allowObsoleteLineFolding); err != nil {
+		return nil, err
+	}
+
+	// fill trailer
+	if len(r.trailerParam) != 0 {
+		trailer, err := toHeader(make(http.Header), r.trailerParam, r.transformer)
+		if err != nil {
+			return nil, err
+		}
+		req.Trailer = trailer
+		// A trailer can only travel over chunked transfer encoding: an
+		// identity body with a known Content-Length has nowhere to put
+		// it, so net/http silently drops it. ContentLength -1 makes
+		// http.Request.Write pick chunked automatically.
+		req.ContentLength = -1
+	}
 
 	if r.client.proxyFromEnv {
 		u, err := http.ProxyFromEnvironment(req)
@@ -472,21 +1888,149 @@ func (r *Request) RawRequest() (*http.Request, error) {
 		r.client.SetProxyURL(u)
 	}
 
+	var cookieParts []string
+	if r.client.cli.Jar != nil {
+		for _, c := range r.client.cli.Jar.Cookies(req.URL) {
+			cookieParts = append(cookieParts, c.Name+"="+c.Value)
+		}
+	}
+	for _, c := range r.cookies {
+		cookieParts = append(cookieParts, c.Name+"="+c.Value)
+	}
+	cookieHeader := strings.Join(cookieParts, "; ")
+	if err := r.client.checkHeaderLimits(header, cookieHeader); err != nil {
+		return nil, err
+	}
+
 	req.Header = header
+	for _, c := range r.cookies {
+		req.AddCookie(c)
+	}
+
+	if r.awsSigV4 != nil {
+		if err := awsSigV4SignRequest(req, r.body, r.awsSigV4); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(r.signers) != 0 {
+		body, err := readAndRestoreBody(req)
+		if err != nil {
+			return nil, err
+		}
+		for _, signer := range r.signers {
+			if err := signer(req, body); err != nil {
+				return nil, fmt.Errorf("requests: signer failed: %w", err)
+			}
+		}
+	}
+
 	r.rawRequest = req
 
+	if r.client.connLabeler != nil {
+		ctx := r.ctx
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		r.ctx = context.WithValue(ctx, connLabelContextKey, r.client.connLabeler(req))
+	}
+
+	if r.client.wireSizeAccounting {
+		r.wireSize = &WireSize{}
+		ctx := r.ctx
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		r.ctx = context.WithValue(ctx, wireSizeContextKey, r.wireSize)
+	}
+
+	if r.debugWriter != nil {
+		if err := r.client.ensureDebugTransport(); err != nil {
+			return nil, err
+		}
+		ctx := r.ctx
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		r.ctx = context.WithValue(ctx, debugDumpContextKey, &debugDump{w: r.debugWriter, mu: &r.client.debugMu})
+	}
+
+	if r.headerTimeout > 0 || r.bodyIdleTimeout > 0 {
+		if err := r.client.ensurePhaseTimeoutTransport(); err != nil {
+			return nil, err
+		}
+		ctx := r.ctx
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		r.ctx = context.WithValue(ctx, phaseTimeoutContextKey, &phaseTimeouts{header: r.headerTimeout, bodyIdle: r.bodyIdleTimeout})
+	}
+
+	{
+		ctx := r.ctx
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		r.ctx = context.WithValue(ctx, redirectHistoryContextKey, &r.redirectHistory)
+	}
+
+	if r.traceEnabled {
+		ctx := r.ctx
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		ctx, ts := withClientTrace(ctx)
+		r.traceState = ts
+		r.ctx = ctx
+	}
+
 	r.withContext()
-	return req, nil
+	return r.rawRequest, nil
+}
+
+// readAndRestoreBody reads req's body, if any, and puts an equivalent
+// NopCloser back so the real send still sees the full content.
+func readAndRestoreBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	req.Body.Close()
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return body, nil
 }
 
-func (r *Request) messageBody() []byte {
-	if r.rawRequest.Body == nil {
+// messageBody returns up to the client's SetLogBodyLimit bytes of req's
+// body for Message to dump, without ever reading from or otherwise
+// touching req.Body itself. It only does this when r.bodyPreview is set
+// -- RawRequest arranges that for any body it knows how to re-read
+// without disturbing it: bytes/string/file-backed or SetBodyProvider.
+// A body backed by an arbitrary io.Reader (bodyPreview == nil) is
+// reported as omitted rather than risking blocking on, partially
+// draining, or otherwise corrupting a live, unbuffered stream.
+func (r *Request) messageBody(req *http.Request) []byte {
+	if req.Body == nil {
 		return nil
 	}
-	body, _ := ioutil.ReadAll(r.rawRequest.Body)
-	r.rawRequest.Body.Close()
-	r.rawRequest.Body = ioutil.NopCloser(bytes.NewReader(body))
-	return body
+	if r.bodyPreview == nil {
+		return []byte("\n[body omitted: not replayable]\n")
+	}
+
+	limit := r.client.logBodyLimit
+	if limit <= 0 {
+		limit = defaultLogBodyLimit
+	}
+	b, err := r.bodyPreview(int64(limit) + 1)
+	if err != nil {
+		return nil
+	}
+	if len(b) > limit {
+		b = append(b[:limit], []byte(fmt.Sprintf("\n[body truncated at %d bytes]\n", limit))...)
+	}
+	return b
 }
 
 // String returns the HTTP request basic information
@@ -505,7 +2049,8 @@ func (r *Request) MessageHead() string {
 }
 
 func (r *Request) message(body bool) string {
-	req, err := r.Clone().RawRequest()
+	clone := r.Clone()
+	req, err := clone.RawRequest()
 	if err != nil {
 		return err.Error()
 	}
@@ -522,7 +2067,7 @@ func (r *Request) message(body bool) string {
 	}
 
 	if body {
-		b = append(b, r.messageBody()...)
+		b = append(b, clone.messageBody(req)...)
 	}
 	return string(b)
 }
@@ -0,0 +1,45 @@
+package requests
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestRequestLogLevelOverride(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {})
+
+	buf := &bytes.Buffer{}
+	cli := NewClient()
+	cli.log = log.New(buf, "", 0)
+	cli.SetLogLevel(LogInfo)
+
+	_, err = cli.NewRequest().SetURLByStr(mock.URL()).SetQuiet().Get("")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected no log output for quiet request, got %q", buf.String())
+	}
+
+	buf.Reset()
+	_, err = cli.NewRequest().SetURLByStr(mock.URL()).SetLogLevel(LogMessageHead).Get("")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !strings.Contains(buf.String(), "GET") || !strings.Contains(buf.String(), "HTTP/1.1") {
+		t.Errorf("expected escalated message-head logging, got %q", buf.String())
+	}
+}
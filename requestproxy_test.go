@@ -0,0 +1,119 @@
+package requests
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestSetProxyURLRoutesHTTPRequestInAbsoluteForm(t *testing.T) {
+	var recordedMethod, recordedURL string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recordedMethod = r.Method
+		recordedURL = r.URL.String()
+		w.Write([]byte("via-proxy"))
+	}))
+	defer proxy.Close()
+	proxyURL, err := url.Parse(proxy.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := NewRequest().SetProxyURL(proxyURL).Get("http://example.invalid/path")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recordedMethod != MethodGet {
+		t.Errorf("proxy saw method %q, want GET", recordedMethod)
+	}
+	if !strings.Contains(recordedURL, "example.invalid/path") {
+		t.Errorf("proxy saw request-target %q, want it in absolute form naming example.invalid", recordedURL)
+	}
+	if got := string(resp.Body()); got != "via-proxy" {
+		t.Errorf("body = %q, want the proxy's own response", got)
+	}
+}
+
+func TestSetProxyURLUsesConnectForHTTPSTarget(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	recorded := make(chan *http.Request, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		req, err := http.ReadRequest(bufio.NewReader(conn))
+		if err != nil {
+			return
+		}
+		recorded <- req
+		// Refuse the tunnel -- the test only cares that CONNECT was sent
+		// to the right host, not that the request actually completes.
+		conn.Write([]byte("HTTP/1.1 502 Bad Gateway\r\n\r\n"))
+	}()
+
+	proxyURL := &url.URL{Scheme: "http", Host: ln.Addr().String()}
+	_, err = NewRequest().SetProxyURL(proxyURL).Get("https://example.invalid/path")
+	if err == nil {
+		t.Fatal("expected an error, since the CONNECT tunnel was refused")
+	}
+
+	select {
+	case req := <-recorded:
+		if req.Method != http.MethodConnect {
+			t.Errorf("proxy saw method %q, want CONNECT", req.Method)
+		}
+		if req.Host != "example.invalid:443" {
+			t.Errorf("CONNECT Host = %q, want example.invalid:443", req.Host)
+		}
+	default:
+		t.Fatal("proxy never received a request")
+	}
+}
+
+func TestSetProxyURLFallsBackToClientProxyWhenUnset(t *testing.T) {
+	var calls int
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Write([]byte("via-client-proxy"))
+	}))
+	defer proxy.Close()
+	proxyURL, err := url.Parse(proxy.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := NewClient().SetProxyURL(proxyURL)
+	resp, err := client.NewRequest().Get("http://example.invalid/path")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+	if got := string(resp.Body()); got != "via-client-proxy" {
+		t.Errorf("body = %q, want the client proxy's own response", got)
+	}
+}
+
+func TestSetProxyURLByStrParseErrorIsLogged(t *testing.T) {
+	var logBuf bytes.Buffer
+	client := NewClient().SetLogger(&logBuf)
+	req := client.NewRequest().SetProxyURLByStr("http://%zz")
+	if req.proxyURL != nil {
+		t.Errorf("proxyURL = %v, want nil after a parse error", req.proxyURL)
+	}
+	if logBuf.Len() == 0 {
+		t.Error("expected the parse error to be logged")
+	}
+}
@@ -2,13 +2,21 @@ package requests
 
 import (
 	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"mime"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"strconv"
+	"strings"
 	"time"
+
+	"gopkg.in/yaml.v2"
 )
 
 // Response is an object represents executed request and its values.
@@ -19,14 +27,105 @@ type Response struct {
 	method      string
 	sendAt      time.Time
 	recvAt      time.Time
+	stubbed     bool
+	attempts    int
+	client      *Client
+	wireSize    *WireSize
+
+	charset          string
+	rawUndecodedBody []byte
+
+	config *ConfigSnapshot
+
+	trailer http.Header
+
+	jsonrpcID       json.RawMessage
+	jsonrpcBatchIDs []json.RawMessage
+
+	result      interface{}
+	errorResult interface{}
+
+	streamed   bool
+	streamBody io.Reader
+
+	maxResponseSize int64
+
+	contentEncoding string
+
+	downloadProgress DownloadProgressFunc
+
+	redirectHistory []*url.URL
+	finalURL        *url.URL
+
+	traceInfo TraceInfo
+}
+
+// ClientConfig returns the client's configuration as it stood when this
+// response's request was sent: timeout, proxy, TLS mode, redirect policy,
+// base URL and default headers (secrets redacted). It's nil for responses
+// built by NewStubResponse.
+func (r *Response) ClientConfig() *ConfigSnapshot {
+	return r.config
+}
+
+// Attempts returns how many times the request was sent to produce this
+// response: 1 normally, plus one for each retry allowed by
+// Request.SetRetry and one for a 417 Expectation Failed retried without
+// the Expect header (see Request.SetExpectContinue).
+func (r *Response) Attempts() int {
+	if r.attempts == 0 {
+		return 1
+	}
+	return r.attempts
+}
+
+// WireSizes returns the bytes sent for the request and received for the
+// response, measured on the wire (headers and body, post-compression), for
+// billing or quota purposes. Both are 0 unless the client was set up with
+// Client.SetWireSizeAccounting(true) before the request was sent.
+func (r *Response) WireSizes() (sent, received int64) {
+	return r.wireSize.Sent(), r.wireSize.Received()
 }
 
-func newResponse(resp *http.Response) (*Response, error) {
+// Stubbed reports whether this response was produced by a Client.StubResponse
+// rule instead of being fetched (or loaded from cache).
+func (r *Response) Stubbed() bool {
+	return r.stubbed
+}
+
+// NewStubResponse builds a *Response suitable for returning from a
+// Client.StubResponse build function, without going through an actual
+// http.Response.
+func NewStubResponse(statusCode int, body []byte, header http.Header) *Response {
+	if header == nil {
+		header = http.Header{}
+	}
+	return &Response{
+		rawResponse: &http.Response{
+			StatusCode: statusCode,
+			Status:     http.StatusText(statusCode),
+			Header:     header,
+		},
+		body:    body,
+		stubbed: true,
+	}
+}
+
+func newResponse(resp *http.Response, client *Client, streamed bool, maxResponseSize int64, downloadProgress DownloadProgressFunc) (*Response, error) {
 	r := &Response{
-		rawResponse: resp,
+		rawResponse:      resp,
+		client:           client,
+		streamed:         streamed,
+		maxResponseSize:  maxResponseSize,
+		downloadProgress: downloadProgress,
 	}
 	err := r.process()
 	if err != nil {
+		if _, ok := err.(*ErrResponseTooLarge); ok {
+			// The partial body read so far is still useful for
+			// diagnostics, so hand back r instead of discarding it.
+			return r, err
+		}
 		return nil, err
 	}
 	return r, nil
@@ -46,11 +145,6 @@ func (r *Response) Location() *url.URL {
 	return r.location
 }
 
-// WriteFile is writes the response body to file.
-func (r *Response) WriteFile(file string) error {
-	return ioutil.WriteFile(file, r.body, 0666)
-}
-
 // Body returns HTTP response as []byte array for the executed request.
 func (r *Response) Body() []byte {
 	return r.body
@@ -61,6 +155,260 @@ func (r *Response) ContentType() string {
 	return r.rawResponse.Header.Get(HeaderContentType)
 }
 
+// ContentEncoding returns the Content-Encoding the server declared (e.g.
+// "gzip"), even though process() already undid it and Body/Size reflect
+// the decompressed bytes. Empty when the response wasn't compressed, or
+// named an encoding with no registered decoder (see RegisterEncoding).
+func (r *Response) ContentEncoding() string {
+	return r.contentEncoding
+}
+
+// XML decodes the response body as XML into v, operating on the body
+// after process()'s charset conversion, so e.g. an ISO-8859-1-declared
+// document decodes correctly even though v's fields are plain Go strings.
+// If decoding fails, the returned error names the innermost element that
+// was open when the failure occurred, in addition to the underlying
+// encoding/xml error.
+func (r *Response) XML(v interface{}) error {
+	if r.streamBody != nil {
+		return ErrBodyNotBuffered
+	}
+	dec := xml.NewDecoder(bytes.NewReader(r.body))
+	// r.body has already been transcoded to UTF-8 by process()'s call to
+	// TryCharset, but the XML prolog, if any, still names the original
+	// encoding; without this, Decoder would refuse to decode a non-UTF-8
+	// declared charset itself.
+	dec.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+	if err := dec.Decode(v); err != nil {
+		if elem := xmlFailingElement(r.body, dec.InputOffset()); elem != "" {
+			return fmt.Errorf("requests: XML: decoding element <%s>: %w", elem, err)
+		}
+		return fmt.Errorf("requests: XML: decoding body: %w", err)
+	}
+	return nil
+}
+
+// xmlFailingElement re-walks body up to offset (the point encoding/xml's
+// Decoder had reached when it failed) to find the innermost element still
+// open at that point, since encoding/xml itself doesn't report this.
+func xmlFailingElement(body []byte, offset int64) string {
+	dec := xml.NewDecoder(bytes.NewReader(body))
+	dec.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+	var lastElem string
+	for dec.InputOffset() < offset {
+		tok, err := dec.Token()
+		if err != nil {
+			break
+		}
+		if se, ok := tok.(xml.StartElement); ok {
+			lastElem = se.Name.Local
+		}
+	}
+	return lastElem
+}
+
+// YAML decodes the response body as YAML into v. A body holding more
+// than one "---"-separated document decodes only the first, same as
+// yaml.Unmarshal.
+func (r *Response) YAML(v interface{}) error {
+	if r.streamBody != nil {
+		return ErrBodyNotBuffered
+	}
+	return yaml.Unmarshal(r.body, v)
+}
+
+// JSON decodes the response body as JSON into v. If decoding fails, the
+// returned error includes the status code and a truncated snippet of the
+// body, since a JSON decode failure is almost always the server having
+// returned an HTML or plain-text error page instead of JSON.
+func (r *Response) JSON(v interface{}) error {
+	return r.decodeJSON(v, false)
+}
+
+// JSONStrict is like JSON but rejects any field present in the body that
+// doesn't match a field in v, via json.Decoder.DisallowUnknownFields.
+func (r *Response) JSONStrict(v interface{}) error {
+	return r.decodeJSON(v, true)
+}
+
+func (r *Response) decodeJSON(v interface{}, strict bool) error {
+	if r.streamBody != nil {
+		return ErrBodyNotBuffered
+	}
+	dec := json.NewDecoder(bytes.NewReader(r.body))
+	if strict {
+		dec.DisallowUnknownFields()
+	}
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("requests: JSON: decoding status %d body failed: %w (body: %s)",
+			r.StatusCode(), err, jsonBodySnippet(r.body))
+	}
+	return nil
+}
+
+// jsonBodySnippet returns up to 200 bytes of body for a JSON decode error
+// message, long enough to recognize an HTML error page or truncated JSON
+// without flooding logs with a huge body.
+func jsonBodySnippet(body []byte) string {
+	const limit = 200
+	if len(body) <= limit {
+		return string(body)
+	}
+	return string(body[:limit]) + "...(truncated)"
+}
+
+// Result returns the pointer passed to Request.SetResult, once Do has
+// decoded a 2xx body into it, or nil if SetResult wasn't used or the
+// response status wasn't 2xx.
+func (r *Response) Result() interface{} {
+	return r.result
+}
+
+// Error returns the pointer passed to Request.SetError, once Do has
+// decoded a 4xx/5xx body into it, or nil if SetError wasn't used or the
+// response status was 2xx.
+func (r *Response) Error() interface{} {
+	return r.errorResult
+}
+
+// decodeResultOrError implements Request.SetResult/SetError: on a 2xx
+// response it decodes the body into result (if non-nil), otherwise into
+// errResult (if non-nil), both via Decode's content-type dispatch. A
+// decode failure is wrapped in *ErrDecodeResult so the caller can still
+// read StatusCode() off the response rather than losing it behind an
+// opaque decode error.
+func (r *Response) decodeResultOrError(result, errResult interface{}) error {
+	ok := r.StatusCode() >= 200 && r.StatusCode() < 300
+	target := errResult
+	if ok {
+		target = result
+	}
+	if target == nil {
+		return nil
+	}
+	if err := r.Decode(target); err != nil {
+		return &ErrDecodeResult{Response: r, Err: err}
+	}
+	if ok {
+		r.result = target
+	} else {
+		r.errorResult = target
+	}
+	return nil
+}
+
+// ETag returns the response's ETag header, quotes included, or "" if absent.
+func (r *Response) ETag() string {
+	return r.rawResponse.Header.Get(HeaderETag)
+}
+
+// LastModified parses the response's Last-Modified header, returning
+// ok=false if it's absent or doesn't parse.
+func (r *Response) LastModified() (t time.Time, ok bool) {
+	v := r.rawResponse.Header.Get(HeaderLastModified)
+	if v == "" {
+		return time.Time{}, false
+	}
+	t, err := http.ParseTime(v)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// IsSuccess reports whether the status code is 2xx.
+func (r *Response) IsSuccess() bool {
+	code := r.StatusCode()
+	return code >= 200 && code < 300
+}
+
+// IsRedirect reports whether the status code is 3xx.
+func (r *Response) IsRedirect() bool {
+	code := r.StatusCode()
+	return code >= 300 && code < 400
+}
+
+// IsClientError reports whether the status code is 4xx.
+func (r *Response) IsClientError() bool {
+	code := r.StatusCode()
+	return code >= 400 && code < 500
+}
+
+// IsServerError reports whether the status code is 5xx.
+func (r *Response) IsServerError() bool {
+	code := r.StatusCode()
+	return code >= 500 && code < 600
+}
+
+// ExpectStatus returns an *ErrUnexpectedStatus, naming codes and a body
+// snippet, unless the response's status code is one of codes.
+func (r *Response) ExpectStatus(codes ...int) error {
+	for _, code := range codes {
+		if r.StatusCode() == code {
+			return nil
+		}
+	}
+	return &ErrUnexpectedStatus{Response: r, Allowed: codes}
+}
+
+// IsNotModified reports whether a Request.SetIfNoneMatch /
+// SetIfModifiedSince revalidation came back 304 Not Modified.
+func (r *Response) IsNotModified() bool {
+	return r.StatusCode() == http.StatusNotModified
+}
+
+// IsPartialContent reports whether the server answered a Request.SetRange
+// / SetRanges request with 206 Partial Content instead of serving the
+// whole resource.
+func (r *Response) IsPartialContent() bool {
+	return r.StatusCode() == http.StatusPartialContent
+}
+
+// ContentRange parses the response's Content-Range header ("bytes
+// start-end/total"), as a server sends back for a Request.SetRange /
+// SetRanges request. ok is false if the header is missing or doesn't
+// parse. total is -1 if the server sent "*" for an unknown resource size.
+func (r *Response) ContentRange() (start, end, total int64, ok bool) {
+	v := r.rawResponse.Header.Get(HeaderContentRange)
+	rest := strings.TrimPrefix(v, "bytes ")
+	if rest == v {
+		return 0, 0, 0, false
+	}
+	slash := strings.IndexByte(rest, '/')
+	if slash < 0 {
+		return 0, 0, 0, false
+	}
+	rangePart, totalPart := rest[:slash], rest[slash+1:]
+
+	if totalPart == "*" {
+		total = -1
+	} else {
+		t, err := strconv.ParseInt(totalPart, 10, 64)
+		if err != nil {
+			return 0, 0, 0, false
+		}
+		total = t
+	}
+
+	dash := strings.IndexByte(rangePart, '-')
+	if dash < 0 {
+		return 0, 0, 0, false
+	}
+	start, err := strconv.ParseInt(rangePart[:dash], 10, 64)
+	if err != nil {
+		return 0, 0, 0, false
+	}
+	end, err = strconv.ParseInt(rangePart[dash+1:], 10, 64)
+	if err != nil {
+		return 0, 0, 0, false
+	}
+	return start, end, total, true
+}
+
 // Status returns the HTTP status string for the executed request.
 func (r *Response) Status() string {
 	if r.rawResponse == nil {
@@ -79,6 +427,13 @@ func (r *Response) Header() http.Header {
 	return r.rawResponse.Header
 }
 
+// Trailer returns the HTTP trailers the server sent after the response
+// body, captured once process reads the body to EOF -- the point at
+// which net/http itself fills them in. Nil if the server sent none.
+func (r *Response) Trailer() http.Header {
+	return r.trailer
+}
+
 // Cookies to access all the response cookies
 func (r *Response) Cookies() []*http.Cookie {
 	if r.rawResponse == nil {
@@ -107,11 +462,44 @@ func (r *Response) Size() int {
 	return len(r.body)
 }
 
-// RawBody returns the HTTP raw response body.
+// CompressedSize returns the response body's size as it crossed the
+// wire, before ContentEncoding was undone, vs. Size's decompressed
+// count. It requires Client.SetWireSizeAccounting(true); otherwise it
+// returns 0, same as an unaccounted WireSize.
+func (r *Response) CompressedSize() int64 {
+	return r.wireSize.Received()
+}
+
+// RawBody returns the HTTP raw response body. For a response streamed via
+// Request.SetDoNotParseResponse, this is the live connection (charset-
+// converted, like BodyStream); otherwise it's the already-buffered body.
 func (r *Response) RawBody() io.Reader {
+	if r.streamBody != nil {
+		return r.streamBody
+	}
 	return bytes.NewReader(r.body)
 }
 
+// BodyStream returns the live, unread response body for a request sent
+// with Request.SetDoNotParseResponse(true), already passed through
+// process()'s content-encoding and charset handling. It's nil for an
+// ordinary, fully buffered response. The caller must read it to
+// completion (or close it via Response.Close) to let the connection be
+// reused.
+func (r *Response) BodyStream() io.Reader {
+	return r.streamBody
+}
+
+// Close releases the connection backing a streamed response (see
+// Request.SetDoNotParseResponse). It's a no-op for an ordinary buffered
+// response, whose body process() already drained and closed.
+func (r *Response) Close() error {
+	if r.rawResponse == nil || r.rawResponse.Body == nil {
+		return nil
+	}
+	return r.rawResponse.Body.Close()
+}
+
 // String returns the HTTP response basic information
 func (r *Response) String() string {
 	return fmt.Sprintf("%s %s %d %d %s", r.method, r.location, r.StatusCode(), r.Size(), r.Time())
@@ -128,6 +516,9 @@ func (r *Response) MessageHead() string {
 }
 
 func (r *Response) message(body bool) string {
+	if body && r.streamBody != nil {
+		return ErrBodyNotBuffered.Error()
+	}
 	b, err := httputil.DumpResponse(r.RawResponse(), body)
 	if err != nil {
 		return err.Error()
@@ -144,35 +535,141 @@ func (r *Response) RawResponse() *http.Response {
 	return resp
 }
 
+// isBodilessResponse reports whether a response for the given request
+// method and status code never carries a body, per RFC 7230 section 3.3.3:
+// HEAD responses and 1xx/204/304 statuses. Content-Length (if any) is kept
+// as-is on the header; only body/charset/HTML sniffing is skipped.
+func isBodilessResponse(method string, statusCode int) bool {
+	if method == MethodHead {
+		return true
+	}
+	return statusCode == http.StatusNoContent || statusCode == http.StatusNotModified ||
+		(statusCode >= 100 && statusCode < 200)
+}
+
+// bodilessMethod returns the method of the request that produced this
+// response, preferring the one net/http attached to rawResponse (always
+// correct for a live fetch) and falling back to r.method, which is set
+// later by init() for live fetches or restored by UnarshalText for cached
+// ones.
+func (r *Response) bodilessMethod() string {
+	if r.rawResponse != nil && r.rawResponse.Request != nil && r.rawResponse.Request.Method != "" {
+		return r.rawResponse.Request.Method
+	}
+	return r.method
+}
+
 func (r *Response) process() (err error) {
 	resp := r.rawResponse
 	if u, err := resp.Location(); err == nil {
 		r.location = u
 	}
-	defer resp.Body.Close()
-	body, mediatype, err := TryCharset(resp.Body, r.ContentType())
+	rawBody := resp.Body
+	closeBody := true
+	defer func() {
+		if closeBody {
+			rawBody.Close()
+		}
+	}()
+
+	body := io.Reader(resp.Body)
+	if r.downloadProgress != nil {
+		body = &progressReader{r: body, total: resp.ContentLength, onProgress: r.downloadProgress}
+	}
+
+	if isBodilessResponse(r.bodilessMethod(), resp.StatusCode) {
+		r.body, err = ioutil.ReadAll(body)
+		if err != nil {
+			return err
+		}
+		r.trailer = resp.Trailer
+		resp.Body = nil
+		return nil
+	}
+
+	encoding := resp.Header.Get(HeaderContentEncoding)
+	decoded, warnMsg, err := decodeContentEncoding(body, encoding)
+	if err != nil {
+		return err
+	}
+	if warnMsg != "" && r.client != nil {
+		r.client.printErrorLevel(errors.New(warnMsg), LogError)
+	}
+	if warnMsg == "" && encoding != "" && encoding != "identity" {
+		// Body/Size now reflect decompressed bytes, so a Content-Encoding
+		// header naming the original, no-longer-true encoding would mislead
+		// a caller reading it back off Header() or Message(). The original
+		// is kept on r.contentEncoding, via ContentEncoding().
+		r.contentEncoding = encoding
+		resp.Header.Del(HeaderContentEncoding)
+	}
+
+	if r.client != nil && r.client.keepUndecodedBody {
+		raw, err := ioutil.ReadAll(decoded)
+		if err != nil {
+			return wrapContentEncodingErr(err, r.contentEncoding)
+		}
+		r.rawUndecodedBody = raw
+		decoded = bytes.NewReader(raw)
+	}
+
+	if _, params, err := mime.ParseMediaType(r.ContentType()); err == nil {
+		r.charset = params["charset"]
+	}
+
+	body, mediatype, err := TryCharset(decoded, r.ContentType())
 	if err != nil {
 		return err
 	}
 	r.rawResponse.Header.Set(HeaderContentType, mediatype)
+
+	if r.maxResponseSize > 0 {
+		body = &maxSizeReader{r: body, limit: r.maxResponseSize, contentLength: resp.ContentLength}
+	}
+
+	if r.streamed {
+		r.streamBody = body
+		closeBody = false
+		return nil
+	}
+
 	r.body, err = ioutil.ReadAll(body)
+	if tooLarge, ok := err.(*ErrResponseTooLarge); ok {
+		if int64(len(r.body)) > tooLarge.Limit {
+			r.body = r.body[:tooLarge.Limit]
+		}
+		return tooLarge
+	}
 	if err != nil {
-		return err
+		return wrapContentEncodingErr(err, r.contentEncoding)
 	}
+	// resp.Trailer is only populated once the body has been read to EOF,
+	// which ioutil.ReadAll above just did.
+	r.trailer = resp.Trailer
 
 	resp.Body = nil
 	return nil
 }
 
 func (r *Response) MarshalText() ([]byte, error) {
-	return MarshalResponse(r.RawResponse())
+	data, err := MarshalResponse(r.RawResponse())
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(r.method+"\n"), data...), nil
 }
 
 func (r *Response) UnarshalText(data []byte) error {
-	resp, err := UnmarshalResponse(data)
+	i := bytes.IndexByte(data, '\n')
+	if i < 0 {
+		return fmt.Errorf("requests: invalid cached response")
+	}
+	method := string(data[:i])
+	resp, err := UnmarshalResponse(data[i+1:], method)
 	if err != nil {
 		return err
 	}
+	r.method = method
 	r.rawResponse = resp
 	return r.process()
 }
@@ -0,0 +1,245 @@
+package requests
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryDefaultConditionRetriesOn5xx(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	var calls int32
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	resp, err := NewRequest().SetRetry(2).Get(mock.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode() != http.StatusOK {
+		t.Errorf("status = %d, want 200", resp.StatusCode())
+	}
+	if atomic.LoadInt32(&calls) != 2 {
+		t.Errorf("calls = %d, want 2", calls)
+	}
+	if resp.Attempts() != 2 {
+		t.Errorf("Attempts() = %d, want 2", resp.Attempts())
+	}
+}
+
+func TestRetryDefaultConditionRetriesOn429(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	var calls int32
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	resp, err := NewRequest().SetRetry(2).SetRetryBackoff(time.Millisecond, 10*time.Millisecond).Get(mock.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode() != http.StatusOK {
+		t.Errorf("status = %d, want 200", resp.StatusCode())
+	}
+	if resp.Attempts() != 2 {
+		t.Errorf("Attempts() = %d, want 2", resp.Attempts())
+	}
+}
+
+func TestRetryDefaultConditionRetriesOnNetworkError(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	var calls int32
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			hj, ok := w.(http.Hijacker)
+			if !ok {
+				t.Fatal("ResponseWriter does not support hijacking")
+			}
+			conn, _, err := hj.Hijack()
+			if err != nil {
+				t.Fatal(err)
+			}
+			conn.Close()
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	resp, err := NewRequest().SetRetry(2).SetRetryBackoff(time.Millisecond, 10*time.Millisecond).Get(mock.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode() != http.StatusOK {
+		t.Errorf("status = %d, want 200", resp.StatusCode())
+	}
+	if resp.Attempts() != 2 {
+		t.Errorf("Attempts() = %d, want 2", resp.Attempts())
+	}
+}
+
+func TestRetryCustomConditionOverridesDefault(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	var calls int32
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	// The default condition never retries a 404, so without a custom
+	// condition this would return the 404 on the first attempt.
+	resp, err := NewRequest().
+		SetRetry(2).
+		SetRetryBackoff(time.Millisecond, 10*time.Millisecond).
+		SetRetryCondition(func(resp *Response, err error) bool {
+			return err != nil || resp.StatusCode() == http.StatusNotFound
+		}).
+		Get(mock.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode() != http.StatusOK {
+		t.Errorf("status = %d, want 200", resp.StatusCode())
+	}
+	if atomic.LoadInt32(&calls) != 2 {
+		t.Errorf("calls = %d, want 2", calls)
+	}
+}
+
+// onlyReader wraps an io.Reader without exposing its concrete type, so
+// neither http.NewRequest nor rewindableBodyGetter can detect it as
+// seekable.
+type onlyReader struct {
+	r io.Reader
+}
+
+func (o *onlyReader) Read(p []byte) (int, error) {
+	return o.r.Read(p)
+}
+
+func TestRetryOnlyReplaysRewindableBodies(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	var calls int32
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	var logBuf bytes.Buffer
+	client := NewClient().SetLogger(&logBuf)
+	// onlyReader hides its underlying *strings.Reader behind a plain
+	// io.Reader, defeating both RawRequest's own rewindableBodyGetter and
+	// http.NewRequest's built-in detection -- so GetBody is left unset
+	// and a retry can't safely replay it.
+	resp, err := client.NewRequest().SetRetry(2).SetBody(&onlyReader{strings.NewReader("payload")}).Post(mock.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode() != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503 -- a non-rewindable body must not be retried", resp.StatusCode())
+	}
+	if atomic.LoadInt32(&calls) != 1 {
+		t.Errorf("calls = %d, want 1 -- SetRetry should have been ignored", calls)
+	}
+	if !strings.Contains(logBuf.String(), "non-rewindable") {
+		t.Errorf("log output = %q, want a note that SetRetry was ignored", logBuf.String())
+	}
+}
+
+func TestRetryBackoffHonorsContextCancellation(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+
+	start := time.Now()
+	resp, err := NewRequest().
+		SetRetry(5).
+		SetRetryBackoff(time.Minute, time.Minute).
+		GetCtx(ctx, mock.URL())
+	elapsed := time.Since(start)
+
+	if elapsed > time.Second {
+		t.Errorf("elapsed = %s, want the backoff to abort quickly once ctx was cancelled", elapsed)
+	}
+	// Cancelling mid-backoff abandons the retry rather than synthesizing a
+	// new error: the 503 already received is the final answer, same as if
+	// SetRetry had never been called.
+	if err == nil && resp.StatusCode() != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503 if no error was returned", resp.StatusCode())
+	}
+}
+
+func TestRetryLogsEachAttemptAtLogInfo(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	var calls int32
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	var logBuf bytes.Buffer
+	client := NewClient().SetLogger(&logBuf)
+	if _, err := client.NewRequest().
+		SetRetry(2).
+		SetRetryBackoff(time.Millisecond, 10*time.Millisecond).
+		Get(mock.URL()); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(logBuf.String(), "attempt 1") {
+		t.Errorf("log output = %q, want it to mention the failed attempt number", logBuf.String())
+	}
+}
@@ -0,0 +1,125 @@
+package requests
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRewindableBodySurvives307Redirect(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+
+	var (
+		firstHits  int
+		secondBody []byte
+	)
+	mock.HandleFunc("^/first$", func(w http.ResponseWriter, r *http.Request) {
+		firstHits++
+		http.Redirect(w, r, mock.URL()+"/second", http.StatusTemporaryRedirect)
+	})
+	mock.HandleFunc("^/second$", func(w http.ResponseWriter, r *http.Request) {
+		secondBody, _ = ioutil.ReadAll(r.Body)
+	})
+
+	_, err = NewRequest().SetURLByStr(mock.URL()).SetBodyBytes([]byte("payload")).Post("/first")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if firstHits != 1 {
+		t.Errorf("firstHits = %d, want 1", firstHits)
+	}
+	if string(secondBody) != "payload" {
+		t.Errorf("body at second hop = %q, want %q", secondBody, "payload")
+	}
+}
+
+func TestRewindableBodyFromFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "requests-rewindable")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := dir + "/body.txt"
+	if err := ioutil.WriteFile(path, []byte("file payload"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+
+	var secondBody []byte
+	mock.HandleFunc("^/first$", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, mock.URL()+"/second", http.StatusTemporaryRedirect)
+	})
+	mock.HandleFunc("^/second$", func(w http.ResponseWriter, r *http.Request) {
+		secondBody, _ = ioutil.ReadAll(r.Body)
+	})
+
+	_, err = NewRequest().SetURLByStr(mock.URL()).SetBody(f).Post("/first")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(secondBody) != "file payload" {
+		t.Errorf("body at second hop = %q, want %q", secondBody, "file payload")
+	}
+}
+
+func TestSetBodyProviderReplaysOnRedirect(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+
+	var (
+		calls      int
+		secondBody []byte
+	)
+	mock.HandleFunc("^/first$", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, mock.URL()+"/second", http.StatusTemporaryRedirect)
+	})
+	mock.HandleFunc("^/second$", func(w http.ResponseWriter, r *http.Request) {
+		secondBody, _ = ioutil.ReadAll(r.Body)
+	})
+
+	provider := func() (io.ReadCloser, error) {
+		calls++
+		return ioutil.NopCloser(strings.NewReader("provided")), nil
+	}
+
+	_, err = NewRequest().SetURLByStr(mock.URL()).SetBodyProvider(provider).Post("/first")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Errorf("provider calls = %d, want 2 (initial send + replay)", calls)
+	}
+	if string(secondBody) != "provided" {
+		t.Errorf("body at second hop = %q, want %q", secondBody, "provided")
+	}
+}
@@ -0,0 +1,73 @@
+package requests
+
+import (
+	"strconv"
+	"strings"
+)
+
+// HeaderServerTiming is the header carrying per-request performance
+// metrics as described by the Server Timing spec (W3C, formerly RFC 8673).
+const HeaderServerTiming = "Server-Timing"
+
+// ServerTimingMetric is one entry of a Server-Timing header, e.g.
+// `db;dur=12.3;desc="hit"` parses to {Name: "db", Dur: 12.3, Desc: "hit"}.
+type ServerTimingMetric struct {
+	Name string
+	Dur  float64
+	Desc string
+}
+
+// ServerTiming parses every Server-Timing header on the response into a
+// slice of ServerTimingMetric. Multiple headers and multiple comma
+// separated metrics per header are both supported. Entries with a metric
+// name that can't be parsed are skipped; dur/desc are optional and default
+// to their zero values when absent or unparsable.
+func (r *Response) ServerTiming() ([]ServerTimingMetric, error) {
+	var metrics []ServerTimingMetric
+	for _, line := range r.Header()[HeaderServerTiming] {
+		for _, entry := range strings.Split(line, ",") {
+			m, ok := parseServerTimingMetric(entry)
+			if !ok {
+				continue
+			}
+			metrics = append(metrics, m)
+		}
+	}
+	return metrics, nil
+}
+
+func parseServerTimingMetric(entry string) (ServerTimingMetric, bool) {
+	parts := strings.Split(entry, ";")
+	name := strings.TrimSpace(parts[0])
+	if name == "" {
+		return ServerTimingMetric{}, false
+	}
+	m := ServerTimingMetric{Name: name}
+	for _, param := range parts[1:] {
+		param = strings.TrimSpace(param)
+		key, value := param, ""
+		if i := strings.IndexByte(param, '='); i >= 0 {
+			key, value = param[:i], param[i+1:]
+		}
+		key = strings.TrimSpace(strings.ToLower(key))
+		value = strings.TrimSpace(value)
+		if len(value) > 1 && value[0] == '"' && value[len(value)-1] == '"' {
+			unquoted, err := strconv.Unquote(value)
+			if err == nil {
+				value = unquoted
+			} else {
+				value = value[1 : len(value)-1]
+			}
+		}
+		switch key {
+		case "dur":
+			dur, err := strconv.ParseFloat(value, 64)
+			if err == nil {
+				m.Dur = dur
+			}
+		case "desc":
+			m.Desc = value
+		}
+	}
+	return m, true
+}
@@ -0,0 +1,31 @@
+package requests
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestServerTiming(t *testing.T) {
+	resp := NewStubResponse(200, nil, http.Header{
+		HeaderServerTiming: []string{
+			`db;dur=12.3;desc="db query"`,
+			`cache;dur=0.5, , badentry;dur=notanumber, fetch`,
+		},
+	})
+
+	metrics, err := resp.ServerTiming()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []ServerTimingMetric{
+		{Name: "db", Dur: 12.3, Desc: "db query"},
+		{Name: "cache", Dur: 0.5},
+		{Name: "badentry"},
+		{Name: "fetch"},
+	}
+	if !reflect.DeepEqual(metrics, want) {
+		t.Errorf("got %+v, want %+v", metrics, want)
+	}
+}
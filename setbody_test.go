@@ -0,0 +1,109 @@
+package requests
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+func TestSetBodyString(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+
+	var (
+		gotBody          []byte
+		gotContentType   string
+		gotContentLength int64
+	)
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		gotBody, _ = ioutil.ReadAll(r.Body)
+		gotContentType = r.Header.Get(HeaderContentType)
+		gotContentLength = r.ContentLength
+	})
+
+	_, err = NewRequest().SetURLByStr(mock.URL()).SetBodyString("hello world").Post("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(gotBody) != "hello world" {
+		t.Errorf("body = %q, want %q", gotBody, "hello world")
+	}
+	if gotContentType != MimeTextPlain {
+		t.Errorf("Content-Type = %q, want %q", gotContentType, MimeTextPlain)
+	}
+	if gotContentLength != int64(len("hello world")) {
+		t.Errorf("Content-Length = %d, want %d", gotContentLength, len("hello world"))
+	}
+}
+
+func TestSetBodyBytes(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+
+	data := []byte{0x00, 0x01, 0x02, 0xff}
+
+	var (
+		gotBody          []byte
+		gotContentType   string
+		gotContentLength int64
+	)
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		gotBody, _ = ioutil.ReadAll(r.Body)
+		gotContentType = r.Header.Get(HeaderContentType)
+		gotContentLength = r.ContentLength
+	})
+
+	_, err = NewRequest().SetURLByStr(mock.URL()).SetBodyBytes(data).Post("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(gotBody) != string(data) {
+		t.Errorf("body = %v, want %v", gotBody, data)
+	}
+	if gotContentType != MimeOctetStream {
+		t.Errorf("Content-Type = %q, want %q", gotContentType, MimeOctetStream)
+	}
+	if gotContentLength != int64(len(data)) {
+		t.Errorf("Content-Length = %d, want %d", gotContentLength, len(data))
+	}
+}
+
+func TestSetBodyStringEmptyDoesNotFormEncode(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+
+	var gotContentLength string
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		gotContentLength = strconv.FormatInt(r.ContentLength, 10)
+	})
+
+	_, err = NewRequest().SetURLByStr(mock.URL()).SetBodyString("").Post("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if gotContentLength != "0" {
+		t.Errorf("Content-Length = %s, want %s", gotContentLength, "0")
+	}
+}
@@ -0,0 +1,103 @@
+package requests
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestSetResultDecodesJSONSuccess(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderContentType, MimeJSON)
+		w.Write([]byte(`{"name":"gopher"}`))
+	})
+
+	var out struct {
+		Name string `json:"name"`
+	}
+	resp, err := NewRequest().SetURLByStr(mock.URL()).SetResult(&out).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Name != "gopher" {
+		t.Errorf("Name = %q, want gopher", out.Name)
+	}
+	if resp.Result() != &out {
+		t.Error("Result() should return the pointer passed to SetResult")
+	}
+	if resp.Error() != nil {
+		t.Error("Error() should be nil on a successful response")
+	}
+}
+
+func TestSetErrorDecodesJSONErrorBody(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderContentType, MimeJSON)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"message":"bad input"}`))
+	})
+
+	var okOut struct{}
+	var errOut struct {
+		Message string `json:"message"`
+	}
+	resp, err := NewRequest().SetURLByStr(mock.URL()).SetResult(&okOut).SetError(&errOut).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode() != http.StatusBadRequest {
+		t.Errorf("StatusCode() = %d, want 400", resp.StatusCode())
+	}
+	if errOut.Message != "bad input" {
+		t.Errorf("Message = %q, want bad input", errOut.Message)
+	}
+	if resp.Error() != &errOut {
+		t.Error("Error() should return the pointer passed to SetError")
+	}
+	if resp.Result() != nil {
+		t.Error("Result() should be nil when the response wasn't 2xx")
+	}
+}
+
+func TestSetErrorDecodeFailurePreservesStatusCode(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderContentType, MimeTextPlain)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("internal error, not JSON"))
+	})
+
+	var errOut struct {
+		Message string `json:"message"`
+	}
+	resp, err := NewRequest().SetURLByStr(mock.URL()).SetError(&errOut).Get("/")
+	if err == nil {
+		t.Fatal("want an error decoding a non-JSON 500 body as JSON")
+	}
+	decodeErr, ok := err.(*ErrDecodeResult)
+	if !ok {
+		t.Fatalf("err = %T, want *ErrDecodeResult", err)
+	}
+	if decodeErr.Response.StatusCode() != http.StatusInternalServerError {
+		t.Errorf("StatusCode() = %d, want 500", decodeErr.Response.StatusCode())
+	}
+	if resp.StatusCode() != http.StatusInternalServerError {
+		t.Errorf("resp.StatusCode() = %d, want 500", resp.StatusCode())
+	}
+}
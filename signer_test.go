@@ -0,0 +1,117 @@
+package requests
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func hmacSigner(secret string) func(*http.Request, []byte) error {
+	return func(req *http.Request, body []byte) error {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(req.Method))
+		mac.Write([]byte(req.URL.Path))
+		mac.Write(body)
+		req.Header.Set("X-Signature", hex.EncodeToString(mac.Sum(nil)))
+		return nil
+	}
+}
+
+func TestSetSignerAddsHeaderFromMethodPathAndBody(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	var captured *http.Request
+	var receivedBody []byte
+	mock.HandleFunc("/orders", func(w http.ResponseWriter, r *http.Request) {
+		captured = r
+		receivedBody = make([]byte, r.ContentLength)
+		r.Body.Read(receivedBody)
+	})
+
+	_, err = NewRequest().SetURLByStr(mock.URL()).
+		SetSigner(hmacSigner("s3cr3t")).
+		SetBodyBytes([]byte(`{"id":1}`)).Post("/orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mac := hmac.New(sha256.New, []byte("s3cr3t"))
+	mac.Write([]byte("POST"))
+	mac.Write([]byte("/orders"))
+	mac.Write([]byte(`{"id":1}`))
+	want := hex.EncodeToString(mac.Sum(nil))
+
+	if got := captured.Header.Get("X-Signature"); got != want {
+		t.Errorf("X-Signature = %q, want %q", got, want)
+	}
+	if string(receivedBody) != `{"id":1}` {
+		t.Errorf("server received %q, want the original body intact", receivedBody)
+	}
+}
+
+func TestSetSignerComposesInRegistrationOrder(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	var captured *http.Request
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		captured = r
+	})
+
+	var order []string
+	_, err = NewRequest().SetURLByStr(mock.URL()).
+		SetSigner(func(req *http.Request, body []byte) error {
+			order = append(order, "first")
+			req.Header.Set("X-First", "1")
+			return nil
+		}).
+		SetSigner(func(req *http.Request, body []byte) error {
+			order = append(order, "second")
+			req.Header.Set("X-Second", "2")
+			return nil
+		}).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(order) != 2 || order[0] != "first" || order[1] != "second" {
+		t.Errorf("order = %v, want [first second]", order)
+	}
+	if captured.Header.Get("X-First") != "1" || captured.Header.Get("X-Second") != "2" {
+		t.Errorf("headers = %+v, want both signers' headers present", captured.Header)
+	}
+}
+
+func TestSetSignerErrorAbortsDo(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("handler should not be reached when a signer fails")
+	})
+
+	wantErr := errors.New("no key configured")
+	_, err = NewRequest().SetURLByStr(mock.URL()).
+		SetSigner(func(req *http.Request, body []byte) error { return wantErr }).Get("/")
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("err = %v, want one wrapping %v", err, wantErr)
+	}
+}
@@ -0,0 +1,25 @@
+package requests
+
+// SetErrorOnStatus makes Do() return a non-nil *StatusError for any 4xx
+// or 5xx response instead of a nil error, for any request that doesn't
+// override it with Request.SetErrorOnStatus. The *Response itself is
+// still returned alongside the error either way, so headers and the
+// buffered body remain inspectable.
+func (c *Client) SetErrorOnStatus(enabled bool) *Client {
+	c.errorOnStatus = enabled
+	return c
+}
+
+// SetErrorOnStatus overrides the client's Client.SetErrorOnStatus setting
+// for this request.
+func (r *Request) SetErrorOnStatus(enabled bool) *Request {
+	r.errorOnStatus = &enabled
+	return r
+}
+
+func (r *Request) effectiveErrorOnStatus() bool {
+	if r.errorOnStatus != nil {
+		return *r.errorOnStatus
+	}
+	return r.client.errorOnStatus
+}
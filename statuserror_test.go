@@ -0,0 +1,81 @@
+package requests
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestSetErrorOnStatusReturnsStatusError(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("not found"))
+	})
+
+	client := NewClient().SetErrorOnStatus(true)
+	resp, err := client.NewRequest().SetURLByStr(mock.URL()).Get("/")
+	if err == nil {
+		t.Fatal("want a *StatusError for a 404 response")
+	}
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("errors.As failed to extract *StatusError from %v (%T)", err, err)
+	}
+	if statusErr.StatusCode != http.StatusNotFound {
+		t.Errorf("StatusCode = %d, want 404", statusErr.StatusCode)
+	}
+	if resp == nil || resp.StatusCode() != http.StatusNotFound {
+		t.Error("want Do to still return the *Response alongside the error")
+	}
+	if string(resp.Body()) != "not found" {
+		t.Errorf("resp.Body() = %q, want not found", resp.Body())
+	}
+}
+
+func TestSetErrorOnStatusPerRequestOverride(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	client := NewClient().SetErrorOnStatus(true)
+
+	// Per-request override back to the old behavior.
+	_, err = client.NewRequest().SetURLByStr(mock.URL()).SetErrorOnStatus(false).Get("/")
+	if err != nil {
+		t.Errorf("want no error with the per-request override, got %v", err)
+	}
+
+	// Enabling it per-request on a client that defaults to off.
+	plainClient := NewClient()
+	_, err = plainClient.NewRequest().SetURLByStr(mock.URL()).SetErrorOnStatus(true).Get("/")
+	if err == nil {
+		t.Error("want a *StatusError with the per-request override enabled")
+	}
+}
+
+func TestSetErrorOnStatusDoesNotTriggerOnSuccess(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {})
+
+	client := NewClient().SetErrorOnStatus(true)
+	_, err = client.NewRequest().SetURLByStr(mock.URL()).Get("/")
+	if err != nil {
+		t.Errorf("want no error for a 200 response, got %v", err)
+	}
+}
@@ -0,0 +1,147 @@
+package requests
+
+import (
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// StreamResponse is like Response, but leaves the body unread for the
+// caller to consume directly off the connection instead of buffering the
+// whole thing into memory -- returned by Request.Stream for
+// multi-gigabyte downloads or long-lived feeds where Response's
+// full-buffering would be wasteful or impossible.
+type StreamResponse struct {
+	rawResponse *http.Response
+	location    *url.URL
+	method      string
+
+	bodyOnce sync.Once
+	body     io.ReadCloser
+}
+
+// StatusCode returns the HTTP status code for the executed request.
+func (r *StreamResponse) StatusCode() int {
+	return r.rawResponse.StatusCode
+}
+
+// Header returns the response headers.
+func (r *StreamResponse) Header() http.Header {
+	return r.rawResponse.Header
+}
+
+// Location returns the request url.
+func (r *StreamResponse) Location() *url.URL {
+	return r.location
+}
+
+// Body returns the response body for the caller to read, with charset
+// transcoding applied lazily via TryCharset the first time Body is
+// called. Unlike Response, nothing is buffered: reading it drives the
+// underlying connection directly, and it must be closed (directly, or
+// via StreamResponse.Close) to release that connection back to the pool.
+func (r *StreamResponse) Body() io.ReadCloser {
+	r.bodyOnce.Do(func() {
+		decoded, mediatype, err := TryCharset(r.rawResponse.Body, r.Header().Get(HeaderContentType))
+		if err != nil {
+			r.body = r.rawResponse.Body
+			return
+		}
+		r.rawResponse.Header.Set(HeaderContentType, mediatype)
+		if rc, ok := decoded.(io.ReadCloser); ok {
+			r.body = rc
+		} else {
+			r.body = struct {
+				io.Reader
+				io.Closer
+			}{decoded, r.rawResponse.Body}
+		}
+	})
+	return r.body
+}
+
+// Close releases the connection Body would read from back to the pool,
+// discarding anything left unread. It's safe to call without ever
+// calling Body.
+func (r *StreamResponse) Close() error {
+	return r.rawResponse.Body.Close()
+}
+
+// String returns the HTTP response basic information, without touching
+// the body.
+func (r *StreamResponse) String() string {
+	return r.method + " " + r.location.String() + " " + http.StatusText(r.StatusCode())
+}
+
+// MessageHead returns the HTTP response header information, without
+// reading (or buffering) the body.
+func (r *StreamResponse) MessageHead() string {
+	b, err := httputil.DumpResponse(r.rawResponse, false)
+	if err != nil {
+		return err.Error()
+	}
+	return string(b)
+}
+
+// Stream performs the HTTP request like Do, but returns a *StreamResponse
+// whose body is left unread for the caller to consume directly off the
+// connection, instead of Response's full in-memory buffering. Use it for
+// multi-gigabyte downloads or long-lived feeds that Do can't handle.
+// SetRetry, Client.SetCache and Client.StubResponse are all skipped,
+// since each needs the whole body in memory to rewind, save or replay
+// it; redirects, proxy overrides and logging behave the same as Do.
+//
+// The request's method and URL come from the builder, e.g.
+// SetMethod/SetURLByStr, same as Do.
+func (r *Request) Stream() (*StreamResponse, error) {
+	return r.client.doStream(r.Clone())
+}
+
+// doStream is Stream's counterpart to Client.do: a single, unbuffered
+// send that leaves the response body for the caller to read.
+func (c *Client) doStream(req *Request) (*StreamResponse, error) {
+	_, err := req.RawRequest()
+	if err != nil {
+		return nil, err
+	}
+	defer req.cancelContext()
+
+	c.setDeadlineHeader(req.rawRequest)
+	c.printRequest(req)
+	req.sendAt = time.Now()
+	resp, err := c.processRequest(req, req.rawRequest)
+	if err != nil {
+		c.printErrorLevel(err, req.effectiveLogLevel())
+		return nil, err
+	}
+
+	response := &StreamResponse{
+		rawResponse: resp,
+		method:      req.method,
+		location:    req.baseURL,
+	}
+	if u, err := resp.Location(); err == nil {
+		response.location = u
+	}
+	c.printStreamResponse(req, response)
+	return response, nil
+}
+
+// printStreamResponse logs resp for a streamed request. Its body is left
+// for the caller to read, so LogMessageAll dumps headers only, like
+// LogMessageHead, instead of also dumping -- and thereby consuming -- the
+// body.
+func (c *Client) printStreamResponse(req *Request, resp *StreamResponse) {
+	level := req.effectiveLogLevel()
+	if c.log != nil && level > LogError && c.shouldLog() {
+		switch level {
+		case LogInfo:
+			c.log.Printf("Response: %s", resp.String())
+		case LogMessageHead, LogMessageAll:
+			c.log.Printf("Response: %s", resp.MessageHead())
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package requests
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestStreamReturnsUnbufferedBody(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderContentType, "text/plain")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("streamed payload"))
+	})
+
+	resp, err := NewRequest().SetMethod(MethodGet).SetURLByStr(mock.URL()).Stream()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Close()
+	if resp.StatusCode() != http.StatusOK {
+		t.Errorf("StatusCode() = %d, want 200", resp.StatusCode())
+	}
+	body, err := ioutil.ReadAll(resp.Body())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != "streamed payload" {
+		t.Errorf("body = %q, want %q", body, "streamed payload")
+	}
+}
+
+func TestStreamAppliesCharsetLazily(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderContentType, "text/plain; charset=gbk")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte{0xc4, 0xe3, 0xba, 0xc3}) // "你好" in GBK
+	})
+
+	resp, err := NewRequest().SetMethod(MethodGet).SetURLByStr(mock.URL()).Stream()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Close()
+	// Before Body is called, the header still advertises the original
+	// charset: the transcode hasn't happened yet.
+	if got := resp.Header().Get(HeaderContentType); got != "text/plain; charset=gbk" {
+		t.Errorf("Header before Body() = %q, want the untouched charset", got)
+	}
+	body, err := ioutil.ReadAll(resp.Body())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != "你好" {
+		t.Errorf("body = %q, want 你好 decoded from GBK", body)
+	}
+}
+
+func TestStreamCloseReleasesConnectionWithoutReadingBody(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("never read"))
+	})
+
+	resp, err := NewRequest().SetMethod(MethodGet).SetURLByStr(mock.URL()).Stream()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := resp.Close(); err != nil {
+		t.Errorf("Close() = %v, want nil", err)
+	}
+}
+
+func TestStreamMessageHeadDoesNotDumpBody(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Extra", "yes")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("should not appear in MessageHead"))
+	})
+
+	resp, err := NewRequest().SetMethod(MethodGet).SetURLByStr(mock.URL()).Stream()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Close()
+
+	msg := resp.MessageHead()
+	if !strings.Contains(msg, "X-Extra: yes") {
+		t.Errorf("MessageHead() = %q, want it to contain the X-Extra header", msg)
+	}
+	if strings.Contains(msg, "should not appear in MessageHead") {
+		t.Errorf("MessageHead() = %q, want it to not include the body", msg)
+	}
+	body, err := ioutil.ReadAll(resp.Body())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != "should not appear in MessageHead" {
+		t.Errorf("body = %q, unexpected", body)
+	}
+}
@@ -0,0 +1,117 @@
+package requests
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http/httptrace"
+	"time"
+)
+
+type traceContextKeyType struct{}
+
+var traceContextKey = traceContextKeyType{}
+
+// TraceInfo is a per-request timing breakdown captured via Request.EnableTrace.
+// Every field is zero for a response whose request never called EnableTrace.
+// Durations are computed from time.Now, which is backed by the monotonic
+// clock reading Go attaches to every time.Time, so they're unaffected by
+// wall-clock adjustments.
+type TraceInfo struct {
+	DNSLookup    time.Duration
+	ConnTime     time.Duration
+	TLSHandshake time.Duration
+	ServerTime   time.Duration
+	TotalTime    time.Duration
+	ConnReused   bool
+	RemoteAddr   net.Addr
+}
+
+// traceState accumulates the timestamps an httptrace.ClientTrace reports
+// for a single request attempt, later reduced into a TraceInfo. Its
+// callbacks all fire sequentially on the goroutine driving the round
+// trip, so it needs no locking. TotalTime isn't filled in here -- a
+// trace has no hook for "response body fully read" -- Client.do sets it
+// from the same sendAt/recvAt pair Response.Time() uses.
+type traceState struct {
+	dnsStart          time.Time
+	connectStart      time.Time
+	tlsHandshakeStart time.Time
+	wroteRequestAt    time.Time
+	info              TraceInfo
+}
+
+// EnableTrace installs an httptrace.ClientTrace on the request, so the
+// response it produces has a populated Response.TraceInfo(): DNS lookup,
+// connect and TLS handshake time, time to first response byte, total
+// time, and whether the connection was reused.
+func (r *Request) EnableTrace() *Request {
+	r.traceEnabled = true
+	return r
+}
+
+func withClientTrace(ctx context.Context) (context.Context, *traceState) {
+	ts := &traceState{}
+	trace := &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			ts.dnsStart = time.Now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			if !ts.dnsStart.IsZero() {
+				ts.info.DNSLookup = time.Since(ts.dnsStart)
+			}
+		},
+		ConnectStart: func(network, addr string) {
+			ts.connectStart = time.Now()
+		},
+		ConnectDone: func(network, addr string, err error) {
+			if err == nil && !ts.connectStart.IsZero() {
+				ts.info.ConnTime = time.Since(ts.connectStart)
+			}
+		},
+		TLSHandshakeStart: func() {
+			ts.tlsHandshakeStart = time.Now()
+		},
+		TLSHandshakeDone: func(_ tls.ConnectionState, err error) {
+			if err == nil && !ts.tlsHandshakeStart.IsZero() {
+				ts.info.TLSHandshake = time.Since(ts.tlsHandshakeStart)
+			}
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			ts.info.ConnReused = info.Reused
+			if info.Conn != nil {
+				ts.info.RemoteAddr = info.Conn.RemoteAddr()
+			}
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			ts.wroteRequestAt = time.Now()
+		},
+		GotFirstResponseByte: func() {
+			if !ts.wroteRequestAt.IsZero() {
+				ts.info.ServerTime = time.Since(ts.wroteRequestAt)
+			}
+		},
+	}
+	ctx = context.WithValue(ctx, traceContextKey, ts)
+	return httptrace.WithClientTrace(ctx, trace), ts
+}
+
+// TraceInfo returns the timing breakdown captured by Request.EnableTrace,
+// or a zero TraceInfo if tracing wasn't enabled.
+func (r *Response) TraceInfo() TraceInfo {
+	return r.traceInfo
+}
+
+// setTraceFields populates a Response's TraceInfo from the traceState its
+// request accumulated, if tracing was enabled. TotalTime isn't tracked by
+// traceState itself -- there's no trace hook for "response body fully
+// read" -- so it's computed here from the same sendAt/recvAt pair
+// Response.Time() uses, after response.init has set them.
+func setTraceFields(response *Response, req *Request) {
+	if req.traceState == nil {
+		return
+	}
+	info := req.traceState.info
+	info.TotalTime = response.recvAt.Sub(response.sendAt)
+	response.traceInfo = info
+}
@@ -0,0 +1,90 @@
+package requests
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestTraceInfoIsZeroWithoutEnableTrace(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+
+	resp, err := NewClient().NewRequest().SetURLByStr(mock.URL()).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := resp.TraceInfo()
+	if info != (TraceInfo{}) {
+		t.Errorf("TraceInfo() = %+v, want zero value", info)
+	}
+}
+
+func TestEnableTracePopulatesTimingsForPlainHTTP(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+
+	resp, err := NewClient().NewRequest().SetURLByStr(mock.URL()).EnableTrace().Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := resp.TraceInfo()
+	if info.ConnTime <= 0 {
+		t.Errorf("ConnTime = %v, want > 0", info.ConnTime)
+	}
+	if info.ServerTime <= 0 {
+		t.Errorf("ServerTime = %v, want > 0", info.ServerTime)
+	}
+	if info.TotalTime <= 0 {
+		t.Errorf("TotalTime = %v, want > 0", info.TotalTime)
+	}
+	if info.TLSHandshake != 0 {
+		t.Errorf("TLSHandshake = %v, want 0 for a plain HTTP request", info.TLSHandshake)
+	}
+	if info.ConnReused {
+		t.Errorf("ConnReused = true on a fresh connection, want false")
+	}
+	if info.RemoteAddr == nil {
+		t.Errorf("RemoteAddr is nil, want the mock server's address")
+	}
+}
+
+func TestEnableTraceReportsConnReusedOnSecondRequest(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+
+	client := NewClient()
+	if _, err := client.NewRequest().SetURLByStr(mock.URL()).EnableTrace().Get("/"); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.NewRequest().SetURLByStr(mock.URL()).EnableTrace().Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.TraceInfo().ConnReused {
+		t.Errorf("ConnReused = false on a second request to the same server, want true")
+	}
+}
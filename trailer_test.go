@@ -0,0 +1,103 @@
+package requests
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestSetTrailerSendsRequestTrailer(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(body) != "payload" {
+			t.Errorf("body = %q, want %q", body, "payload")
+		}
+		// r.Trailer is only populated once the body has been fully read.
+		if got := r.Trailer.Get("X-Checksum"); got != "deadbeef" {
+			t.Errorf("request trailer X-Checksum = %q, want deadbeef", got)
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	_, err = NewRequest().
+		SetBodyString("payload").
+		SetTrailer("X-Checksum", "deadbeef").
+		Post(mock.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDeclareTrailerAnnouncesEmptyTrailerField(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		if _, ok := r.Trailer["X-Optional"]; !ok {
+			t.Errorf("r.Trailer = %v, want it to contain the declared X-Optional field", r.Trailer)
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	_, err = NewRequest().
+		SetBodyString("payload").
+		DeclareTrailer("X-Optional").
+		Post(mock.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestResponseTrailerCapturedAfterBodyRead(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Trailer", "X-Response-Status")
+		w.Write([]byte("ok"))
+		w.Header().Set("X-Response-Status", "complete")
+	})
+
+	resp, err := NewRequest().Get(mock.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := resp.Trailer().Get("X-Response-Status"); got != "complete" {
+		t.Errorf("Trailer().Get(X-Response-Status) = %q, want complete", got)
+	}
+}
+
+func TestMessageIncludesResponseTrailer(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Trailer", "X-Response-Status")
+		w.Write([]byte("ok"))
+		w.Header().Set("X-Response-Status", "complete")
+	})
+
+	resp, err := NewRequest().Get(mock.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg := resp.Message(); !strings.Contains(msg, "X-Response-Status: complete") {
+		t.Errorf("Message() = %q, want it to include the response trailer", msg)
+	}
+}
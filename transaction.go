@@ -0,0 +1,108 @@
+package requests
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// MultiError collects multiple errors produced while running a
+// Transaction: the error that aborted it, followed by any errors the
+// rollbacks of already-completed steps returned while compensating.
+type MultiError struct {
+	Errors []error
+}
+
+func (m *MultiError) Error() string {
+	parts := make([]string, 0, len(m.Errors))
+	for _, err := range m.Errors {
+		parts = append(parts, err.Error())
+	}
+	return fmt.Sprintf("requests: %d error(s) occurred: %s", len(m.Errors), strings.Join(parts, "; "))
+}
+
+type txStep struct {
+	name     string
+	build    func(ctx context.Context) (*Request, error)
+	rollback func(ctx context.Context, resp *Response) error
+	resp     *Response
+}
+
+// Transaction runs a sequence of requests that must all succeed, rolling
+// back the already-completed steps, in reverse order, the moment one
+// fails. Use it for provisioning flows where several POSTs create
+// resources that must be cleaned up together on partial failure.
+type Transaction struct {
+	steps []*txStep
+}
+
+// NewTransaction creates an empty Transaction.
+func NewTransaction() *Transaction {
+	return &Transaction{}
+}
+
+// Add appends a step under name. build is called with the Transaction's
+// run context immediately before the step is sent, so it can read earlier
+// steps' responses via TransactionResult(ctx, name) to build a request
+// that depends on them. rollback, if non-nil, is invoked with the same
+// context and this step's response if a later step fails.
+func (tx *Transaction) Add(name string, build func(ctx context.Context) (*Request, error), rollback func(ctx context.Context, resp *Response) error) *Transaction {
+	tx.steps = append(tx.steps, &txStep{name: name, build: build, rollback: rollback})
+	return tx
+}
+
+type txResultsContextKeyType struct{}
+
+var txResultsContextKey = txResultsContextKeyType{}
+
+// TransactionResult returns the response of the step named name, for use
+// from a Transaction build or rollback function via its ctx argument.
+func TransactionResult(ctx context.Context, name string) (*Response, bool) {
+	results, _ := ctx.Value(txResultsContextKey).(map[string]*Response)
+	resp, ok := results[name]
+	return resp, ok
+}
+
+// Run executes every step in order. On the first failure - building a
+// step's request or sending it - it runs the rollbacks of every
+// already-completed step, in reverse order. If every rollback succeeds it
+// returns the original failure itself; otherwise it returns a *MultiError
+// with the original failure first and the rollback errors after it. It
+// returns nil once every step has succeeded.
+func (tx *Transaction) Run(ctx context.Context) error {
+	results := map[string]*Response{}
+	ctx = context.WithValue(ctx, txResultsContextKey, results)
+
+	var completed []*txStep
+	for _, step := range tx.steps {
+		req, err := step.build(ctx)
+		if err != nil {
+			return tx.rollback(ctx, completed, fmt.Errorf("requests: building step %q: %v", step.name, err))
+		}
+		resp, err := req.Do()
+		if err != nil {
+			return tx.rollback(ctx, completed, fmt.Errorf("requests: step %q: %v", step.name, err))
+		}
+		step.resp = resp
+		results[step.name] = resp
+		completed = append(completed, step)
+	}
+	return nil
+}
+
+func (tx *Transaction) rollback(ctx context.Context, completed []*txStep, cause error) error {
+	errs := []error{cause}
+	for i := len(completed) - 1; i >= 0; i-- {
+		step := completed[i]
+		if step.rollback == nil {
+			continue
+		}
+		if err := step.rollback(ctx, step.resp); err != nil {
+			errs = append(errs, fmt.Errorf("requests: rollback %q: %v", step.name, err))
+		}
+	}
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	return &MultiError{Errors: errs}
+}
@@ -0,0 +1,104 @@
+package requests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestTransactionRollsBackOnFailure(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+
+	mock.HandleFunc("^/a$", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			return
+		}
+		w.Write([]byte("id-a"))
+	})
+	mock.HandleFunc("^/b$", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			return
+		}
+		w.Write([]byte("id-b"))
+	})
+	var rolledBack []string
+
+	tx := NewTransaction()
+	tx.Add("a",
+		func(ctx context.Context) (*Request, error) {
+			return NewRequest().SetURLByStr(mock.URL() + "/a"), nil
+		},
+		func(ctx context.Context, resp *Response) error {
+			rolledBack = append(rolledBack, "a")
+			_, err := NewRequest().SetURLByStr(mock.URL() + "/a").Delete("")
+			return err
+		})
+	tx.Add("b",
+		func(ctx context.Context) (*Request, error) {
+			if _, ok := TransactionResult(ctx, "a"); !ok {
+				return nil, fmt.Errorf("step b: missing result of step a")
+			}
+			return NewRequest().SetURLByStr(mock.URL() + "/b"), nil
+		},
+		func(ctx context.Context, resp *Response) error {
+			rolledBack = append(rolledBack, "b")
+			_, err := NewRequest().SetURLByStr(mock.URL() + "/b").Delete("")
+			return err
+		})
+	tx.Add("c",
+		func(ctx context.Context) (*Request, error) {
+			// A connection that's refused outright, standing in for the
+			// step that fails.
+			return NewRequest().SetURLByStr("http://127.0.0.1:1/c"), nil
+		},
+		nil)
+
+	err = tx.Run(context.Background())
+	if err == nil {
+		t.Fatal("expected an error from the failing step")
+	}
+
+	if len(rolledBack) != 2 || rolledBack[0] != "b" || rolledBack[1] != "a" {
+		t.Errorf("rolledBack = %v, want [b a]", rolledBack)
+	}
+}
+
+func TestTransactionSucceeds(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+
+	var rolledBack bool
+	tx := NewTransaction()
+	tx.Add("only",
+		func(ctx context.Context) (*Request, error) {
+			return NewRequest().SetURLByStr(mock.URL()), nil
+		},
+		func(ctx context.Context, resp *Response) error {
+			rolledBack = true
+			return nil
+		})
+
+	if err := tx.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if rolledBack {
+		t.Error("rollback should not run when every step succeeds")
+	}
+}
@@ -0,0 +1,83 @@
+package requests
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// transportSwapGracePeriod is how long a transport that has been swapped
+// out by reconfigureTransport is kept alive (and its idle connections
+// open) before CloseIdleConnections is called on it, giving requests that
+// started on it before the swap time to finish.
+const transportSwapGracePeriod = 30 * time.Second
+
+// transportSwapper is installed as http.Client.Transport so that
+// reconfigureTransport can replace the *http.Transport underneath a
+// running Client without mutating the one in-flight requests are using.
+// RoundTrip always dispatches to whichever transport is current at the
+// time it's called, so a request already inside RoundTrip keeps running
+// against the transport it started on even if it's swapped out a moment
+// later.
+type transportSwapper struct {
+	current atomic.Value // *http.Transport
+}
+
+func newTransportSwapper(t *http.Transport) *transportSwapper {
+	s := &transportSwapper{}
+	s.current.Store(t)
+	return s
+}
+
+// Load returns the transport currently in effect.
+func (s *transportSwapper) Load() *http.Transport {
+	return s.current.Load().(*http.Transport)
+}
+
+// Swap installs next as the current transport and returns the one it replaced.
+func (s *transportSwapper) Swap(next *http.Transport) *http.Transport {
+	old := s.Load()
+	s.current.Store(next)
+	return old
+}
+
+func (s *transportSwapper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return s.Load().RoundTrip(req)
+}
+
+// getTransportSwapper returns the transportSwapper installed as the
+// client's RoundTripper, installing one around a fresh *http.Transport if
+// none is set yet. It fails with ErrNotTransport if some other
+// http.RoundTripper (e.g. one installed by SetWireSizeAccounting) has
+// since taken that slot.
+func (c *Client) getTransportSwapper() (*transportSwapper, error) {
+	switch t := c.cli.Transport.(type) {
+	case nil:
+		s := newTransportSwapper(&http.Transport{})
+		c.cli.Transport = s
+		return s, nil
+	case *transportSwapper:
+		return t, nil
+	default:
+		return nil, ErrNotTransport
+	}
+}
+
+// reconfigureTransport swaps the client's transport for a modified clone
+// of the current one, atomically, instead of mutating the live transport
+// in place. Requests already in flight keep running against the
+// transport they started on; the replaced one is closed after
+// transportSwapGracePeriod. This is what lets SetProxyURL and
+// SetTLSClientConfig be called safely while the client is handling
+// traffic (e.g. rotating proxies on a schedule).
+func (c *Client) reconfigureTransport(mutate func(*http.Transport)) error {
+	s, err := c.getTransportSwapper()
+	if err != nil {
+		return err
+	}
+	next := s.Load().Clone()
+	mutate(next)
+	old := s.Swap(next)
+	time.AfterFunc(transportSwapGracePeriod, old.CloseIdleConnections)
+	return nil
+}
@@ -0,0 +1,68 @@
+package requests
+
+import (
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// TestConcurrentTransportReconfiguration exercises SetProxyFunc and
+// SetSkipVerify concurrently with in-flight requests, run with -race to
+// catch any data race in reconfigureTransport's swap. Every request
+// should still succeed: once a goroutine's RoundTrip call starts, it runs
+// against the transport that was current at that moment, undisturbed by
+// later reconfiguration.
+func TestConcurrentTransportReconfiguration(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+
+	cli := NewClient()
+
+	stop := make(chan struct{})
+	var reconfigureWg sync.WaitGroup
+	reconfigureWg.Add(1)
+	go func() {
+		defer reconfigureWg.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			cli.SetProxyFunc(func(*http.Request) (*url.URL, error) { return nil, nil })
+			cli.SetSkipVerify(i%2 == 0)
+		}
+	}()
+
+	var failed int32
+	var requestWg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		requestWg.Add(1)
+		go func() {
+			defer requestWg.Done()
+			for j := 0; j < 20; j++ {
+				if _, err := cli.NewRequest().SetURLByStr(mock.URL()).Get("/"); err != nil {
+					atomic.AddInt32(&failed, 1)
+				}
+			}
+		}()
+	}
+	requestWg.Wait()
+	close(stop)
+	reconfigureWg.Wait()
+
+	if failed != 0 {
+		t.Errorf("%d/%d requests failed while the transport was being reconfigured concurrently", failed, 20*20)
+	}
+}
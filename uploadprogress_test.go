@@ -0,0 +1,100 @@
+package requests
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func TestSetUploadProgressReportsFinalCallbackAtBodyLength(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	const size = 512 * 1024
+	payload := bytes.Repeat([]byte("u"), size)
+
+	var gotServerSide int
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		gotServerSide = len(body)
+	})
+
+	var writtens []int64
+	var totals []int64
+	_, err = NewRequest().SetURLByStr(mock.URL()).
+		SetBody(bytes.NewReader(payload)).
+		SetUploadProgress(func(written, total int64) {
+			writtens = append(writtens, written)
+			totals = append(totals, total)
+		}).
+		Post("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if gotServerSide != size {
+		t.Fatalf("server received %d bytes, want %d", gotServerSide, size)
+	}
+	if len(writtens) == 0 {
+		t.Fatal("want at least one progress callback")
+	}
+	for i, total := range totals {
+		if total != size {
+			t.Errorf("totals[%d] = %d, want %d", i, total, size)
+		}
+	}
+	for i := 1; i < len(writtens); i++ {
+		if writtens[i] < writtens[i-1] {
+			t.Errorf("writtens[%d] = %d < writtens[%d] = %d, want monotonically increasing", i, writtens[i], i-1, writtens[i-1])
+		}
+	}
+	if last := writtens[len(writtens)-1]; last != int64(gotServerSide) {
+		t.Errorf("last reported written = %d, want %d (bytes the server received)", last, gotServerSide)
+	}
+}
+
+func TestSetUploadProgressResetsOnRetry(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	const body = "retry me please"
+	attempt := 0
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		attempt++
+		ioutil.ReadAll(r.Body)
+		if attempt == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	var finalPerAttempt []int64
+	var current int64
+	_, err = NewRequest().SetURLByStr(mock.URL()).
+		SetBodyString(body).
+		SetRetry(2).
+		SetUploadProgress(func(written, total int64) {
+			if written < current {
+				finalPerAttempt = append(finalPerAttempt, current)
+			}
+			current = written
+		}).
+		Post("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attempt != 2 {
+		t.Fatalf("server saw %d attempts, want 2", attempt)
+	}
+	if current != int64(len(body)) {
+		t.Errorf("final written = %d, want %d", current, len(body))
+	}
+}
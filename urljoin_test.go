@@ -0,0 +1,102 @@
+package requests
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestURLJoinRFC3986TableDriven(t *testing.T) {
+	tests := []struct {
+		name     string
+		base     string
+		relative string
+		want     string
+	}{
+		{"no trailing slash drops last segment", "https://api.example.com/v1", "users", "https://api.example.com/users"},
+		{"trailing slash preserves prefix", "https://api.example.com/v1/", "users", "https://api.example.com/v1/users"},
+		{"leading slash on relative replaces whole path", "https://api.example.com/v1/", "/users", "https://api.example.com/users"},
+		{"dot segments are resolved away", "https://api.example.com/v1/a/", "../users", "https://api.example.com/v1/users"},
+		{"empty relative keeps the base as-is", "https://api.example.com/v1/", "", "https://api.example.com/v1/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := NewRequest().SetURLByStr(tt.base)
+			if tt.relative != "" {
+				req.SetURLByStr(tt.relative)
+			}
+			u := req.GetURL("")
+			if got := u.String(); got != tt.want {
+				t.Errorf("join(%q, %q) = %q, want %q", tt.base, tt.relative, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestURLJoinPathAppendTableDriven(t *testing.T) {
+	tests := []struct {
+		name     string
+		base     string
+		relative string
+		want     string
+	}{
+		{"no trailing or leading slash", "https://api.example.com/v1", "users", "https://api.example.com/v1/users"},
+		{"trailing slash on base only", "https://api.example.com/v1/", "users", "https://api.example.com/v1/users"},
+		{"leading slash on relative only", "https://api.example.com/v1", "/users", "https://api.example.com/v1/users"},
+		{"both trailing and leading slash", "https://api.example.com/v1/", "/users", "https://api.example.com/v1/users"},
+		// appendPath itself keeps "../users" literal; it's processURL's
+		// later pathParam-assembly pass (run for both join modes, to
+		// resolve the placeholder-substituted path against itself) that
+		// normalizes the dot segments here. Since it resolves the literal
+		// joined path against itself, normalizing is all it does -- it
+		// can't rediscard the "v1" prefix PathAppend just preserved.
+		{"dot segments end up normalized, but the prefix survives", "https://api.example.com/v1/a/", "../users", "https://api.example.com/v1/users"},
+		{"empty relative keeps the base path", "https://api.example.com/v1/", "", "https://api.example.com/v1/"},
+		{"an absolute URL still overrides entirely", "https://api.example.com/v1/", "https://other.example.com/x", "https://other.example.com/x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient().SetURLJoinMode(JoinPathAppend)
+			req := client.NewRequest().SetURLByStr(tt.base)
+			if tt.relative != "" {
+				req.SetURLByStr(tt.relative)
+			}
+			u := req.GetURL("")
+			if got := u.String(); got != tt.want {
+				t.Errorf("join(%q, %q) = %q, want %q", tt.base, tt.relative, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestURLJoinRFC3986WarnsWhenBasePathIsDropped(t *testing.T) {
+	var logBuf bytes.Buffer
+	client := NewClient().SetLogger(&logBuf)
+	client.NewRequest().SetURLByStr("https://api.example.com/v1").SetURLByStr("users")
+
+	if logBuf.Len() == 0 {
+		t.Fatal("expected a warning to be logged when the base path is dropped")
+	}
+	if got := logBuf.String(); !bytes.Contains([]byte(got), []byte("dropped the base path")) {
+		t.Errorf("log output = %q, want it to mention the dropped base path", got)
+	}
+}
+
+func TestURLJoinRFC3986DoesNotWarnWhenPrefixIsPreserved(t *testing.T) {
+	var logBuf bytes.Buffer
+	client := NewClient().SetLogger(&logBuf)
+	client.NewRequest().SetURLByStr("https://api.example.com/v1/").SetURLByStr("users")
+
+	if logBuf.Len() != 0 {
+		t.Errorf("log output = %q, want no warning when the trailing slash already preserves the prefix", logBuf.String())
+	}
+}
+
+func TestURLJoinPathAppendNeverWarns(t *testing.T) {
+	var logBuf bytes.Buffer
+	client := NewClient().SetLogger(&logBuf).SetURLJoinMode(JoinPathAppend)
+	client.NewRequest().SetURLByStr("https://api.example.com/v1").SetURLByStr("users")
+
+	if logBuf.Len() != 0 {
+		t.Errorf("log output = %q, want no warning in JoinPathAppend mode, which never drops the prefix", logBuf.String())
+	}
+}
@@ -2,13 +2,19 @@ package requests
 
 import (
 	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"mime"
 	"mime/multipart"
 	"net/http"
+	"net/textproto"
 	"net/url"
+	"reflect"
 	"regexp"
 	"sort"
 	"strings"
@@ -17,6 +23,7 @@ import (
 	"golang.org/x/net/html/charset"
 	"golang.org/x/text/encoding"
 	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
 )
 
 // Common HTTP methods.
@@ -33,6 +40,16 @@ const (
 	MethodOptions = "OPTIONS"
 	MethodTrace   = "TRACE"
 
+	// WebDAV methods, RFC 4918.
+	MethodPropfind  = "PROPFIND"
+	MethodProppatch = "PROPPATCH"
+	MethodMkcol     = "MKCOL"
+	MethodCopy      = "COPY"
+	MethodMove      = "MOVE"
+	MethodLock      = "LOCK"
+	MethodUnlock    = "UNLOCK"
+	MethodReport    = "REPORT" // RFC 3253 section 3.6
+
 	charsetPrefix   = "; charset="
 	charsetUTF8     = charsetPrefix + "utf-8"
 	MimeJSON        = "application/json" + charsetUTF8
@@ -41,15 +58,61 @@ const (
 	MimeOctetStream = "application/octet-stream" + charsetUTF8
 	MimeURLEncoded  = "application/x-www-form-urlencoded" + charsetUTF8
 	MimeFormData    = "multipart/form-data" + charsetUTF8
-
-	HeaderUserAgent       = "User-Agent"
-	HeaderAccept          = "Accept"
-	HeaderContentType     = "Content-Type"
-	HeaderContentLength   = "Content-Length"
-	HeaderContentEncoding = "Content-Encoding"
-	HeaderAuthorization   = "Authorization"
+	MimeNDJSON      = "application/x-ndjson" + charsetUTF8
+	MimeYAML        = "application/x-yaml" + charsetUTF8
+	// MimeProtobuf has no charset parameter, unlike this block's other
+	// constants: it names a binary wire format, so "charset" doesn't
+	// apply. See package requests/pb for Protobuf request/response
+	// support.
+	MimeProtobuf = "application/x-protobuf"
+	// MimeMsgPack is also a binary wire format; see package
+	// requests/msgpack for MessagePack request/response support.
+	MimeMsgPack = "application/msgpack"
+
+	HeaderUserAgent          = "User-Agent"
+	HeaderAccept             = "Accept"
+	HeaderContentType        = "Content-Type"
+	HeaderContentLength      = "Content-Length"
+	HeaderContentEncoding    = "Content-Encoding"
+	HeaderAuthorization      = "Authorization"
+	HeaderExpect             = "Expect"
+	HeaderAcceptEncoding     = "Accept-Encoding"
+	HeaderRange              = "Range"
+	HeaderContentRange       = "Content-Range"
+	HeaderIfNoneMatch        = "If-None-Match"
+	HeaderIfMatch            = "If-Match"
+	HeaderIfModifiedSince    = "If-Modified-Since"
+	HeaderIfUnmodifiedSince  = "If-Unmodified-Since"
+	HeaderETag               = "ETag"
+	HeaderLastModified       = "Last-Modified"
+	HeaderConnection         = "Connection"
+	HeaderTransferEncoding   = "Transfer-Encoding"
+	HeaderKeepAlive          = "Keep-Alive"
+	HeaderContentDisposition = "Content-Disposition"
+
+	// WebDAV headers, RFC 4918.
+	HeaderDepth       = "Depth"
+	HeaderDestination = "Destination"
+	HeaderOverwrite   = "Overwrite"
+	HeaderLockToken   = "Lock-Token"
+
+	// HeaderIdempotencyKey is the de facto standard header (popularized by
+	// Stripe) a client sets to the same value across retries of one
+	// logical request, so a server can recognize and dedupe a retried
+	// write instead of applying it twice. See Request.SetIdempotencyKey.
+	HeaderIdempotencyKey = "Idempotency-Key"
 )
 
+// quoteETag wraps etag in double quotes per RFC 7232 section 2.3, unless
+// it's already a quoted strong or weak ("W/\"...\"") entity tag, or the
+// "*" sentinel, which are passed through unchanged.
+func quoteETag(etag string) string {
+	if etag == "*" || strings.HasPrefix(etag, `"`) || strings.HasPrefix(etag, `W/"`) {
+		return etag
+	}
+	return `"` + etag + `"`
+}
+
 // Default
 var (
 	DefaultPrefix         = "REQUESTS"
@@ -151,6 +214,11 @@ type multiFile struct {
 	Param       string
 	FileName    string
 	ContentType string
+	// Header, if non-nil, is merged into the part's MIME header: any
+	// Content-Disposition/Content-Type it sets take precedence over the
+	// ones toMulti computes from Param/FileName/ContentType, and any
+	// other entries (e.g. Content-ID) are passed through as-is.
+	Header textproto.MIMEHeader
 	io.Reader
 }
 
@@ -179,7 +247,7 @@ func toQuery(p paramPairs, tr transform.Transformer) (string, error) {
 			vv, err := url.QueryUnescape(val)
 			if err == nil {
 				vv, _, err = transform.String(tr, vv)
-				if err != nil {
+				if err == nil {
 					val = vv
 				}
 			}
@@ -213,6 +281,48 @@ func toPath(path string, p paramPairs, tr transform.Transformer) (string, error)
 	return path, nil
 }
 
+// matrixParam is one key=value pair queued by Request.SetMatrixParam
+// against a named path segment.
+type matrixParam struct {
+	Segment string
+	Key     string
+	Value   string
+}
+
+// applyMatrixParams appends each param's ";key=value" to every occurrence
+// of its named segment in path, in the order SetMatrixParam was called.
+func applyMatrixParams(path string, params []*matrixParam) string {
+	bySegment := map[string][]*matrixParam{}
+	for _, p := range params {
+		bySegment[p.Segment] = append(bySegment[p.Segment], p)
+	}
+
+	segments := strings.Split(path, "/")
+	for i, seg := range segments {
+		mp, ok := bySegment[seg]
+		if !ok {
+			continue
+		}
+		var b strings.Builder
+		b.WriteString(seg)
+		for _, p := range mp {
+			b.WriteByte(';')
+			b.WriteString(escapeMatrixComponent(p.Key))
+			b.WriteByte('=')
+			b.WriteString(escapeMatrixComponent(p.Value))
+		}
+		segments[i] = b.String()
+	}
+	return strings.Join(segments, "/")
+}
+
+// escapeMatrixComponent percent-escapes s for use as a matrix parameter
+// key or value: url.PathEscape already escapes ';' and ',', and this
+// additionally escapes '=', the one matrix-syntax character it leaves alone.
+func escapeMatrixComponent(s string) string {
+	return strings.ReplaceAll(url.PathEscape(s), "=", "%3D")
+}
+
 func toForm(p paramPairs, tr transform.Transformer) (io.Reader, string, error) {
 	vs := url.Values{}
 	for _, v := range p {
@@ -229,10 +339,135 @@ func toForm(p paramPairs, tr transform.Transformer) (io.Reader, string, error) {
 	return bytes.NewBufferString(vs.Encode()), MimeURLEncoded, nil
 }
 
-func toMulti(p paramPairs, m multiFiles, tr transform.Transformer) (io.Reader, string, error) {
+// structToFormValues flattens v, a struct or pointer to struct, into
+// url.Values for Request.SetPayload's form encoding, one entry per
+// exported field. A field's name comes from its "form" tag, falling back
+// to the Go field name; "form:\"-\"" skips the field. A []string field
+// contributes one value per element; anything else is formatted with
+// fmt.Sprint. v must be a struct or *struct, or this returns an error.
+func structToFormValues(v interface{}) (url.Values, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return url.Values{}, nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("requests: SetPayload: form encoding requires a struct, got %T", v)
+	}
+
+	values := url.Values{}
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if field.PkgPath != "" { // unexported
+			continue
+		}
+		name := field.Tag.Get("form")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fv := rv.Field(i)
+		if ss, ok := fv.Interface().([]string); ok {
+			for _, s := range ss {
+				values.Add(name, s)
+			}
+			continue
+		}
+		values.Add(name, fmt.Sprint(fv.Interface()))
+	}
+	return values, nil
+}
+
+// multipartBoundaryRE matches a single RFC 2046 bchars run, i.e. a boundary
+// that does not need the trailing-space exception handled separately below.
+var multipartBoundaryRE = regexp.MustCompile(`^[A-Za-z0-9'()+_,\-./:=? ]{1,70}$`)
+
+// validMultipartBoundary reports whether b is a legal multipart boundary
+// per RFC 2046 section 5.1.1: 1 to 70 characters from the bchars alphabet,
+// not ending in a space.
+func validMultipartBoundary(b string) bool {
+	if b == "" || len(b) > 70 {
+		return false
+	}
+	if strings.HasSuffix(b, " ") {
+		return false
+	}
+	return multipartBoundaryRE.MatchString(b)
+}
+
+// deriveMultipartBoundary computes a deterministic boundary from the names
+// and values of p and the names and file names of m, so that byte-identical
+// logical multipart requests produce byte-identical bodies across runs.
+// File contents are streamed and are intentionally not hashed.
+func deriveMultipartBoundary(p paramPairs, m multiFiles) string {
+	h := sha256.New()
+	for _, v := range p {
+		io.WriteString(h, v.Param)
+		h.Write([]byte{0})
+		io.WriteString(h, v.Value)
+		h.Write([]byte{0})
+	}
+	for _, v := range m {
+		io.WriteString(h, v.Param)
+		h.Write([]byte{0})
+		io.WriteString(h, v.FileName)
+		h.Write([]byte{0})
+	}
+	return "reqs" + hex.EncodeToString(h.Sum(nil))[:32]
+}
+
+// multipartQuoteEscaper escapes backslashes and double quotes so a field
+// or file name can be embedded in a quoted-string header parameter, the
+// same escaping mime/multipart.Writer.CreateFormFile applies internally.
+var multipartQuoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// multipartControlStripper removes control characters, notably CR and LF,
+// which mime/multipart.Writer.CreatePart does not neutralize: left
+// unescaped, a filename like "a\r\nX-Injected: pwned" would break out of
+// the quoted-string value and inject an arbitrary header line into the
+// part.
+func multipartControlStripper(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// multipartFormDataDisposition builds the Content-Disposition value for a
+// multipart file part. Unicode in fieldname/filename is written through
+// as-is inside the quoted string, which Go's own mime.ParseMediaType (and
+// so multipart.Reader) reads back correctly; control characters are
+// stripped and backslashes/quotes escaped to keep the quoted string
+// well-formed and confined to a single header line.
+func multipartFormDataDisposition(fieldname, filename string) string {
+	fieldname = multipartControlStripper(fieldname)
+	filename = multipartControlStripper(filename)
+	return fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+		multipartQuoteEscaper.Replace(fieldname), multipartQuoteEscaper.Replace(filename))
+}
+
+func toMulti(p paramPairs, m multiFiles, tr transform.Transformer, boundary string) (io.Reader, string, error) {
 	buf := bytes.NewBuffer(nil)
 	mw := multipart.NewWriter(buf)
 
+	if boundary != "" {
+		for _, v := range p {
+			if strings.Contains(v.Value, boundary) {
+				return nil, "", fmt.Errorf("requests: multipart boundary %q collides with field %q content", boundary, v.Param)
+			}
+		}
+		if err := mw.SetBoundary(boundary); err != nil {
+			return nil, "", err
+		}
+	}
+
 	for _, v := range p {
 		val := v.Value
 		if tr != nil {
@@ -249,7 +484,21 @@ func toMulti(p paramPairs, m multiFiles, tr transform.Transformer) (io.Reader, s
 	}
 
 	for _, v := range m {
-		w, err := mw.CreateFormFile(v.Param, v.FileName)
+		contentType := v.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		h := make(textproto.MIMEHeader)
+		for k, vs := range v.Header {
+			h[k] = vs
+		}
+		if h.Get("Content-Disposition") == "" {
+			h.Set("Content-Disposition", multipartFormDataDisposition(v.Param, v.FileName))
+		}
+		if h.Get(HeaderContentType) == "" {
+			h.Set(HeaderContentType, contentType)
+		}
+		w, err := mw.CreatePart(h)
 		if err != nil {
 			return nil, "", err
 		}
@@ -276,6 +525,33 @@ func basicAuth(username, password string) string {
 	return base64.StdEncoding.EncodeToString([]byte(auth))
 }
 
+// BasicAuthHeader builds the value of a "Basic" Authorization header per
+// RFC 7617: username must not contain a colon, since that's the
+// separator, so a username with one is rejected rather than silently
+// producing credentials nothing can parse back apart. Both username and
+// password are normalized to Unicode NFC before encoding, as RFC 7617
+// requires for a server advertising charset="UTF-8"; for an
+// all-ASCII username and password this is a no-op.
+func BasicAuthHeader(username, password string) (string, error) {
+	if strings.Contains(username, ":") {
+		return "", fmt.Errorf("requests: BasicAuthHeader: username must not contain ':': %q", username)
+	}
+	auth := norm.NFC.String(username) + ":" + norm.NFC.String(password)
+	return base64.StdEncoding.EncodeToString([]byte(auth)), nil
+}
+
+// newUUIDv4 generates a random RFC 4122 version 4 UUID, e.g. for
+// Request.WithAutoIdempotencyKey.
+func newUUIDv4() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40 // version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // variant 10
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
 func readCookies(line string) (cookies []*http.Cookie) {
 	parts := strings.Split(strings.TrimSpace(line), ";")
 	if len(parts) == 1 && parts[0] == "" {
@@ -333,6 +609,25 @@ func URL(raw interface{}) *url.URL {
 }
 
 // TryCharset try charset
+// maxSizeReader wraps r, failing with *ErrResponseTooLarge once more than
+// limit bytes have been read instead of silently truncating the way a
+// plain io.LimitedReader would.
+type maxSizeReader struct {
+	r             io.Reader
+	limit         int64
+	read          int64
+	contentLength int64
+}
+
+func (m *maxSizeReader) Read(p []byte) (int, error) {
+	n, err := m.r.Read(p)
+	m.read += int64(n)
+	if m.read > m.limit {
+		return n, &ErrResponseTooLarge{Limit: m.limit, BytesRead: m.read, ContentLength: m.contentLength}
+	}
+	return n, err
+}
+
 func TryCharset(r io.Reader, contentType string) (io.Reader, string, error) {
 	mediatype, params, err := mime.ParseMediaType(contentType)
 	if err == nil {
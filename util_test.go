@@ -5,7 +5,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"regexp"
+	"strconv"
 	"sync"
+	"time"
 )
 
 // Mock represent a registry mock
@@ -60,6 +62,21 @@ func (tr *Mock) URL() string {
 	return tr.hostport
 }
 
+// RequestDeadlineRemaining parses the remaining-time-in-milliseconds value
+// a Client.SetDeadlinePropagation header carries on r, for asserting on it
+// from a handler registered with HandleFunc.
+func (tr *Mock) RequestDeadlineRemaining(r *http.Request, header string) (time.Duration, bool) {
+	v := r.Header.Get(header)
+	if v == "" {
+		return 0, false
+	}
+	ms, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return time.Duration(ms) * time.Millisecond, true
+}
+
 // Close closes mock and releases resources
 func (tr *Mock) Close() {
 	tr.server.Close()
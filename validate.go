@@ -0,0 +1,284 @@
+package requests
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"regexp"
+	"strings"
+)
+
+// ResponseValidator inspects a successful response and returns an error if
+// it violates some contract the caller cares about, e.g. a JSON schema
+// (ValidateJSONSchema). Validators run in Client.AddResponseValidator's
+// registration order after ExpectContentType's check, and stop at the
+// first error.
+type ResponseValidator func(req *Request, resp *Response) error
+
+// AddResponseValidator registers a ResponseValidator run by every request
+// made through this client, unless skipped with
+// Request.SetSkipResponseValidation or Request.SetDiscardResponse.
+func (c *Client) AddResponseValidator(v ResponseValidator) *Client {
+	c.responseValidators = append(c.responseValidators, v)
+	return c
+}
+
+// SetSkipResponseValidation opts a single request out of the client's
+// registered ResponseValidators.
+func (r *Request) SetSkipResponseValidation(skip bool) *Request {
+	r.skipResponseValidation = skip
+	return r
+}
+
+// SchemaViolation describes a single JSON Schema constraint a value failed,
+// located by an RFC 6901 JSON Pointer into the document.
+type SchemaViolation struct {
+	Pointer string
+	Message string
+}
+
+// ErrSchemaValidation is returned by a ValidateJSONSchema validator when a
+// response body fails to satisfy the schema, listing every violation found.
+type ErrSchemaValidation struct {
+	Violations []SchemaViolation
+}
+
+func (e *ErrSchemaValidation) Error() string {
+	msgs := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		msgs[i] = fmt.Sprintf("%s: %s", v.Pointer, v.Message)
+	}
+	return fmt.Sprintf("requests: response failed schema validation: %s", strings.Join(msgs, "; "))
+}
+
+// ValidateJSONSchema builds a ResponseValidator that parses schema as a
+// JSON Schema document and checks it against the body of responses whose
+// content type is JSON, ignoring any other response. It supports a
+// practical subset of draft-7: type, enum, required, properties,
+// additionalProperties (bool form only), items, minimum, maximum,
+// minLength, maxLength, minItems, maxItems and pattern.
+func ValidateJSONSchema(schema []byte) (ResponseValidator, error) {
+	var root interface{}
+	if err := json.Unmarshal(schema, &root); err != nil {
+		return nil, fmt.Errorf("requests: invalid JSON schema: %w", err)
+	}
+
+	return func(req *Request, resp *Response) error {
+		mediatype, _, err := mime.ParseMediaType(resp.ContentType())
+		if err != nil || !matchMediaType(mediatype, []string{"application/json", "*/json"}) {
+			return nil
+		}
+
+		var doc interface{}
+		if err := json.Unmarshal(resp.Body(), &doc); err != nil {
+			return &ErrSchemaValidation{Violations: []SchemaViolation{
+				{Pointer: "", Message: "body is not valid JSON: " + err.Error()},
+			}}
+		}
+
+		var violations []SchemaViolation
+		validateJSONSchema(root, doc, "", &violations)
+		if len(violations) > 0 {
+			return &ErrSchemaValidation{Violations: violations}
+		}
+		return nil
+	}, nil
+}
+
+// validateJSONSchema recursively checks value against schema, appending a
+// SchemaViolation for each failed constraint, pointer-addressed from doc's
+// root.
+func validateJSONSchema(schema, value interface{}, pointer string, violations *[]SchemaViolation) {
+	s, ok := schema.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	if declared, ok := s["type"]; ok && !jsonSchemaTypeMatches(declared, value) {
+		*violations = append(*violations, SchemaViolation{
+			Pointer: pointer,
+			Message: fmt.Sprintf("have type %s, want %v", jsonSchemaTypeName(value), declared),
+		})
+		return
+	}
+
+	if enum, ok := s["enum"].([]interface{}); ok {
+		matched := false
+		for _, want := range enum {
+			if jsonSchemaDeepEqual(want, value) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			*violations = append(*violations, SchemaViolation{
+				Pointer: pointer,
+				Message: fmt.Sprintf("value %v is not one of %v", value, enum),
+			})
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, name := range jsonSchemaStrings(s["required"]) {
+			if _, ok := v[name]; !ok {
+				*violations = append(*violations, SchemaViolation{
+					Pointer: pointer,
+					Message: fmt.Sprintf("missing required property %q", name),
+				})
+			}
+		}
+		props, _ := s["properties"].(map[string]interface{})
+		if additional, ok := s["additionalProperties"].(bool); ok && !additional {
+			for name := range v {
+				if _, ok := props[name]; !ok {
+					*violations = append(*violations, SchemaViolation{
+						Pointer: jsonPointerChild(pointer, name),
+						Message: "additional property not allowed",
+					})
+				}
+			}
+		}
+		for name, propSchema := range props {
+			if child, ok := v[name]; ok {
+				validateJSONSchema(propSchema, child, jsonPointerChild(pointer, name), violations)
+			}
+		}
+	case []interface{}:
+		if min, ok := jsonSchemaNumber(s["minItems"]); ok && float64(len(v)) < min {
+			*violations = append(*violations, SchemaViolation{
+				Pointer: pointer,
+				Message: fmt.Sprintf("has %d items, want at least %v", len(v), min),
+			})
+		}
+		if max, ok := jsonSchemaNumber(s["maxItems"]); ok && float64(len(v)) > max {
+			*violations = append(*violations, SchemaViolation{
+				Pointer: pointer,
+				Message: fmt.Sprintf("has %d items, want at most %v", len(v), max),
+			})
+		}
+		if items, ok := s["items"]; ok {
+			for i, elem := range v {
+				validateJSONSchema(items, elem, fmt.Sprintf("%s/%d", pointer, i), violations)
+			}
+		}
+	case string:
+		if min, ok := jsonSchemaNumber(s["minLength"]); ok && float64(len(v)) < min {
+			*violations = append(*violations, SchemaViolation{
+				Pointer: pointer,
+				Message: fmt.Sprintf("length %d is shorter than minLength %v", len(v), min),
+			})
+		}
+		if max, ok := jsonSchemaNumber(s["maxLength"]); ok && float64(len(v)) > max {
+			*violations = append(*violations, SchemaViolation{
+				Pointer: pointer,
+				Message: fmt.Sprintf("length %d is longer than maxLength %v", len(v), max),
+			})
+		}
+		if pattern, ok := s["pattern"].(string); ok {
+			if matched, err := regexp.MatchString(pattern, v); err == nil && !matched {
+				*violations = append(*violations, SchemaViolation{
+					Pointer: pointer,
+					Message: fmt.Sprintf("value %q does not match pattern %q", v, pattern),
+				})
+			}
+		}
+	case float64:
+		if min, ok := jsonSchemaNumber(s["minimum"]); ok && v < min {
+			*violations = append(*violations, SchemaViolation{
+				Pointer: pointer,
+				Message: fmt.Sprintf("value %v is less than minimum %v", v, min),
+			})
+		}
+		if max, ok := jsonSchemaNumber(s["maximum"]); ok && v > max {
+			*violations = append(*violations, SchemaViolation{
+				Pointer: pointer,
+				Message: fmt.Sprintf("value %v is greater than maximum %v", v, max),
+			})
+		}
+	}
+}
+
+// jsonSchemaTypeName reports the JSON Schema type name of value, treating
+// a float64 with no fractional part as "integer" rather than "number".
+func jsonSchemaTypeName(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if v == float64(int64(v)) {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return "unknown"
+	}
+}
+
+// jsonSchemaTypeMatches reports whether value satisfies declared, which may
+// be a single type name or an array of allowed type names. A value typed
+// "integer" also satisfies a declared "number".
+func jsonSchemaTypeMatches(declared, value interface{}) bool {
+	have := jsonSchemaTypeName(value)
+	check := func(want string) bool {
+		return want == have || (want == "number" && have == "integer")
+	}
+	switch d := declared.(type) {
+	case string:
+		return check(d)
+	case []interface{}:
+		for _, w := range d {
+			if s, ok := w.(string); ok && check(s) {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+func jsonSchemaNumber(v interface{}) (float64, bool) {
+	n, ok := v.(float64)
+	return n, ok
+}
+
+func jsonSchemaStrings(v interface{}) []string {
+	list, ok := v.([]interface{})
+	if !ok {
+		return nil
+	}
+	out := make([]string, 0, len(list))
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func jsonSchemaDeepEqual(a, b interface{}) bool {
+	encodedA, errA := json.Marshal(a)
+	encodedB, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(encodedA) == string(encodedB)
+}
+
+// jsonPointerChild appends name to an RFC 6901 JSON Pointer, escaping "~"
+// and "/" as the spec requires.
+func jsonPointerChild(pointer, name string) string {
+	return pointer + "/" + jsonPointerEscape(name)
+}
+
+func jsonPointerEscape(s string) string {
+	s = strings.ReplaceAll(s, "~", "~0")
+	s = strings.ReplaceAll(s, "/", "~1")
+	return s
+}
@@ -0,0 +1,129 @@
+package requests
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+const userSchema = `{
+	"type": "object",
+	"required": ["name", "age"],
+	"properties": {
+		"name": {"type": "string", "minLength": 1},
+		"age": {"type": "integer", "minimum": 0}
+	},
+	"additionalProperties": false
+}`
+
+func TestAddResponseValidatorCustom(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("bad"))
+	})
+
+	client := NewClient().AddResponseValidator(func(req *Request, resp *Response) error {
+		if string(resp.Body()) != "good" {
+			return errors.New("unexpected body")
+		}
+		return nil
+	})
+
+	_, err = client.NewRequest().SetQuiet().SetURLByStr(mock.URL()).Get("/")
+	if err == nil {
+		t.Fatal("expected the custom validator to reject the body")
+	}
+}
+
+func TestValidateJSONSchemaAcceptsConformingPayload(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderContentType, MimeJSON)
+		w.Write([]byte(`{"name": "ada", "age": 30}`))
+	})
+
+	validator, err := ValidateJSONSchema([]byte(userSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := NewClient().AddResponseValidator(validator)
+
+	if _, err := client.NewRequest().SetURLByStr(mock.URL()).Get("/"); err != nil {
+		t.Fatalf("conforming payload should pass: %v", err)
+	}
+}
+
+func TestValidateJSONSchemaRejectsNonConformingPayload(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderContentType, MimeJSON)
+		w.Write([]byte(`{"name": "ada", "age": -1, "extra": true}`))
+	})
+
+	validator, err := ValidateJSONSchema([]byte(userSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := NewClient().AddResponseValidator(validator)
+
+	_, err = client.NewRequest().SetQuiet().SetURLByStr(mock.URL()).Get("/")
+	var schemaErr *ErrSchemaValidation
+	if !errors.As(err, &schemaErr) {
+		t.Fatalf("err = %#v, want one wrapping *ErrSchemaValidation", err)
+	}
+	if !violationsContain(schemaErr.Violations, "/age") || !violationsContain(schemaErr.Violations, "/extra") {
+		t.Errorf("violations = %+v, want ones at /age and /extra", schemaErr.Violations)
+	}
+}
+
+func TestSetSkipResponseValidationOptsOut(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderContentType, MimeJSON)
+		w.Write([]byte(`{"age": -1}`))
+	})
+
+	validator, err := ValidateJSONSchema([]byte(userSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := NewClient().AddResponseValidator(validator)
+
+	if _, err := client.NewRequest().SetSkipResponseValidation(true).SetURLByStr(mock.URL()).Get("/"); err != nil {
+		t.Fatalf("expected validation to be skipped: %v", err)
+	}
+}
+
+func violationsContain(violations []SchemaViolation, pointer string) bool {
+	for _, v := range violations {
+		if strings.HasPrefix(v.Pointer, pointer) {
+			return true
+		}
+	}
+	return false
+}
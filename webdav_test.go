@@ -0,0 +1,212 @@
+package requests
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestPropfindSetsDepthHeader(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	var gotMethod, gotDepth string
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		gotMethod = r.Method
+		gotDepth = r.Header.Get(HeaderDepth)
+		w.WriteHeader(http.StatusMultiStatus)
+	})
+
+	_, err = NewRequest().Propfind(mock.URL(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotMethod != MethodPropfind {
+		t.Errorf("method = %q, want PROPFIND", gotMethod)
+	}
+	if gotDepth != "1" {
+		t.Errorf("Depth = %q, want 1", gotDepth)
+	}
+}
+
+func TestPropfindNegativeDepthMeansInfinity(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	var gotDepth string
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		gotDepth = r.Header.Get(HeaderDepth)
+		w.WriteHeader(http.StatusMultiStatus)
+	})
+
+	_, err = NewRequest().Propfind(mock.URL(), -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotDepth != "infinity" {
+		t.Errorf("Depth = %q, want infinity", gotDepth)
+	}
+}
+
+func TestMkcolSendsMkcolMethod(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	var gotMethod string
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		gotMethod = r.Method
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	_, err = NewRequest().Mkcol(mock.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotMethod != MethodMkcol {
+		t.Errorf("method = %q, want MKCOL", gotMethod)
+	}
+}
+
+func TestMoveSetsDestinationHeader(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	var gotMethod, gotDestination string
+	mock.HandleFunc("/src", func(w http.ResponseWriter, r *http.Request) {
+		gotMethod = r.Method
+		gotDestination = r.Header.Get(HeaderDestination)
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	_, err = NewRequest().Move(mock.URL()+"/src", mock.URL()+"/dst")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotMethod != MethodMove {
+		t.Errorf("method = %q, want MOVE", gotMethod)
+	}
+	if gotDestination != mock.URL()+"/dst" {
+		t.Errorf("Destination = %q, want %q", gotDestination, mock.URL()+"/dst")
+	}
+}
+
+func TestCopySetsDestinationHeader(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	var gotMethod, gotDestination string
+	mock.HandleFunc("/src", func(w http.ResponseWriter, r *http.Request) {
+		gotMethod = r.Method
+		gotDestination = r.Header.Get(HeaderDestination)
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	_, err = NewRequest().Copy(mock.URL()+"/src", mock.URL()+"/dst")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotMethod != MethodCopy {
+		t.Errorf("method = %q, want COPY", gotMethod)
+	}
+	if gotDestination != mock.URL()+"/dst" {
+		t.Errorf("Destination = %q, want %q", gotDestination, mock.URL()+"/dst")
+	}
+}
+
+func TestLockAndUnlock(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	var gotLockMethod, gotUnlockMethod, gotLockToken string
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case MethodLock:
+			gotLockMethod = r.Method
+			w.WriteHeader(http.StatusOK)
+		case MethodUnlock:
+			gotUnlockMethod = r.Method
+			gotLockToken = r.Header.Get(HeaderLockToken)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+
+	_, err = NewRequest().Lock(mock.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotLockMethod != MethodLock {
+		t.Errorf("method = %q, want LOCK", gotLockMethod)
+	}
+
+	_, err = NewRequest().Unlock(mock.URL(), "opaquelocktoken:abc123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotUnlockMethod != MethodUnlock {
+		t.Errorf("method = %q, want UNLOCK", gotUnlockMethod)
+	}
+	if gotLockToken != "opaquelocktoken:abc123" {
+		t.Errorf("Lock-Token = %q, want opaquelocktoken:abc123", gotLockToken)
+	}
+}
+
+func TestProppatchAndReportSendCorrectMethod(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	var gotMethod string
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		gotMethod = r.Method
+		w.WriteHeader(http.StatusMultiStatus)
+	})
+
+	if _, err := NewRequest().Proppatch(mock.URL()); err != nil {
+		t.Fatal(err)
+	}
+	if gotMethod != MethodProppatch {
+		t.Errorf("method = %q, want PROPPATCH", gotMethod)
+	}
+
+	if _, err := NewRequest().Report(mock.URL()); err != nil {
+		t.Fatal(err)
+	}
+	if gotMethod != MethodReport {
+		t.Errorf("method = %q, want REPORT", gotMethod)
+	}
+}
+
+func TestWebDAVMethodsShowInString(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	resp, err := NewRequest().Mkcol(mock.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := resp.String(); got == "" {
+		t.Fatal("String() = empty")
+	}
+	want := MethodMkcol + " "
+	if got := resp.String(); got[:len(want)] != want {
+		t.Errorf("String() = %q, want it to start with %q", got, want)
+	}
+}
@@ -0,0 +1,155 @@
+package requests
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+)
+
+// WireSize holds the byte counts measured on the wire for one request and
+// its response: the request line, headers and body as written to the
+// socket, and the status line, headers and body as read from it. Body
+// counts are post-compression, i.e. taken before any Content-Encoding is
+// undone, matching what actually crossed the network. Sent is counted at
+// the RoundTrip boundary, so a chunked request body (unknown length, e.g.
+// one streamed through Request.SetCompressBody) undercounts by the
+// chunk-framing overhead net/http adds further down the stack.
+type WireSize struct {
+	sent     int64
+	received int64
+}
+
+// Sent returns the number of bytes written to the socket for the request.
+func (w *WireSize) Sent() int64 {
+	if w == nil {
+		return 0
+	}
+	return atomic.LoadInt64(&w.sent)
+}
+
+// Received returns the number of bytes read from the socket for the response.
+func (w *WireSize) Received() int64 {
+	if w == nil {
+		return 0
+	}
+	return atomic.LoadInt64(&w.received)
+}
+
+type wireSizeContextKeyType struct{}
+
+var wireSizeContextKey = wireSizeContextKeyType{}
+
+// wireSizeRoundTripper wraps an http.RoundTripper, accounting bytes against
+// the *WireSize stashed in the request's context by Request.RawRequest.
+// Accounting is done at the RoundTrip boundary rather than at the net.Conn
+// level, so connection reuse attributes bytes to the right request: every
+// request, whether it dials a new connection or reuses an idle one, passes
+// through exactly one RoundTrip call.
+type wireSizeRoundTripper struct {
+	next http.RoundTripper
+}
+
+func (t *wireSizeRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	ws, _ := req.Context().Value(wireSizeContextKey).(*WireSize)
+	if ws == nil {
+		return t.next.RoundTrip(req)
+	}
+
+	atomic.AddInt64(&ws.sent, requestHeaderSize(req))
+	if req.Body != nil {
+		req.Body = &countingReadCloser{ReadCloser: req.Body, n: &ws.sent}
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	atomic.AddInt64(&ws.received, responseHeaderSize(resp))
+	if resp.Body != nil {
+		resp.Body = &countingReadCloser{ReadCloser: resp.Body, n: &ws.received}
+	}
+	return resp, nil
+}
+
+// countingReadCloser counts every byte read through it into n.
+type countingReadCloser struct {
+	io.ReadCloser
+	n *int64
+}
+
+func (c *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	if n > 0 {
+		atomic.AddInt64(c.n, int64(n))
+	}
+	return n, err
+}
+
+// requestHeaderSize approximates the bytes the request line and headers
+// occupy on the wire, not counting the body.
+func requestHeaderSize(req *http.Request) int64 {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s %s HTTP/%d.%d\r\n", req.Method, req.URL.RequestURI(), req.ProtoMajor, req.ProtoMinor)
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	fmt.Fprintf(&buf, "Host: %s\r\n", host)
+	req.Header.Write(&buf)
+	if req.ContentLength > 0 {
+		fmt.Fprintf(&buf, "Content-Length: %d\r\n", req.ContentLength)
+	} else if req.Body != nil && req.GetBody == nil {
+		// ContentLength is 0 both for a known-empty body (where GetBody is
+		// also set, from one of net/http's or Request.RawRequest's own
+		// snapshot types) and for a body of genuinely unknown length, e.g.
+		// a streamed, compressed body; net/http tells them apart the same
+		// way and sends the latter chunked.
+		buf.WriteString("Transfer-Encoding: chunked\r\n")
+	}
+	buf.WriteString("\r\n")
+	return int64(buf.Len())
+}
+
+// responseHeaderSize approximates the bytes the status line and headers
+// occupy on the wire, not counting the body.
+func responseHeaderSize(resp *http.Response) int64 {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "HTTP/%d.%d %s\r\n", resp.ProtoMajor, resp.ProtoMinor, resp.Status)
+	resp.Header.Write(&buf)
+	if resp.Header.Get("Content-Length") == "" && resp.ContentLength >= 0 {
+		fmt.Fprintf(&buf, "Content-Length: %d\r\n", resp.ContentLength)
+	}
+	buf.WriteString("\r\n")
+	return int64(buf.Len())
+}
+
+// SetWireSizeAccounting turns on per-request wire-size accounting, read
+// back afterwards via Response.WireSizes. It wraps the client's transport,
+// so call it after any other transport configuration (SetDialContext,
+// SetProxyFunc, etc.) — later calls to those will otherwise fail with
+// ErrNotTransport since the transport is no longer an *http.Transport.
+func (c *Client) SetWireSizeAccounting(enable bool) *Client {
+	if !enable {
+		c.wireSizeAccounting = false
+		return c
+	}
+	// Go's Transport silently gzip-decodes a response itself whenever the
+	// request has no explicit Accept-Encoding header, which would make the
+	// bytes read here the decompressed size instead of the wire size. Like
+	// SetTLSClientConfig, the field is set via reconfigureTransport, which
+	// swaps the transport atomically rather than mutating the live one in
+	// place.
+	err := c.reconfigureTransport(func(t *http.Transport) {
+		t.DisableCompression = true
+	})
+	if err != nil {
+		c.printError(err)
+		return c
+	}
+	c.cli.Transport = &wireSizeRoundTripper{next: c.cli.Transport}
+	c.wireSizeAccounting = true
+	return c
+}
@@ -0,0 +1,173 @@
+package requests
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// countingConn wraps a net.Conn, counting bytes read from and written to it.
+type countingConn struct {
+	net.Conn
+	read    int64
+	written int64
+}
+
+func (c *countingConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	atomic.AddInt64(&c.read, int64(n))
+	return n, err
+}
+
+func (c *countingConn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	atomic.AddInt64(&c.written, int64(n))
+	return n, err
+}
+
+// rawTCPServer is a minimal HTTP/1.1 server that accepts exactly one
+// connection and counts the raw bytes read from and written to it,
+// independent of anything net/http does.
+type rawTCPServer struct {
+	ln   net.Listener
+	conn *countingConn
+	wg   sync.WaitGroup
+}
+
+func newRawTCPServer(t *testing.T, respBody []byte, respHeaders string) *rawTCPServer {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &rawTCPServer{ln: ln}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		c, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		cc := &countingConn{Conn: c}
+		s.conn = cc
+
+		// http.ReadRequest (rather than a hand-rolled Content-Length read)
+		// so a chunked request body, e.g. from a streamed, compressed
+		// request, is drained correctly too.
+		br := bufio.NewReader(cc)
+		req, err := http.ReadRequest(br)
+		if err != nil {
+			return
+		}
+		io.Copy(ioutil.Discard, req.Body)
+		req.Body.Close()
+
+		resp := "HTTP/1.1 200 OK\r\n" + respHeaders +
+			"Content-Length: " + strconv.Itoa(len(respBody)) + "\r\n\r\n"
+		cc.Write([]byte(resp))
+		cc.Write(respBody)
+		c.Close()
+	}()
+	return s
+}
+
+func (s *rawTCPServer) addr() string {
+	return s.ln.Addr().String()
+}
+
+func (s *rawTCPServer) close() {
+	s.ln.Close()
+	s.wg.Wait()
+}
+
+func TestWireSizesPlain(t *testing.T) {
+	const reqBody = "hello, wire accounting"
+	const respBody = "world"
+
+	srv := newRawTCPServer(t, []byte(respBody), "")
+	defer srv.close()
+
+	cli := NewClient().SetWireSizeAccounting(true)
+	resp, err := cli.NewRequest().SetURLByStr("http://" + srv.addr() + "/").
+		SetBody(strings.NewReader(reqBody)).
+		Post("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.wg.Wait()
+
+	sent, received := resp.WireSizes()
+	if sent != srv.conn.read {
+		t.Errorf("WireSizes sent = %d, want %d (what the server actually read)", sent, srv.conn.read)
+	}
+	if received != srv.conn.written {
+		t.Errorf("WireSizes received = %d, want %d (what the server actually wrote)", received, srv.conn.written)
+	}
+	if sent == 0 || received == 0 {
+		t.Errorf("expected non-zero wire sizes, got sent=%d received=%d", sent, received)
+	}
+}
+
+func TestWireSizesCompressed(t *testing.T) {
+	compressed, err := compressBody("gzip", strings.NewReader("hello, wire accounting, compressed"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := newRawTCPServer(t, compressed, "Content-Encoding: gzip\r\n")
+	defer srv.close()
+
+	cli := NewClient().SetWireSizeAccounting(true)
+	resp, err := cli.NewRequest().SetURLByStr("http://" + srv.addr() + "/").
+		SetBody(strings.NewReader("plain request body")).
+		SetCompressBody("gzip").
+		Post("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.wg.Wait()
+
+	sent, received := resp.WireSizes()
+	// SetCompressBody streams the body through the codec as it's sent, so
+	// its length is unknown up front and net/http sends it chunked; sent
+	// undercounts the real wire bytes by the chunk-framing overhead, since
+	// WireSize counts what's read off Request.Body, not what the chunked
+	// writer adds downstream of it.
+	if sent <= 0 || sent > srv.conn.read {
+		t.Errorf("WireSizes sent = %d, want (0, %d]", sent, srv.conn.read)
+	}
+	if received != srv.conn.written {
+		t.Errorf("WireSizes received = %d, want %d", received, srv.conn.written)
+	}
+	// The response body counted on the wire must match the compressed
+	// (not the decompressed) payload size.
+	if received < int64(len(compressed)) {
+		t.Errorf("received %d is smaller than the compressed body alone (%d)", received, len(compressed))
+	}
+}
+
+func TestWireSizesDisabledByDefault(t *testing.T) {
+	mock, err := NewMock(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {})
+
+	resp, err := NewRequest().SetURLByStr(mock.URL()).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sent, received := resp.WireSizes()
+	if sent != 0 || received != 0 {
+		t.Errorf("expected zero wire sizes when accounting is off, got sent=%d received=%d", sent, received)
+	}
+}
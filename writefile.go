@@ -0,0 +1,79 @@
+package requests
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// FileOption configures Response.WriteFile.
+type FileOption func(*fileWriteOptions)
+
+type fileWriteOptions struct {
+	perm        os.FileMode
+	failIfExist bool
+}
+
+// WithFilePerm sets the permission bits of the written file, instead of
+// the default 0666.
+func WithFilePerm(perm os.FileMode) FileOption {
+	return func(o *fileWriteOptions) { o.perm = perm }
+}
+
+// WithFileFailIfExist makes WriteFile fail with an error instead of
+// overwriting an existing file at the target path.
+func WithFileFailIfExist() FileOption {
+	return func(o *fileWriteOptions) { o.failIfExist = true }
+}
+
+// WriteFile writes the response body to file, creating any missing parent
+// directories. The write is atomic: the body is written to a temporary
+// file in file's directory, which is renamed into place only once the
+// write succeeds, so a crash or error partway through never leaves a
+// truncated file at the target path. By default an existing file at the
+// target path is overwritten; pass WithFileFailIfExist to reject that.
+func (r *Response) WriteFile(file string, opts ...FileOption) error {
+	if r.streamBody != nil {
+		return ErrBodyNotBuffered
+	}
+
+	o := &fileWriteOptions{perm: 0666}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	dir := filepath.Dir(file)
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(dir, ".requests-tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName)
+
+	if _, err := tmp.Write(r.body); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpName, o.perm); err != nil {
+		return err
+	}
+
+	if o.failIfExist {
+		// os.Link fails atomically with EEXIST if file already exists,
+		// unlike a Stat-then-Rename pair, which leaves a race window
+		// where another writer can create file between the two steps.
+		if err := os.Link(tmpName, file); err != nil {
+			return err
+		}
+		return os.Remove(tmpName)
+	}
+
+	return os.Rename(tmpName, file)
+}
@@ -0,0 +1,228 @@
+package requests
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestWriteFileCreatesMissingDirectories(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("nested file contents"))
+	})
+
+	resp, err := NewRequest().SetURLByStr(mock.URL()).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "a", "b", "c", "out.txt")
+	if err := resp.WriteFile(file); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "nested file contents" {
+		t.Errorf("file contents = %q", got)
+	}
+}
+
+func TestWriteFileLeavesNoTempFileBehind(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("atomic write"))
+	})
+
+	resp, err := NewRequest().SetURLByStr(mock.URL()).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "out.txt")
+	if err := resp.WriteFile(file); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "out.txt" {
+		t.Errorf("directory contents = %v, want only out.txt (no leftover temp file)", entries)
+	}
+}
+
+func TestWriteFileWithPerm(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file permission bits aren't meaningful on windows")
+	}
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("restricted"))
+	})
+
+	resp, err := NewRequest().SetURLByStr(mock.URL()).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "secret.txt")
+	if err := resp.WriteFile(file, WithFilePerm(0600)); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("perm = %v, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestWriteFileFailIfExistRejectsExistingFile(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("new contents"))
+	})
+
+	resp, err := NewRequest().SetURLByStr(mock.URL()).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "out.txt")
+	if err := ioutil.WriteFile(file, []byte("original contents"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := resp.WriteFile(file, WithFileFailIfExist()); err == nil {
+		t.Fatal("want an error when the target file already exists")
+	} else if !os.IsExist(err) {
+		t.Errorf("err = %v, want an os.IsExist error", err)
+	}
+
+	got, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "original contents" {
+		t.Errorf("file contents = %q, want the original left untouched", got)
+	}
+}
+
+func TestWriteFileZeroOptionsOverwritesExistingFile(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("new contents"))
+	})
+
+	resp, err := NewRequest().SetURLByStr(mock.URL()).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "out.txt")
+	if err := ioutil.WriteFile(file, []byte("original contents"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := resp.WriteFile(file); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "new contents" {
+		t.Errorf("file contents = %q, want overwritten with new contents", got)
+	}
+}
+
+// simulating a crash between the temp-file write and the rename: forcing
+// the rename itself to fail (by making the target path a non-empty
+// directory, which os.Rename refuses to replace with a file) must never
+// leave a truncated, half-written file at the target path -- the original
+// directory must be left exactly as it was.
+func TestWriteFileFailureBetweenWriteAndRenameLeavesTargetUntouched(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("should never land"))
+	})
+
+	resp, err := NewRequest().SetURLByStr(mock.URL()).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "out.txt")
+	if err := os.Mkdir(file, 0777); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(file, "keep"), []byte("untouched"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	err = resp.WriteFile(file)
+	if err == nil {
+		t.Fatal("want an error when the target path is a non-empty directory")
+	}
+	if !strings.Contains(err.Error(), "out.txt") {
+		t.Errorf("err = %v, want it to name the conflicting target path", err)
+	}
+
+	info, err := os.Stat(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.IsDir() {
+		t.Error("target path should still be a directory after the failed write")
+	}
+	if _, err := os.Stat(filepath.Join(file, "keep")); err != nil {
+		t.Errorf("directory contents should be untouched: %v", err)
+	}
+
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("directory = %v, want only the original out.txt directory (no leftover temp file)", entries)
+	}
+}
@@ -0,0 +1,77 @@
+package requests
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// WriteTo writes the response body to w, implementing io.WriterTo so
+// io.Copy(w, resp) can use it directly. It writes the buffered body if
+// one was read, or copies straight from the network body for a response
+// built with Request.SetDoNotParseResponse. It returns the number of
+// bytes written and the first error encountered, from either reading the
+// response or writing to w.
+func (r *Response) WriteTo(w io.Writer) (int64, error) {
+	if r.streamBody != nil {
+		return io.Copy(w, r.streamBody)
+	}
+	n, err := io.Copy(w, bytes.NewReader(r.body))
+	return n, err
+}
+
+// WriteFileTo writes the response body to a file inside dir, naming it
+// from the Content-Disposition header's filename parameter, falling back
+// to the base name of the request URL's path when that header is absent
+// or unparseable. It returns the full path written. Like WriteFile, the
+// write is atomic: the body lands in a temp file in dir first, which is
+// renamed into place only once it's fully written.
+func (r *Response) WriteFileTo(dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return "", err
+	}
+
+	file := filepath.Join(dir, r.suggestedFilename())
+
+	tmp, err := ioutil.TempFile(dir, ".requests-tmp-*")
+	if err != nil {
+		return "", err
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName)
+
+	if _, err := r.WriteTo(tmp); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Chmod(tmpName, 0666); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmpName, file); err != nil {
+		return "", err
+	}
+	return file, nil
+}
+
+func (r *Response) suggestedFilename() string {
+	if cd := r.Header().Get(HeaderContentDisposition); cd != "" {
+		if _, params, err := mime.ParseMediaType(cd); err == nil {
+			if name := params["filename"]; name != "" {
+				return path.Base(name)
+			}
+		}
+	}
+	if r.location != nil && r.location.Path != "" {
+		if base := path.Base(r.location.Path); base != "." && base != "/" {
+			return base
+		}
+	}
+	return "download"
+}
@@ -0,0 +1,157 @@
+package requests
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResponseWriteToCopiesBody(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	const want = "hello, writerto"
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(want))
+	})
+
+	resp, err := NewRequest().SetURLByStr(mock.URL()).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	n, err := resp.WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(len(want)) {
+		t.Errorf("WriteTo returned %d, want %d", n, len(want))
+	}
+	if buf.String() != want {
+		t.Errorf("WriteTo wrote %q, want %q", buf.String(), want)
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("boom")
+}
+
+func TestResponseWriteToPropagatesWriterError(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("some body"))
+	})
+
+	resp, err := NewRequest().SetURLByStr(mock.URL()).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := resp.WriteTo(failingWriter{}); err == nil {
+		t.Fatal("want an error from a failing writer")
+	}
+}
+
+func TestResponseWriteToStreamedBody(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	const want = "hello, streamed writerto"
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(want))
+	})
+
+	resp, err := NewRequest().SetURLByStr(mock.URL()).SetDoNotParseResponse(true).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Close()
+
+	var buf bytes.Buffer
+	if _, err := resp.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != want {
+		t.Errorf("WriteTo wrote %q, want %q", buf.String(), want)
+	}
+}
+
+func TestResponseWriteFileToUsesContentDisposition(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	const want = "hello, content-disposition"
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderContentDisposition, `attachment; filename="report.csv"`)
+		w.Write([]byte(want))
+	})
+
+	resp, err := NewRequest().SetURLByStr(mock.URL()).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	file, err := resp.WriteFileTo(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(file) != "report.csv" {
+		t.Errorf("WriteFileTo path = %q, want basename report.csv", file)
+	}
+	got, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("file contents = %q, want %q", got, want)
+	}
+}
+
+func TestResponseWriteFileToFallsBackToURLBasename(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/files/archive.zip", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("zip bytes"))
+	})
+
+	resp, err := NewRequest().SetURLByStr(mock.URL()).Get("/files/archive.zip")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	file, err := resp.WriteFileTo(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(file) != "archive.zip" {
+		t.Errorf("WriteFileTo path = %q, want basename archive.zip", file)
+	}
+	if _, err := os.Stat(file); err != nil {
+		t.Fatal(err)
+	}
+}
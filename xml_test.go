@@ -0,0 +1,72 @@
+package requests
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+func TestResponseXMLDecodesISO88591Body(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		doc := `<?xml version="1.0" encoding="ISO-8859-1"?><person><name>Andr&#233;</name></person>`
+		encoded, err := charmap.ISO8859_1.NewEncoder().String(doc)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		w.Header().Set(HeaderContentType, "text/xml; charset=iso-8859-1")
+		w.Write([]byte(encoded))
+	})
+
+	resp, err := NewRequest().SetURLByStr(mock.URL()).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out struct {
+		Name string `xml:"name"`
+	}
+	if err := resp.XML(&out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Name != "André" {
+		t.Errorf("Name = %q, want André", out.Name)
+	}
+}
+
+func TestResponseXMLErrorIdentifiesFailingElement(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderContentType, MimeXML)
+		w.Write([]byte(`<person><age>not-a-number</age></person>`))
+	})
+
+	resp, err := NewRequest().SetURLByStr(mock.URL()).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out struct {
+		Age int `xml:"age"`
+	}
+	err = resp.XML(&out)
+	if err == nil {
+		t.Fatal("want an error decoding a non-numeric age")
+	}
+	if !strings.Contains(err.Error(), "<age>") {
+		t.Errorf("error = %q, want it to identify the <age> element", err.Error())
+	}
+}
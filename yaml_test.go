@@ -0,0 +1,103 @@
+package requests
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func TestSetYAMLSetsContentTypeAndBody(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	var gotBody, gotContentType string
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		gotContentType = r.Header.Get(HeaderContentType)
+		body, _ := ioutil.ReadAll(r.Body)
+		gotBody = string(body)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	_, err = NewRequest().SetURLByStr(mock.URL()).
+		SetYAML(map[string]string{"name": "gopher"}).
+		Post("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotContentType != MimeYAML {
+		t.Errorf("Content-Type = %q, want %q", gotContentType, MimeYAML)
+	}
+	if gotBody != "name: gopher\n" {
+		t.Errorf("body = %q, want %q", gotBody, "name: gopher\n")
+	}
+}
+
+type yamlMarshalFailure struct{}
+
+func (yamlMarshalFailure) MarshalYAML() (interface{}, error) {
+	return nil, errors.New("boom")
+}
+
+func TestSetYAMLMarshalErrorIsDeferred(t *testing.T) {
+	_, err := NewRequest().SetQuiet().SetURLByStr("http://example.invalid/").
+		SetYAML(yamlMarshalFailure{}).
+		Post("/")
+	if err == nil {
+		t.Fatal("want an error for a value whose MarshalYAML fails")
+	}
+}
+
+func TestResponseYAMLDecodesBody(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("name: gopher\nage: 10\n"))
+	})
+
+	resp, err := NewRequest().SetURLByStr(mock.URL()).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var v struct {
+		Name string `yaml:"name"`
+		Age  int    `yaml:"age"`
+	}
+	if err := resp.YAML(&v); err != nil {
+		t.Fatal(err)
+	}
+	if v.Name != "gopher" || v.Age != 10 {
+		t.Errorf("v = %+v, want {gopher 10}", v)
+	}
+}
+
+func TestResponseYAMLDecodesOnlyFirstDocument(t *testing.T) {
+	mock, err := NewMock(func(err error) { t.Error(err) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("name: first\n---\nname: second\n"))
+	})
+
+	resp, err := NewRequest().SetURLByStr(mock.URL()).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var v struct {
+		Name string `yaml:"name"`
+	}
+	if err := resp.YAML(&v); err != nil {
+		t.Fatal(err)
+	}
+	if v.Name != "first" {
+		t.Errorf("Name = %q, want first (only the first document should decode)", v.Name)
+	}
+}
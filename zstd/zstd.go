@@ -0,0 +1,32 @@
+// Package zstd adds Content-Encoding: zstd support on top of
+// github.com/wzshiming/requests, kept as a separate module so the zstd
+// dependency doesn't leak into the core module. Importing it registers
+// the codec with requests.RegisterEncoding via its init(), so
+// Client/Request.SetAcceptEncoding and response decompression pick it up
+// automatically, including as one layer of a multi-encoding response
+// (e.g. "zstd, gzip"); there's nothing to call from this package
+// directly.
+package zstd
+
+import (
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/wzshiming/requests"
+)
+
+func init() {
+	requests.RegisterEncoding("zstd", func(w io.Writer) io.WriteCloser {
+		// Only fails on invalid options, which NewWriter is never given
+		// here; compress/flate's registered codec discards the same
+		// never-in-practice error the same way.
+		enc, _ := zstd.NewWriter(w)
+		return enc
+	}, func(r io.Reader) (io.ReadCloser, error) {
+		dec, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return dec.IOReadCloser(), nil
+	})
+}
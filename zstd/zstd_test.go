@@ -0,0 +1,78 @@
+package zstd
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	zstdlib "github.com/klauspost/compress/zstd"
+	"github.com/wzshiming/requests"
+)
+
+func TestResponseDecompressesZstd(t *testing.T) {
+	const want = "hello, zstd compressed response"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(requests.HeaderContentEncoding, "zstd")
+		enc, _ := zstdlib.NewWriter(w)
+		enc.Write([]byte(want))
+		enc.Close()
+	}))
+	defer srv.Close()
+
+	resp, err := requests.NewRequest().SetURLByStr(srv.URL).
+		SetAcceptEncoding("zstd").
+		Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(resp.Body()) != want {
+		t.Errorf("Body() = %q, want %q", resp.Body(), want)
+	}
+}
+
+func TestResponseDecompressesDoublyEncodedZstdThenGzip(t *testing.T) {
+	const want = "hello, doubly compressed response"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Applied in listed order: gzip first, then zstd over the gzip
+		// output, so Content-Encoding lists "gzip, zstd".
+		var gzipped bytes.Buffer
+		gw := gzip.NewWriter(&gzipped)
+		gw.Write([]byte(want))
+		gw.Close()
+
+		w.Header().Set(requests.HeaderContentEncoding, "gzip, zstd")
+		enc, _ := zstdlib.NewWriter(w)
+		enc.Write(gzipped.Bytes())
+		enc.Close()
+	}))
+	defer srv.Close()
+
+	resp, err := requests.NewRequest().SetURLByStr(srv.URL).
+		SetAcceptEncoding("gzip", "zstd").
+		Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(resp.Body()) != want {
+		t.Errorf("Body() = %q, want %q", resp.Body(), want)
+	}
+}
+
+func TestResponseUnknownEncodingInChainPassesThroughWithWarning(t *testing.T) {
+	const raw = "not actually compressed"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(requests.HeaderContentEncoding, "made-up-encoding")
+		w.Write([]byte(raw))
+	}))
+	defer srv.Close()
+
+	resp, err := requests.NewRequest().SetURLByStr(srv.URL).Get("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(resp.Body()) != raw {
+		t.Errorf("Body() = %q, want the raw bytes passed through", resp.Body())
+	}
+}